@@ -15,8 +15,13 @@ type (
 		Log
 		PG
 		Mail
+		Push
+		Sms
 		AWS
+		Storage
 		S3
+		Media
+		Scanner
 		Queue
 		Monitoring
 	}
@@ -53,24 +58,76 @@ type (
 	}
 
 	Mail struct {
-		Host     string `env:"MAIL_HOST"`
-		Port     int    `env:"MAIL_PORT"`
-		User     string `env:"MAIL_USER"`
-		Password string `env:"MAIL_PASS"`
-		From     string `env:"MAIL_FROM"`
-		Test     string `env:"MAIL_TEST"`
+		Driver      string `env:"MAIL_DRIVER" env-default:"smtp"`
+		Host        string `env:"MAIL_HOST"`
+		Port        int    `env:"MAIL_PORT"`
+		User        string `env:"MAIL_USER"`
+		Password    string `env:"MAIL_PASS"`
+		From        string `env:"MAIL_FROM"`
+		Test        string `env:"MAIL_TEST"`
+		CaptureDir  string `env:"MAIL_CAPTURE_DIR"`
+		SNSTopicArn string `env:"MAIL_SNS_TOPIC_ARN"`
+	}
+
+	Push struct {
+		Driver             string `env:"PUSH_DRIVER" env-default:"fake"`
+		FCMProjectID       string `env:"PUSH_FCM_PROJECT_ID"`
+		FCMCredentialsFile string `env:"PUSH_FCM_CREDENTIALS_FILE"`
+		APNsKeyFile        string `env:"PUSH_APNS_KEY_FILE"`
+		APNsKeyID          string `env:"PUSH_APNS_KEY_ID"`
+		APNsTeamID         string `env:"PUSH_APNS_TEAM_ID"`
+		APNsTopic          string `env:"PUSH_APNS_TOPIC"`
+		APNsSandbox        bool   `env:"PUSH_APNS_SANDBOX"`
+	}
+
+	Sms struct {
+		Driver string `env:"SMS_DRIVER" env-default:"fake"`
 	}
 
 	AWS struct {
 		Region string `env:"AWS_REGION"`
 	}
 
+	Storage struct {
+		Driver   string `env:"STORAGE_DRIVER" env-default:"s3"`
+		LocalDir string `env:"STORAGE_LOCAL_DIR" env-default:"storage"`
+	}
+
 	S3 struct {
-		Bucket string `env:"S3_BUCKET"`
+		Bucket    string `env:"S3_BUCKET"`
+		Endpoint  string `env:"S3_ENDPOINT"`
+		PathStyle bool   `env:"S3_PATH_STYLE"`
+		// CDNUrl serves the bucket through a CDN, downloads of private media
+		// are CloudFront signed URLs when a key pair is set.
+		CDNUrl            string `env:"S3_CDN_URL"`
+		CDNKeyID          string `env:"S3_CDN_KEY_ID"`
+		CDNPrivateKeyFile string `env:"S3_CDN_PRIVATE_KEY_FILE"`
+	}
+
+	// Media maps each accepted MIME type to its maximum size, such as
+	// image/png:600KB, and each image variant to its size, fit and format,
+	// such as thumbnail:200x200 crop jpeg. PrivateUrlExpiry is how long the
+	// URLs of private media last, in seconds. Media no entity uses are
+	// deleted OrphanTTL seconds after their last change, unless GCDryRun.
+	Media struct {
+		Types            map[string]string `env:"MEDIA_TYPES" env-default:"image/jpeg:600KB,image/png:600KB,video/mp4:30MB,audio/mpeg:10MB"`
+		Variants         map[string]string `env:"MEDIA_VARIANTS" env-default:"thumbnail:200x200 crop jpeg,medium:800x800 fit jpeg,webp:1600x1600 fit webp"`
+		PrivateUrlExpiry int               `env:"MEDIA_PRIVATE_URL_EXPIRY" env-default:"900"`
+		OrphanTTL        int               `env:"MEDIA_ORPHAN_TTL" env-default:"86400"`
+		GCDryRun         bool              `env:"MEDIA_GC_DRY_RUN" env-default:"true"`
+	}
+
+	// Scanner checks uploads for malware before they are served. Timeout is
+	// in seconds.
+	Scanner struct {
+		Driver        string `env:"SCANNER_DRIVER" env-default:"noop"`
+		ClamAVAddress string `env:"SCANNER_CLAMAV_ADDRESS" env-default:"localhost:3310"`
+		Timeout       int    `env:"SCANNER_TIMEOUT" env-default:"60"`
 	}
 
 	Queue struct {
-		Host string `env:"QUEUE_HOST"`
+		Host           string `env:"QUEUE_HOST"`
+		OutboxInterval int    `env:"QUEUE_OUTBOX_INTERVAL" env-default:"5"`
 	}
 
 	Monitoring struct {
@@ -1,10 +1,12 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/felixlambertv/go-cleanplate/internal/di"
 	"github.com/getsentry/sentry-go"
@@ -33,8 +35,26 @@ func Run(cfg *config.Config) {
 	if err != nil {
 		l.Fatal(fmt.Errorf("app - Run - postgres: %w", err))
 	}
+	// Users are searched by trigram similarity. pg_trgm is a prerequisite of
+	// the database, created here only when missing as it takes a privileged
+	// role.
+	err = createExtension(db, "pg_trgm")
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - pg_trgm, run CREATE EXTENSION pg_trgm as a privileged role: %w", err))
+	}
 	err = db.AutoMigrate(
 		&model.User{},
+		&model.OutboxMessage{},
+		&model.JobRun{},
+		&model.ProcessedMessage{},
+		&model.EmailLog{},
+		&model.EmailSuppression{},
+		&model.NotificationPreference{},
+		&model.InAppNotification{},
+		&model.DeviceToken{},
+		&model.Media{},
+		&model.MediaVariant{},
+		&model.MediaUploadChunk{},
 	)
 	if err != nil {
 		l.Fatal(fmt.Errorf("app - Run - migrate: %w", err))
@@ -50,14 +70,33 @@ func Run(cfg *config.Config) {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
-	for {
-		fmt.Println("worker receive message")
-		err := di.QueueService.ReceiveMessage()
-		if err != nil {
-			fmt.Printf("failed to process or get message: %v\n", err)
-			continue
+	go func() {
+		for {
+			fmt.Println("worker receive message")
+			err := di.QueueService.ReceiveMessage()
+			if err != nil {
+				fmt.Printf("failed to process or get message: %v\n", err)
+				continue
+			}
 		}
-	}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(outboxInterval(cfg))
+		defer ticker.Stop()
+
+		for range ticker.C {
+			err := di.OutboxService.Relay()
+			if err != nil {
+				fmt.Printf("failed to relay outbox messages: %v\n", err)
+			}
+		}
+	}()
+
+	di.SchedulerService.Start()
+
+	listenCtx, stopListening := context.WithCancel(context.Background())
+	go di.InboxService.Listen(listenCtx)
 
 	select {
 	case s := <-interrupt:
@@ -66,8 +105,30 @@ func Run(cfg *config.Config) {
 		l.Error(fmt.Errorf("%w", err))
 	}
 
+	di.SchedulerService.Stop()
+	stopListening()
+
 	err = httpServer.Shutdown()
 	if err != nil {
 		l.Error(fmt.Errorf("%w", err))
 	}
 }
+
+// createExtension creates the postgres extension unless it already exists.
+func createExtension(db *gorm.DB, name string) error {
+	var exists bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = ?)", name).Scan(&exists).Error
+	if err != nil || exists {
+		return err
+	}
+	return db.Exec("CREATE EXTENSION IF NOT EXISTS " + name).Error
+}
+
+// outboxInterval falls back to the default interval, tickers panic on
+// intervals that are not positive.
+func outboxInterval(cfg *config.Config) time.Duration {
+	if cfg.Queue.OutboxInterval <= 0 {
+		return time.Second * 5
+	}
+	return time.Duration(cfg.Queue.OutboxInterval) * time.Second
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
 	"github.com/felixlambertv/go-cleanplate/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type authRoutes struct {
@@ -21,7 +22,7 @@ type authRoutes struct {
 	ms  service.IMailService
 }
 
-func newAuthRoutes(handler *gin.RouterGroup, l logger.Interface, cfg *config.Config, s service.IAuthService, ms service.IMailService) {
+func newAuthRoutes(handler *gin.RouterGroup, l logger.Interface, db *gorm.DB, cfg *config.Config, s service.IAuthService, ms service.IMailService) {
 	r := &authRoutes{l: l, s: s, cfg: cfg, ms: ms}
 
 	h := handler.Group("auth")
@@ -32,10 +33,10 @@ func newAuthRoutes(handler *gin.RouterGroup, l logger.Interface, cfg *config.Con
 		verifyGroup := h.Group("verify").Use(middleware.JWTAuthMiddleware(cfg, consttype.USER))
 		{
 			verifyGroup.POST("", r.verifyToken)
-			verifyGroup.POST("send", r.sendVerifyEmail)
+			verifyGroup.POST("send", middleware.DbTransactionMiddleware(db), r.sendVerifyEmail)
 		}
 
-		h.POST("forgot-password", r.forgotPassword)
+		h.POST("forgot-password", middleware.DbTransactionMiddleware(db), r.forgotPassword)
 		h.POST("reset-password", r.resetPassword)
 
 		h.GET("refresh-token", r.refreshAuthToken)
@@ -104,6 +105,10 @@ func (r *authRoutes) register(ctx *gin.Context) {
 		return
 	}
 
+	if req.Locale == "" {
+		req.Locale = utils.GetRequestLocale(ctx)
+	}
+
 	user, token, err := r.s.Register(req)
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusUnauthorized, utils.ErrorRes{
@@ -157,8 +162,10 @@ func (r *authRoutes) sendVerifyEmail(ctx *gin.Context) {
 		return
 	}
 
+	trx := ctx.MustGet("db_trx").(*gorm.DB)
+
 	token := utils.GenerateRandomToken()
-	err := r.s.SendVerificationEmail(loggedInUser.ID, token)
+	err := r.s.WithTrx(trx).SendVerificationEmail(loggedInUser.ID, token, utils.GetRequestLocale(ctx))
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
 			Message: "Error sending verification email",
@@ -216,7 +223,10 @@ func (r *authRoutes) forgotPassword(ctx *gin.Context) {
 		return
 	}
 
-	err = r.s.ForgotPassword(req)
+	trx := ctx.MustGet("db_trx").(*gorm.DB)
+
+	req.Locale = utils.GetRequestLocale(ctx)
+	err = r.s.WithTrx(trx).ForgotPassword(req)
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
 			Message: "Something went wrong",
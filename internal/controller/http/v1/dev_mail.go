@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Captured mail is untrusted markup, previews are served in a sandbox so its
+// scripts cannot run against the API origin.
+const mailPreviewPolicy = "sandbox allow-popups allow-popups-to-escape-sandbox"
+
+var mailInboxTemplate = template.Must(template.New("inbox").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Mail catcher</title>
+  <style>
+    body { font-family: sans-serif; margin: 2rem; }
+    table { border-collapse: collapse; width: 100%; }
+    th, td { border-bottom: 1px solid #ddd; padding: .5rem; text-align: left; vertical-align: top; }
+    ul { margin: 0; padding-left: 1rem; }
+  </style>
+</head>
+<body>
+  <h1>Mail catcher</h1>
+  <p>{{len .}} captured message(s)</p>
+  <table>
+    <tr><th>Sent at</th><th>To</th><th>Subject</th><th>Preview</th><th>Links</th></tr>
+    {{range .}}
+    <tr>
+      <td>{{.SentAt.Format "2006-01-02 15:04:05"}}</td>
+      <td>{{range .To}}{{.}}<br>{{end}}</td>
+      <td>{{.Subject}}</td>
+      <td><a href="mail/{{.ID}}/html" target="_blank">HTML</a> · <a href="mail/{{.ID}}/text" target="_blank">Text</a></td>
+      <td><ul>{{range .Links}}<li><a href="{{.}}" target="_blank">{{.}}</a></li>{{end}}</ul></td>
+    </tr>
+    {{end}}
+  </table>
+</body>
+</html>`))
+
+type devMailRoutes struct {
+	l logger.Interface
+	s service.IMailCatcherService
+}
+
+func newDevMailRoutes(handler *gin.Engine, l logger.Interface, s service.IMailCatcherService) {
+	r := &devMailRoutes{l: l, s: s}
+
+	h := handler.Group("dev/mail")
+	{
+		h.GET("", r.getMessages)
+		h.DELETE("", r.deleteMessages)
+		h.GET("/:id", r.getMessage)
+		h.GET("/:id/html", r.previewHTML)
+		h.GET("/:id/text", r.previewText)
+	}
+}
+
+func (r *devMailRoutes) getMessages(ctx *gin.Context) {
+	messages, err := r.s.GetMessages()
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		ctx.Status(http.StatusOK)
+		ctx.Header("Content-Type", "text/html; charset=utf-8")
+		if err := mailInboxTemplate.Execute(ctx.Writer, messages); err != nil {
+			r.l.Error(err, "http - v1 - getMessages")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Captured Mail",
+		Data:    messages,
+	})
+}
+
+func (r *devMailRoutes) getMessage(ctx *gin.Context) {
+	message, err := r.s.GetMessage(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Captured mail not found",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Captured Mail",
+		Data:    message,
+	})
+}
+
+func (r *devMailRoutes) previewHTML(ctx *gin.Context) {
+	message, err := r.s.GetMessage(ctx.Param("id"))
+	if err != nil {
+		ctx.String(http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.Header("Content-Security-Policy", mailPreviewPolicy)
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(message.HTML))
+}
+
+func (r *devMailRoutes) previewText(ctx *gin.Context) {
+	message, err := r.s.GetMessage(ctx.Param("id"))
+	if err != nil {
+		ctx.String(http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(message.Text))
+}
+
+func (r *devMailRoutes) deleteMessages(ctx *gin.Context) {
+	err := r.s.DeleteMessages()
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Captured mail deleted",
+		Data:    nil,
+	})
+}
@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/middleware"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type deviceRoutes struct {
+	l logger.Interface
+	s service.IPushService
+}
+
+func newDeviceRoutes(handler *gin.RouterGroup, l logger.Interface, cfg *config.Config, s service.IPushService) {
+	r := &deviceRoutes{l: l, s: s}
+
+	h := handler.Group("users/me/devices").Use(middleware.JWTAuthMiddleware(cfg, consttype.USER))
+	{
+		h.GET("", r.getDevices)
+		h.POST("", r.registerDevice)
+		h.DELETE("/:token", r.unregisterDevice)
+	}
+}
+
+func (r *deviceRoutes) getDevices(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	devices, err := r.s.GetDevices(loggedInUser.ID)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Devices",
+		Data:    devices,
+	})
+}
+
+// registerDevice is called by the app on every start, registering a token
+// again only moves it to the logged in user.
+func (r *deviceRoutes) registerDevice(ctx *gin.Context) {
+	var req request.RegisterDeviceRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ve := utils.ValidationResponse(err)
+
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  ve,
+		})
+		return
+	}
+
+	device, err := r.s.RegisterDevice(loggedInUser.ID, req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "push is not available") {
+			code = http.StatusUnprocessableEntity
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot register device",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Device registered",
+		Data:    device,
+	})
+}
+
+func (r *deviceRoutes) unregisterDevice(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := r.s.UnregisterDevice(loggedInUser.ID, ctx.Param("token"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusNotFound
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot unregister device",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Device unregistered",
+		Data:    nil,
+	})
+}
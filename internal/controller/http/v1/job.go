@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/middleware"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type jobRoutes struct {
+	l   logger.Interface
+	cfg *config.Config
+	s   service.ISchedulerService
+}
+
+func newJobRoutes(handler *gin.RouterGroup, l logger.Interface, cfg *config.Config, s service.ISchedulerService) {
+	r := &jobRoutes{l: l, cfg: cfg, s: s}
+
+	h := handler.Group("admin/jobs").Use(middleware.JWTAuthMiddleware(cfg, consttype.ADMIN))
+	{
+		h.GET("", r.getJobs)
+		h.GET("/:name/runs", r.getJobRuns)
+		h.POST("/:name/trigger", r.triggerJob)
+	}
+}
+
+func (r *jobRoutes) getJobs(ctx *gin.Context) {
+	jobs, err := r.s.GetJobs()
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Jobs",
+		Data:    jobs,
+	})
+}
+
+func (r *jobRoutes) getJobRuns(ctx *gin.Context) {
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.JobRunQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	runs, err := r.s.GetJobRuns(ctx.Param("name"), paginationReq)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Job runs not found",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Job Runs",
+		Data:    runs,
+	})
+}
+
+func (r *jobRoutes) triggerJob(ctx *gin.Context) {
+	err := r.s.TriggerJob(ctx.Param("name"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Cannot trigger job",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusAccepted, utils.SuccessRes{
+		Message: "Job triggered",
+		Data:    nil,
+	})
+}
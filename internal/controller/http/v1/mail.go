@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/middleware"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/internal/service/mail"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SNS messages are at most 256 KB.
+const snsMaxBodySize = 256 << 10
+
+type mailRoutes struct {
+	l   logger.Interface
+	cfg *config.Config
+	s   service.IMailService
+}
+
+func newMailRoutes(handler *gin.RouterGroup, l logger.Interface, cfg *config.Config, s service.IMailService) {
+	r := &mailRoutes{l: l, cfg: cfg, s: s}
+
+	h := handler.Group("admin/mail").Use(middleware.JWTAuthMiddleware(cfg, consttype.ADMIN))
+	{
+		h.GET("/templates", r.getTemplates)
+		h.GET("/templates/locales", r.getLocales)
+		h.GET("/templates/:name/preview", r.previewTemplate)
+		h.GET("/logs", r.getEmailLogs)
+		h.GET("/suppressions", r.getSuppressions)
+		h.DELETE("/suppressions/:email", r.deleteSuppression)
+	}
+
+	// SNS authenticates itself with signed messages, not with a JWT.
+	w := handler.Group("webhooks")
+	{
+		w.POST("/ses", r.handleSESWebhook)
+	}
+}
+
+func (r *mailRoutes) getTemplates(ctx *gin.Context) {
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Mail Templates",
+		Data:    r.s.GetTemplates(),
+	})
+}
+
+func (r *mailRoutes) getLocales(ctx *gin.Context) {
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Mail Locales",
+		Data:    r.s.GetLocales(),
+	})
+}
+
+// previewTemplate renders the HTML part by default, ?format=text returns the
+// plain text part and ?format=json both parts with the subject. The locale is
+// taken from ?locale, then from Accept-Language.
+func (r *mailRoutes) previewTemplate(ctx *gin.Context) {
+	locale := ctx.Query("locale")
+	if locale == "" {
+		locale = utils.GetRequestLocale(ctx)
+	}
+
+	preview, err := r.s.PreviewTemplate(ctx.Param("name"), locale)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Cannot preview mail template",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	switch ctx.Query("format") {
+	case "text":
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(preview.Text))
+	case "json":
+		utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+			Message: "Success Preview Mail Template",
+			Data:    preview,
+		})
+	default:
+		ctx.Header("Content-Security-Policy", mailPreviewPolicy)
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(preview.HTML))
+	}
+}
+
+func (r *mailRoutes) getEmailLogs(ctx *gin.Context) {
+	var filter request.EmailLogFilter
+
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.EmailLogQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	logs, err := r.s.GetEmailLogs(filter, paginationReq)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Email Logs",
+		Data:    logs,
+	})
+}
+
+func (r *mailRoutes) getSuppressions(ctx *gin.Context) {
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.EmailSuppressionQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	suppressions, err := r.s.GetSuppressions(paginationReq)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Email Suppressions",
+		Data:    suppressions,
+	})
+}
+
+func (r *mailRoutes) deleteSuppression(ctx *gin.Context) {
+	err := r.s.DeleteSuppression(ctx.Param("email"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Email suppression not found",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Email suppression deleted",
+		Data:    nil,
+	})
+}
+
+// handleSESWebhook answers 400 to messages that fail verification, so SNS
+// does not retry them, and 500 to failures worth a retry.
+func (r *mailRoutes) handleSESWebhook(ctx *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, snsMaxBodySize))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	err = r.s.HandleSNSMessage(body)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, mail.ErrInvalidSNSMessage) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot handle notification",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Notification handled",
+		Data:    nil,
+	})
+}
@@ -1,97 +1,706 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/felixlambertv/go-cleanplate/config"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
 	"github.com/felixlambertv/go-cleanplate/internal/middleware"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
 	"github.com/felixlambertv/go-cleanplate/internal/service"
+	mediaService "github.com/felixlambertv/go-cleanplate/internal/service/media"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
 	"github.com/felixlambertv/go-cleanplate/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// maxFilenameLength is the longest filename field accepted with an upload.
+const maxFilenameLength = 255
+
+// tusVersion is the version of the tus protocol resumable uploads follow.
+const tusVersion = "1.0.0"
+
 type mediaRoutes struct {
 	l   logger.Interface
 	cfg *config.Config
 	ms  service.IMediaService
+	is  service.IImageService
 }
 
-func newMediaRoutes(handler *gin.RouterGroup, l logger.Interface, db *gorm.DB, cfg *config.Config, ms service.IMediaService) {
-	r := &mediaRoutes{l: l, cfg: cfg, ms: ms}
+func newMediaRoutes(handler *gin.RouterGroup, l logger.Interface, db *gorm.DB, cfg *config.Config, ms service.IMediaService, is service.IImageService) {
+	r := &mediaRoutes{l: l, cfg: cfg, ms: ms, is: is}
+
+	h := handler.Group("media").Use(middleware.JWTAuthMiddleware(cfg, consttype.USER))
+	{
+		h.GET("", r.getMediaList)
+		h.GET("/:id", r.getMedia)
+		h.GET("/:id/download", r.downloadMedia)
+		h.DELETE("/:id", r.deleteMedia)
+		// Files are uploaded straight to the blob store, these requests stay
+		// small whatever the size of the file.
+		h.POST("/uploads", r.createUpload)
+		h.POST("/uploads/:id/complete", r.completeUpload)
+		h.POST("/:id/resize-url", r.createResizeUrl)
+		// Resumable uploads follow tus, a chunk cut off by the network is
+		// sent again from the offset reported by HEAD.
+		h.POST("/resumable", r.createResumableUpload)
+		h.HEAD("/resumable/:id", r.getResumableUpload)
+		h.PATCH("/resumable/:id", r.appendUpload)
+		h.DELETE("/resumable/:id", r.deleteMedia)
+	}
 
-	h := handler.Group("media").Use(middleware.Timeout(time.Duration(cfg.App.Timeout) * time.Second))
+	// Resized images are requested by URLs signed for the owner, so they can
+	// be shown without a login.
+	p := handler.Group("media")
 	{
-		h.POST("/upload", r.uploadMedia)
+		p.GET("/:id/resize", r.resizeMedia)
+	}
+
+	t := handler.Group("media").Use(middleware.JWTAuthMiddleware(cfg, consttype.USER), middleware.Timeout(time.Duration(cfg.App.Timeout)*time.Second))
+	{
+		t.POST("/upload", r.uploadMedia)
+	}
+
+	a := handler.Group("admin/media").Use(middleware.JWTAuthMiddleware(cfg, consttype.ADMIN))
+	{
+		a.GET("/orphans", r.getOrphanReport)
+		a.POST("/orphans/sweep", r.sweepOrphans)
 	}
 }
 
+// uploadMedia streams the file part of the form to the service as it arrives,
+// the form is never buffered. A filename field sent before the file names it,
+// otherwise the name of the file part is used. A visibility field has to come
+// before the file as well.
 func (r *mediaRoutes) uploadMedia(ctx *gin.Context) {
 	var req request.MediaUploadRequest
 
-	err := ctx.ShouldBind(&req)
-	if err != nil {
-		ve := utils.ValidationResponse(err)
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
 
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
 			Message: "request invalid",
 			Debug:   nil,
-			Errors:  ve,
+			Errors:  err.Error(),
 		})
 		return
 	}
 
-	extension := "." + req.Filename[strings.LastIndex(req.Filename, ".")+1:]
-	if err := utils.ValidateExtension(extension); err != nil {
+	for req.File == nil {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+				Message: "request invalid",
+				Debug:   nil,
+				Errors:  err.Error(),
+			})
+			return
+		}
+
+		switch part.FormName() {
+		case "filename":
+			filename, err := io.ReadAll(io.LimitReader(part, maxFilenameLength+1))
+			if err != nil || len(filename) > maxFilenameLength {
+				utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+					Message: "request invalid",
+					Debug:   nil,
+					Errors:  "filename is not valid",
+				})
+				return
+			}
+			req.Filename = string(filename)
+		case "visibility":
+			visibility, err := io.ReadAll(io.LimitReader(part, 16))
+			if err != nil || (string(visibility) != consttype.MEDIA_PUBLIC.String() && string(visibility) != consttype.MEDIA_PRIVATE.String()) {
+				utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+					Message: "request invalid",
+					Debug:   nil,
+					Errors:  "visibility must be public or private",
+				})
+				return
+			}
+			req.Visibility = string(visibility)
+		case "file":
+			if req.Filename == "" {
+				req.Filename = part.FileName()
+			}
+			req.ContentType = part.Header.Get("Content-Type")
+			req.File = part
+		}
+	}
+
+	if req.File == nil || req.Filename == "" {
 		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
 			Message: "request invalid",
 			Debug:   nil,
-			Errors:  err.Error(),
+			Errors:  "file is required",
 		})
 		return
 	}
 
-	fileReader, err := utils.ReadRequestFile(req.File)
+	media, err := r.ms.UploadMedia(loggedInUser.ID, req, ctx)
 	if err != nil {
+		message := "something went wrong when uploading the media"
+		if mediaService.IsRejected(err) {
+			message = "request invalid"
+		}
 		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
-			Message: "request invalid",
+			Message: message,
 			Debug:   nil,
 			Errors:  err.Error(),
 		})
 		return
 	}
 
-	err = utils.GetReadableFileSize(float64(fileReader.Size()), extension)
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Upload Successful",
+		Data:    media,
+	})
+}
+
+func (r *mediaRoutes) getMediaList(ctx *gin.Context) {
+	var filter request.MediaFilter
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindQuery(&filter)
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
-			Message: "request invalid",
-			Debug:   nil,
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.MediaQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	media, err := r.ms.GetMediaList(loggedInUser.ID, filter, paginationReq, ctx)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Media",
+		Data:    media,
+	})
+}
+
+func (r *mediaRoutes) getMedia(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	media, err := r.ms.GetMedia(loggedInUser.ID, id, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusNotFound
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot get media",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Media",
+		Data:    media,
+	})
+}
+
+func (r *mediaRoutes) deleteMedia(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	err := r.ms.DeleteMedia(loggedInUser.ID, id, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusNotFound
+		} else if errors.Is(err, mediaService.ErrMediaInUse) {
+			code = http.StatusConflict
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot delete media",
+			Debug:   err,
 			Errors:  err.Error(),
 		})
 		return
 	}
 
-	uploadedMediaUrl, err := r.ms.UploadMedia(req, ctx)
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Media deleted",
+		Data:    nil,
+	})
+}
+
+func (r *mediaRoutes) createUpload(ctx *gin.Context) {
+	var req request.CreateMediaUploadRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
+		ve := utils.ValidationResponse(err)
+
 		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
-			Message: "something went wrong when uploading the media",
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  ve,
+		})
+		return
+	}
+
+	upload, err := r.ms.CreateUpload(loggedInUser.ID, req, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if mediaService.IsRejected(err) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot create upload",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, utils.SuccessRes{
+		Message: "Upload Created",
+		Data:    upload,
+	})
+}
+
+func (r *mediaRoutes) completeUpload(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	media, err := r.ms.CompleteUpload(loggedInUser.ID, id, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, mediaService.ErrUploadIncomplete):
+			code = http.StatusConflict
+		case errors.Is(err, mediaService.ErrUploadMismatch) || mediaService.IsRejected(err):
+			code = http.StatusUnprocessableEntity
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot complete upload",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Upload Completed",
+		Data:    media,
+	})
+}
+
+func (r *mediaRoutes) createResumableUpload(ctx *gin.Context) {
+	var req request.CreateMediaUploadRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ve := utils.ValidationResponse(err)
+
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  ve,
+		})
+		return
+	}
+
+	upload, err := r.ms.CreateResumableUpload(loggedInUser.ID, req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if mediaService.IsRejected(err) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot create upload",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	setUploadHeaders(ctx, upload)
+	ctx.Header("Location", upload.UploadUrl)
+	utils.SuccessResponse(ctx, http.StatusCreated, utils.SuccessRes{
+		Message: "Upload Created",
+		Data:    upload,
+	})
+}
+
+func (r *mediaRoutes) getResumableUpload(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	upload, err := r.ms.GetResumableUpload(loggedInUser.ID, id)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusNotFound
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot get upload",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	setUploadHeaders(ctx, upload)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusOK)
+}
+
+// appendUpload streams the body of the request to the service as a chunk of
+// the upload, starting at the Upload-Offset header.
+func (r *mediaRoutes) appendUpload(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	if ctx.ContentType() != "application/offset+octet-stream" {
+		utils.ErrorResponse(ctx, http.StatusUnsupportedMediaType, utils.ErrorRes{
+			Message: "request not valid",
 			Debug:   nil,
+			Errors:  "content type must be application/offset+octet-stream",
+		})
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  "Upload-Offset must be a number",
+		})
+		return
+	}
+
+	upload, err := r.ms.AppendUpload(loggedInUser.ID, id, offset, ctx.Request.Body, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, mediaService.ErrUploadOffsetMismatch) ||
+			errors.Is(err, mediaService.ErrUploadNotPending):
+			code = http.StatusConflict
+		case errors.Is(err, mediaService.ErrUploadTooLong):
+			code = http.StatusRequestEntityTooLarge
+		case mediaService.IsRejected(err):
+			code = http.StatusUnprocessableEntity
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot append upload",
+			Debug:   err,
 			Errors:  err.Error(),
 		})
 		return
 	}
 
-	response := response.MediaResponse{
-		UploadedUrl: uploadedMediaUrl,
+	setUploadHeaders(ctx, upload)
+	ctx.Status(http.StatusNoContent)
+}
+
+func setUploadHeaders(ctx *gin.Context, upload *response.ResumableUploadResponse) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	ctx.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+}
+
+func (r *mediaRoutes) createResizeUrl(ctx *gin.Context) {
+	var req request.ResizeMediaRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	resizeUrl, err := r.is.ResizeUrl(loggedInUser.ID, id, req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, mediaService.ErrMediaNotImage):
+			code = http.StatusUnprocessableEntity
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot create resize url",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
-		Message: "Upload Successful",
-		Data:    response,
+		Message: "Success Create Resize Url",
+		Data:    resizeUrl,
+	})
+}
+
+func (r *mediaRoutes) resizeMedia(ctx *gin.Context) {
+	var req request.SignedResizeMediaRequest
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	body, info, err := r.is.ResizeMedia(id, req, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, mediaService.ErrInvalidResizeSignature) ||
+			errors.Is(err, mediaService.ErrResizeUrlExpired):
+			code = http.StatusForbidden
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot resize media",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+	defer body.Close()
+
+	// A signed URL always renders the same image, private images are only
+	// cached by the browser until their URL expires.
+	cacheControl := "public, max-age=31536000, immutable"
+	if req.Expires != 0 {
+		cacheControl = fmt.Sprintf("private, max-age=%d", req.Expires-time.Now().Unix())
+	}
+	ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, map[string]string{
+		"Cache-Control": cacheControl,
+	})
+}
+
+// downloadMedia streams a media of the logged in user through the app, the
+// way to download private media without a presigned URL.
+func (r *mediaRoutes) downloadMedia(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := mediaID(ctx)
+	if !ok {
+		return
+	}
+
+	body, download, err := r.ms.DownloadMedia(loggedInUser.ID, id, ctx)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, mediaService.ErrUploadIncomplete),
+			errors.Is(err, mediaService.ErrMediaNotScanned):
+			code = http.StatusConflict
+		case errors.Is(err, mediaService.ErrMediaInfected):
+			code = http.StatusGone
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot download media",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, download.Size, download.MimeType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": download.Filename}),
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func mediaID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  "id must be a number",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (r *mediaRoutes) getOrphanReport(ctx *gin.Context) {
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.MediaQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	report, err := r.ms.GetOrphanReport(paginationReq)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Orphan Media",
+		Data:    report,
+	})
+}
+
+// sweepOrphans runs a sweep right away, a dryRun query lists what it would
+// delete without deleting it.
+func (r *mediaRoutes) sweepOrphans(ctx *gin.Context) {
+	var req request.SweepMediaRequest
+
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	dryRun := r.cfg.Media.GCDryRun
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	sweep, err := r.ms.SweepOrphans(dryRun, ctx)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Cannot sweep orphan media",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Sweep Orphan Media",
+		Data:    sweep,
 	})
 }
@@ -0,0 +1,404 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/middleware"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/internal/service/notification"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Streams end before the write timeout of the server cuts them off, clients
+// reconnect with the Last-Event-ID header and receive what they missed.
+const (
+	sseMaxDuration = time.Second * 60
+	sseHeartbeat   = time.Second * 20
+	sseRetryMillis = 1000
+)
+
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Unsubscribe</title>
+  <style>
+    body { font-family: sans-serif; margin: 2rem; }
+  </style>
+</head>
+<body>
+  <h1>Unsubscribe</h1>
+  <p>Stop receiving {{.Type}} notifications by {{.Channel}}?</p>
+  <form method="post" action="?token={{.Token}}">
+    <button type="submit">Unsubscribe</button>
+  </form>
+</body>
+</html>`))
+
+type notificationRoutes struct {
+	l     logger.Interface
+	cfg   *config.Config
+	s     service.INotificationService
+	inbox service.IInboxService
+}
+
+func newNotificationRoutes(handler *gin.RouterGroup, l logger.Interface, cfg *config.Config, s service.INotificationService, inbox service.IInboxService) {
+	r := &notificationRoutes{l: l, cfg: cfg, s: s, inbox: inbox}
+
+	h := handler.Group("users/me").Use(middleware.JWTAuthMiddleware(cfg, consttype.USER))
+	{
+		h.GET("/notification-preferences", r.getPreferences)
+		h.PUT("/notification-preferences", r.updatePreferences)
+		h.GET("/notifications", r.getNotifications)
+		h.GET("/notifications/stream", r.streamNotifications)
+		h.POST("/notifications/read-all", r.markAllRead)
+		h.POST("/notifications/:id/read", r.markRead)
+	}
+
+	// Unsubscribe links are signed, they work without logging in. Opening one
+	// only asks for a confirmation, as mail scanners open links too, which is
+	// then POSTed to the same link, like one-click unsubscribe does.
+	u := handler.Group("notifications")
+	{
+		u.GET("/unsubscribe", r.confirmUnsubscribe)
+		u.POST("/unsubscribe", r.unsubscribe)
+	}
+}
+
+func (r *notificationRoutes) getPreferences(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	preferences, err := r.s.GetPreferences(loggedInUser.ID)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Notification Preferences",
+		Data:    preferences,
+	})
+}
+
+func (r *notificationRoutes) updatePreferences(ctx *gin.Context) {
+	var req request.UpdateNotificationPreferencesRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ve := utils.ValidationResponse(err)
+
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  ve,
+		})
+		return
+	}
+
+	preferences, err := r.s.UpdatePreferences(loggedInUser.ID, req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, notification.ErrInvalidPreference) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot update notification preferences",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Update Notification Preferences",
+		Data:    preferences,
+	})
+}
+
+func (r *notificationRoutes) confirmUnsubscribe(ctx *gin.Context) {
+	var req request.UnsubscribeRequest
+
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ve := utils.ValidationResponse(err)
+
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  ve,
+		})
+		return
+	}
+
+	notificationType, channel, err := r.s.CheckUnsubscribe(req.Token)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, notification.ErrInvalidUnsubscribeToken) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot unsubscribe",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	err = unsubscribeTemplate.Execute(ctx.Writer, map[string]string{
+		"Token":   req.Token,
+		"Type":    strings.ReplaceAll(notificationType.String(), "_", " "),
+		"Channel": strings.ReplaceAll(channel.String(), "_", "-"),
+	})
+	if err != nil {
+		r.l.Error(err, "http - v1 - confirmUnsubscribe")
+	}
+}
+
+func (r *notificationRoutes) unsubscribe(ctx *gin.Context) {
+	var req request.UnsubscribeRequest
+
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ve := utils.ValidationResponse(err)
+
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  ve,
+		})
+		return
+	}
+
+	err = r.s.Unsubscribe(req.Token)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, notification.ErrInvalidUnsubscribeToken) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot unsubscribe",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Unsubscribed",
+		Data:    nil,
+	})
+}
+
+func (r *notificationRoutes) getNotifications(ctx *gin.Context) {
+	var filter request.InAppNotificationFilter
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindQuery(&filter)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.InAppNotificationQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	notifications, err := r.inbox.GetNotifications(loggedInUser.ID, filter, paginationReq)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success Get Notifications",
+		Data:    notifications,
+	})
+}
+
+func (r *notificationRoutes) markRead(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  "id must be a number",
+		})
+		return
+	}
+
+	err = r.inbox.MarkRead(loggedInUser.ID, uint(id))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusNotFound
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot mark notification as read",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Notification marked as read",
+		Data:    nil,
+	})
+}
+
+func (r *notificationRoutes) markAllRead(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := r.inbox.MarkAllRead(loggedInUser.ID)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Notifications marked as read",
+		Data:    nil,
+	})
+}
+
+// streamNotifications sends new notifications as Server-Sent Events named
+// "notification" with the notification ID as event ID. The token goes in the
+// Authorization header like on every other route, browsers need an
+// EventSource implementation that can set headers.
+func (r *notificationRoutes) streamNotifications(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	notifications, unsubscribe := r.inbox.Subscribe(loggedInUser.ID)
+	defer unsubscribe()
+
+	// Subscribe before replaying, a notification stored in between arrives
+	// twice and is skipped the second time.
+	var lastID uint
+	var missed []model.InAppNotification
+	if lastEventID, err := strconv.ParseUint(ctx.GetHeader("Last-Event-ID"), 10, 64); err == nil {
+		lastID = uint(lastEventID)
+		missed, err = r.inbox.GetNotificationsAfter(loggedInUser.ID, lastID)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+				Message: "Something went wrong",
+				Debug:   err,
+				Errors:  err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	fmt.Fprintf(ctx.Writer, "retry: %d\n\n", sseRetryMillis)
+	for _, notification := range missed {
+		if writeNotificationEvent(ctx.Writer, notification) != nil {
+			return
+		}
+		lastID = notification.ID
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	timeout := time.NewTimer(sseMaxDuration)
+	defer timeout.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-timeout.C:
+			return
+		case <-heartbeat.C:
+			_, err = io.WriteString(ctx.Writer, ": ping\n\n")
+		case notification := <-notifications:
+			if notification.ID <= lastID {
+				continue
+			}
+			err = writeNotificationEvent(ctx.Writer, notification)
+			lastID = notification.ID
+		}
+		if err != nil {
+			return
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+func writeNotificationEvent(w io.Writer, notification model.InAppNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", notification.ID, data)
+	return err
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/felixlambertv/go-cleanplate/config"
 	"github.com/felixlambertv/go-cleanplate/internal/middleware"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
 
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -41,10 +42,21 @@ func NewRouter(handler *gin.Engine, l logger.Interface, db *gorm.DB, cfg *config
 		http.Redirect(context.Writer, context.Request, deeplinkUrl, http.StatusSeeOther)
 	})
 
+	if cfg.App.Env == "local" && di.MailCatcherService != nil {
+		newDevMailRoutes(handler, l, di.MailCatcherService)
+	}
+
 	h := handler.Group("api/v1")
 	{
-		newAuthRoutes(h, l, cfg, di.AuthService, di.MailService)
+		newAuthRoutes(h, l, db, cfg, di.AuthService, di.MailService)
 		newUserRoutes(h, l, db, di.UserService, cfg)
-		newMediaRoutes(h, l, db, cfg, di.MediaService)
+		newMediaRoutes(h, l, db, cfg, di.MediaService, di.ImageService)
+		newJobRoutes(h, l, cfg, di.SchedulerService)
+		newMailRoutes(h, l, cfg, di.MailService)
+		newNotificationRoutes(h, l, cfg, di.NotificationService, di.InboxService)
+		newDeviceRoutes(h, l, cfg, di.PushService)
+		if verifier, ok := di.BlobStore.(storage.URLVerifier); ok {
+			newStorageRoutes(h, l, di.BlobStore, verifier)
+		}
 	}
 }
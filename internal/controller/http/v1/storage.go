@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSignedUploadSize caps uploads to presigned URLs of the app, S3 enforces
+// its own limits.
+const maxSignedUploadSize = 100 << 20
+
+// storageRoutes serve the signed URLs of blob stores without a storage
+// service of their own, so presigned URLs work the same with every driver.
+type storageRoutes struct {
+	l        logger.Interface
+	store    storage.BlobStore
+	verifier storage.URLVerifier
+}
+
+func newStorageRoutes(handler *gin.RouterGroup, l logger.Interface, store storage.BlobStore, verifier storage.URLVerifier) {
+	r := &storageRoutes{l: l, store: store, verifier: verifier}
+
+	h := handler.Group("storage")
+	{
+		h.GET("/*key", r.getBlob)
+		h.PUT("/*key", r.putBlob)
+	}
+}
+
+func (r *storageRoutes) getBlob(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	err := r.verifier.VerifyRequest(key, ctx.Request)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusForbidden, utils.ErrorRes{
+			Message: "Cannot get blob",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	body, info, err := r.store.Get(ctx, key)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			code = http.StatusNotFound
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot get blob",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, nil)
+}
+
+func (r *storageRoutes) putBlob(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	err := r.verifier.VerifyRequest(key, ctx.Request)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusForbidden, utils.ErrorRes{
+			Message: "Cannot put blob",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSignedUploadSize)
+	info, err := r.store.Put(ctx, key, body, storage.PutOptions{ContentType: ctx.GetHeader("Content-Type")})
+	if err != nil {
+		code := http.StatusInternalServerError
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			code = http.StatusRequestEntityTooLarge
+		} else if errors.Is(err, storage.ErrInvalidKey) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot put blob",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	if info.ETag != "" {
+		ctx.Header("ETag", info.ETag)
+	}
+	ctx.Status(http.StatusOK)
+}
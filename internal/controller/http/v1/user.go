@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/felixlambertv/go-cleanplate/internal/middleware"
 	"github.com/felixlambertv/go-cleanplate/internal/model"
 	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/internal/service/user"
 	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
 	"github.com/felixlambertv/go-cleanplate/pkg/utils"
@@ -35,8 +37,11 @@ func newUserRoutes(handler *gin.RouterGroup, l logger.Interface, db *gorm.DB, s
 	userHandler := handler.Group("users").Use(middleware.JWTAuthMiddleware(cfg, consttype.USER))
 	{
 		userHandler.PATCH("/country", r.updateUserCountry)
+		userHandler.PATCH("/phone", r.updateUserPhone)
 		userHandler.GET("/me", r.getCurrentUser)
-		userHandler.DELETE("/delete", r.deleteUser)
+		userHandler.PUT("/me/avatar", middleware.DbTransactionMiddleware(db), r.updateAvatar)
+		userHandler.DELETE("/me/avatar", middleware.DbTransactionMiddleware(db), r.removeAvatar)
+		userHandler.DELETE("/delete", middleware.DbTransactionMiddleware(db), r.deleteUser)
 	}
 }
 
@@ -130,8 +135,50 @@ func (r *userRoutes) updateUserCountry(ctx *gin.Context) {
 	})
 }
 
+func (r *userRoutes) updateUserPhone(ctx *gin.Context) {
+	var req request.UpdateUserPhoneRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	user, err := r.s.UpdateUserPhone(req, loggedInUser.ID)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, utils.ErrorRes{
+			Message: "Something went wrong",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success updating user",
+		Data:    user,
+	})
+}
+
 func (r *userRoutes) getUser(ctx *gin.Context) {
-	paginationReq := utils.GeneratePaginationFromRequest(ctx, model.User{})
+	paginationReq, err := utils.GeneratePaginationFromRequest(ctx, model.UserQuery)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
 
 	users, err := r.s.GetUsers(paginationReq)
 	if err != nil {
@@ -216,7 +263,8 @@ func (r *userRoutes) deleteUser(ctx *gin.Context) {
 		return
 	}
 
-	err := r.s.DeleteUser(loggedInUser.ID)
+	trx := ctx.MustGet("db_trx").(*gorm.DB)
+	err := r.s.WithTrx(trx).DeleteUser(loggedInUser.ID)
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
 			Message: "Something went wrong",
@@ -231,3 +279,83 @@ func (r *userRoutes) deleteUser(ctx *gin.Context) {
 		Data:    nil,
 	})
 }
+
+func (r *userRoutes) updateAvatar(ctx *gin.Context) {
+	var req request.UpdateAvatarRequest
+
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, utils.ErrorRes{
+			Message: "request not valid",
+			Debug:   err,
+			Errors:  utils.ValidationResponse(err),
+		})
+		return
+	}
+
+	r.setAvatar(ctx, loggedInUser.ID, &req.MediaID)
+}
+
+func (r *userRoutes) removeAvatar(ctx *gin.Context) {
+	loggedInUser, ok := getLoggedInUser(ctx)
+	if !ok {
+		return
+	}
+
+	r.setAvatar(ctx, loggedInUser.ID, nil)
+}
+
+func (r *userRoutes) setAvatar(ctx *gin.Context, userID uint, mediaID *uint) {
+	trx := ctx.MustGet("db_trx").(*gorm.DB)
+	updatedUser, err := r.s.WithTrx(trx).SetAvatar(userID, mediaID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusNotFound
+		} else if errors.Is(err, user.ErrAvatarNotImage) {
+			code = http.StatusBadRequest
+		}
+		utils.ErrorResponse(ctx, code, utils.ErrorRes{
+			Message: "Cannot update avatar",
+			Debug:   err,
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, utils.SuccessRes{
+		Message: "Success updating avatar",
+		Data:    updatedUser,
+	})
+}
+
+// getLoggedInUser returns the user set by JWTAuthMiddleware, it answers the
+// request itself when there is none.
+func getLoggedInUser(ctx *gin.Context) (response.UserResponse, bool) {
+	ctxUser, exists := ctx.Get("user")
+	if !exists {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Error getting user",
+			Debug:   nil,
+			Errors:  "User not found",
+		})
+		return response.UserResponse{}, false
+	}
+
+	loggedInUser, ok := ctxUser.(response.UserResponse)
+	if !ok {
+		utils.ErrorResponse(ctx, http.StatusNotFound, utils.ErrorRes{
+			Message: "Error getting user",
+			Debug:   nil,
+			Errors:  "Unable to assert User ID",
+		})
+		return response.UserResponse{}, false
+	}
+
+	return loggedInUser, true
+}
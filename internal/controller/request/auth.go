@@ -11,6 +11,7 @@ type (
 		Email    string `json:"email" binding:"required,email" example:"email@email.com"`
 		Password string `json:"password" binding:"required" example:"password123"`
 		Country  string `json:"country" binding:"required" example:"indonesia"`
+		Locale   string `json:"locale" binding:"omitempty,bcp47_language_tag" example:"id"`
 	}
 
 	VerifyTokenRequest struct {
@@ -19,7 +20,8 @@ type (
 	}
 
 	ForgotPasswordRequest struct {
-		Email string `json:"email" binding:"required,email"`
+		Email  string `json:"email" binding:"required,email"`
+		Locale string `json:"-"`
 	}
 
 	ResetPasswordRequest struct {
@@ -1,21 +1,156 @@
 package request
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
 
 type (
+	// SendEmailRequest is queued as JSON. Data holds the template data, its
+	// shape is checked against the EmailData type registered for Template.
+	// Locale is a BCP 47 tag picking the template and subject translation, an
+	// empty Subject uses the translated one. UserID links the email log to the
+	// user the email is about. UnsubscribeUrl is added to the template data and
+	// the List-Unsubscribe header of emails the user can opt out of.
 	SendEmailRequest struct {
-		Template string `validate:"required,oneof=reset_password.html verify_email.html"`
-		Subject  string `validate:"required"`
-		Name     string
-		Email    string `validate:"required,email"`
-		Token    int
-		LinkUrl  string
+		Template       string            `validate:"required,mail_template"`
+		UserID         uint              `json:",omitempty"`
+		Subject        string            `json:",omitempty"`
+		Locale         string            `json:",omitempty"`
+		To             []EmailAddress    `validate:"required,min=1,dive"`
+		Cc             []EmailAddress    `json:",omitempty" validate:"dive"`
+		Bcc            []EmailAddress    `json:",omitempty" validate:"dive"`
+		ReplyTo        *EmailAddress     `json:",omitempty"`
+		Headers        map[string]string `json:",omitempty"`
+		Data           json.RawMessage   `json:",omitempty"`
+		Attachments    []EmailAttachment `json:",omitempty" validate:"dive"`
+		UnsubscribeUrl string            `json:",omitempty" validate:"omitempty,url"`
+	}
+
+	EmailAddress struct {
+		Name  string `json:",omitempty"`
+		Email string `validate:"required,email"`
+	}
+
+	// EmailAttachment references a stored file, the content is only loaded
+	// when the email is sent so queued messages stay small.
+	EmailAttachment struct {
+		Filename    string `validate:"required"`
+		ContentType string `json:",omitempty"`
+		StorageKey  string `validate:"required"`
+	}
+
+	// EmailData is the typed data of one template.
+	EmailData interface {
+		EmailTemplate() string
+	}
+
+	VerifyEmailData struct {
+		Name  string
+		Token int `validate:"required"`
+	}
+
+	ResetPasswordData struct {
+		Name    string
+		LinkUrl string `validate:"required,url"`
+	}
+
+	ProductUpdateData struct {
+		Name    string
+		Title   string `validate:"required"`
+		Body    string `validate:"required"`
+		LinkUrl string `json:",omitempty" validate:"omitempty,url"`
 	}
 )
 
+// emailDataTypes lists the typed data of each template. Templates missing here
+// accept any JSON object as data.
+var emailDataTypes = map[string]func() EmailData{
+	VerifyEmailData{}.EmailTemplate():   func() EmailData { return &VerifyEmailData{} },
+	ResetPasswordData{}.EmailTemplate(): func() EmailData { return &ResetPasswordData{} },
+	ProductUpdateData{}.EmailTemplate(): func() EmailData { return &ProductUpdateData{} },
+}
+
+func (VerifyEmailData) EmailTemplate() string { return "verify_email.html" }
+
+func (ResetPasswordData) EmailTemplate() string { return "reset_password.html" }
+
+func (ProductUpdateData) EmailTemplate() string { return "product_update.html" }
+
+// NewSendEmailRequest builds a request for the template of data.
+func NewSendEmailRequest(data EmailData, to ...EmailAddress) (SendEmailRequest, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return SendEmailRequest{}, err
+	}
+
+	return SendEmailRequest{
+		Template: data.EmailTemplate(),
+		To:       to,
+		Data:     b,
+	}, nil
+}
+
+// NewEmailData returns an empty value of the data type registered for
+// template, or nil when the template has none.
+func NewEmailData(template string) EmailData {
+	newData, ok := emailDataTypes[template]
+	if !ok {
+		return nil
+	}
+	return newData()
+}
+
+// TemplateData decodes Data into a map for the template. Numbers are kept as
+// json.Number so tokens are not printed in exponent notation.
+func (s SendEmailRequest) TemplateData() (map[string]any, error) {
+	data := map[string]any{}
+	if len(s.Data) == 0 || string(s.Data) == "null" {
+		return data, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(s.Data))
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("email data is not an object: %w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalJSON also accepts the former flat shape with Name, Email, Token and
+// LinkUrl fields, so messages queued before the payload became generic are
+// still delivered.
+func (s *SendEmailRequest) UnmarshalJSON(b []byte) error {
+	type sendEmailRequest SendEmailRequest
+	var req struct {
+		sendEmailRequest
+		Name    string
+		Email   string
+		Token   int
+		LinkUrl string
+	}
+
+	err := json.Unmarshal(b, &req)
+	if err != nil {
+		return err
+	}
+
+	*s = SendEmailRequest(req.sendEmailRequest)
+	if len(s.To) > 0 || req.Email == "" {
+		return nil
+	}
+
+	s.To = []EmailAddress{{Name: req.Name, Email: req.Email}}
+	if len(s.Data) == 0 {
+		s.Data, err = json.Marshal(map[string]any{"Name": req.Name, "Token": req.Token, "LinkUrl": req.LinkUrl})
+	}
+
+	return err
+}
+
 func (s SendEmailRequest) ToString() string {
 	b, err := json.Marshal(s)
 	if err != nil {
@@ -24,3 +159,12 @@ func (s SendEmailRequest) ToString() string {
 	}
 	return string(b)
 }
+
+type (
+	EmailLogFilter struct {
+		UserID    uint   `form:"userId"`
+		Recipient string `form:"recipient" binding:"omitempty,email"`
+		Template  string `form:"template"`
+		Status    string `form:"status" binding:"omitempty,oneof=sent failed suppressed delivered bounced complained"`
+	}
+)
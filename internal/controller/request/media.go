@@ -1,10 +1,81 @@
 package request
 
-import "mime/multipart"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
 
 type (
+	// MediaUploadRequest is a file streamed from a multipart form, File reads
+	// the part as it arrives instead of a copy buffered in memory.
 	MediaUploadRequest struct {
-		Filename string                `form:"filename" binding:"required" example:"scenarioImage"`
-		File     *multipart.FileHeader `form:"file" binding:"required,file"`
+		Filename    string
+		ContentType string
+		Visibility  string
+		File        io.Reader
+	}
+
+	MediaFilter struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending pending_scan ready infected"`
+	}
+
+	// SweepMediaRequest runs a sweep of orphan media, DryRun defaults to the
+	// configured mode.
+	SweepMediaRequest struct {
+		DryRun *bool `form:"dryRun" example:"true"`
+	}
+
+	// CreateMediaUploadRequest declares the file the client is about to upload,
+	// the upload URL only accepts a file of this type and size.
+	CreateMediaUploadRequest struct {
+		Filename    string `json:"filename" binding:"required,max=255" example:"holiday.png"`
+		ContentType string `json:"contentType" binding:"required" example:"image/png"`
+		Size        int64  `json:"size" binding:"required,min=1" example:"524288"`
+		Visibility  string `json:"visibility" binding:"omitempty,oneof=public private" example:"private"`
+	}
+
+	// ResizeMediaRequest asks for a size of an image other than its variants.
+	// Fit keeps the whole image within Width and Height, crop fills them.
+	ResizeMediaRequest struct {
+		Width  int    `json:"width" form:"w" binding:"required,min=1,max=2048" example:"300"`
+		Height int    `json:"height" form:"h" binding:"required,min=1,max=2048" example:"300"`
+		Fit    string `json:"fit" form:"fit" binding:"required,oneof=fit crop" example:"crop"`
+		Format string `json:"format" form:"format" binding:"required,oneof=jpeg png webp" example:"webp"`
+	}
+
+	// SignedResizeMediaRequest is a resize through a URL given by the app, Sig
+	// signs the other parameters. URLs of private images expire at Expires,
+	// in Unix seconds.
+	SignedResizeMediaRequest struct {
+		ResizeMediaRequest
+		Expires int64  `form:"expires" binding:"omitempty,min=1"`
+		Sig     string `form:"sig" binding:"required"`
+	}
+
+	ProcessMediaRequest struct {
+		MediaID uint `validate:"required"`
+	}
+
+	ScanMediaRequest struct {
+		MediaID uint `validate:"required"`
 	}
 )
+
+func (p ProcessMediaRequest) ToString() string {
+	b, err := json.Marshal(p)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return ""
+	}
+	return string(b)
+}
+
+func (s ScanMediaRequest) ToString() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,39 @@
+package request
+
+import "github.com/felixlambertv/go-cleanplate/pkg/consttype"
+
+type (
+	// NotificationRequest is one notification for one user, every channel
+	// renders the part it can send. Title, Body and Link are the short form
+	// used by SMS, push and in-app notifications, Email is the template data of
+	// the email. Channels without content for them are skipped.
+	NotificationRequest struct {
+		Type   consttype.NotificationType
+		Locale string
+		Title  string
+		Body   string
+		Link   string
+		Email  EmailData
+		// UnsubscribeUrl is set by the notification service for types that can
+		// be opted out of.
+		UnsubscribeUrl string
+	}
+
+	UpdateNotificationPreferencesRequest struct {
+		Preferences []NotificationPreferenceRequest `json:"preferences" binding:"required,min=1,dive"`
+	}
+
+	NotificationPreferenceRequest struct {
+		Type    string `json:"type" binding:"required" example:"product_update"`
+		Channel string `json:"channel" binding:"required,oneof=email sms push in_app" example:"email"`
+		Enabled *bool  `json:"enabled" binding:"required" example:"false"`
+	}
+
+	UnsubscribeRequest struct {
+		Token string `form:"token" binding:"required"`
+	}
+
+	InAppNotificationFilter struct {
+		Unread bool `form:"unread"`
+	}
+)
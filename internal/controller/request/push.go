@@ -0,0 +1,36 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	RegisterDeviceRequest struct {
+		Token    string `json:"token" binding:"required,max=4096" example:"fcm-registration-token"`
+		Platform string `json:"platform" binding:"required,oneof=ios android" example:"android"`
+	}
+
+	// SendPushRequest is queued once per device, so a retry never pushes twice
+	// to a device that already received the notification. Data is passed to
+	// the app as is.
+	SendPushRequest struct {
+		UserID   uint                     `json:",omitempty"`
+		Token    string                   `validate:"required"`
+		Platform consttype.DevicePlatform `validate:"required,oneof=ios android"`
+		Title    string                   `validate:"required"`
+		Body     string                   `json:",omitempty"`
+		Data     map[string]string        `json:",omitempty"`
+	}
+)
+
+func (s SendPushRequest) ToString() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SendSmsRequest is queued once per message, To is the E.164 number of the
+// user when the message was queued.
+type SendSmsRequest struct {
+	UserID uint   `json:",omitempty"`
+	To     string `validate:"required,e164"`
+	Body   string `validate:"required"`
+}
+
+func (s SendSmsRequest) ToString() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return ""
+	}
+	return string(b)
+}
@@ -10,4 +10,13 @@ type (
 	UpdateUserCountryRequest struct {
 		Country string `json:"country" binding:"required"`
 	}
+
+	// UpdateUserPhoneRequest takes the number in E.164, as SMS are sent to it.
+	UpdateUserPhoneRequest struct {
+		Phone string `json:"phone" binding:"required,e164" example:"+6281234567890"`
+	}
+
+	UpdateAvatarRequest struct {
+		MediaID uint `json:"mediaId" binding:"required" example:"3"`
+	}
 )
@@ -0,0 +1,16 @@
+package response
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+)
+
+type (
+	JobResponse struct {
+		Name      string        `json:"name" example:"purge-expired-reset-tokens"`
+		Spec      string        `json:"spec" example:"@every 15m"`
+		NextRunAt time.Time     `json:"nextRunAt"`
+		LastRun   *model.JobRun `json:"lastRun"`
+	}
+)
@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type (
+	CapturedMailResponse struct {
+		ID      string    `json:"id" example:"6f1c2a4e-8d0b-4b55-9b51-2f3f8f0e9a11"`
+		SentAt  time.Time `json:"sentAt"`
+		From    string    `json:"from" example:"no-reply@example.com"`
+		To      []string  `json:"to" example:"user@example.com"`
+		Cc      []string  `json:"cc,omitempty"`
+		Bcc     []string  `json:"bcc,omitempty"`
+		ReplyTo string    `json:"replyTo,omitempty"`
+		Subject string    `json:"subject" example:"Verify your email"`
+		HTML    string    `json:"html,omitempty"`
+		Text    string    `json:"text,omitempty"`
+		Links   []string  `json:"links"`
+	}
+
+	MailPreviewResponse struct {
+		Template string `json:"template" example:"verify_email.html"`
+		Locale   string `json:"locale" example:"en"`
+		Subject  string `json:"subject" example:"Verification Code"`
+		HTML     string `json:"html"`
+		Text     string `json:"text"`
+	}
+)
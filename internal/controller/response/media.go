@@ -1,7 +1,91 @@
 package response
 
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
 type (
 	MediaResponse struct {
-		UploadedUrl string `json:"uploadedUrl"`
+		ID          uint                      `json:"id"`
+		UploadedUrl string                    `json:"uploadedUrl"`
+		Filename    string                    `json:"filename"`
+		MimeType    string                    `json:"mimeType"`
+		Size        int64                     `json:"size"`
+		Checksum    string                    `json:"checksum,omitempty"`
+		Width       *int                      `json:"width,omitempty"`
+		Height      *int                      `json:"height,omitempty"`
+		Duration    *float64                  `json:"duration,omitempty"`
+		Status      consttype.MediaStatus     `json:"status"`
+		Visibility  consttype.MediaVisibility `json:"visibility"`
+		// UrlExpiresAt is set for private media, their URLs stop working
+		// then and are renewed by getting the media again.
+		UrlExpiresAt *time.Time `json:"urlExpiresAt,omitempty"`
+		// Variants are generated after the upload, they are missing until
+		// then.
+		Variants  []MediaVariantResponse `json:"variants"`
+		CreatedAt time.Time              `json:"createdAt"`
+	}
+
+	MediaVariantResponse struct {
+		Name     string `json:"name" example:"thumbnail"`
+		Url      string `json:"url"`
+		MimeType string `json:"mimeType" example:"image/jpeg"`
+		Width    int    `json:"width" example:"200"`
+		Height   int    `json:"height" example:"200"`
+		Size     int64  `json:"size" example:"8192"`
+	}
+
+	ResizeUrlResponse struct {
+		Url       string     `json:"url"`
+		ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	}
+
+	// MediaDownload describes the content of a media streamed by the app.
+	MediaDownload struct {
+		Filename string `json:"filename"`
+		MimeType string `json:"mimeType"`
+		Size     int64  `json:"size"`
+	}
+
+	// MediaUploadResponse tells the client how to upload the file, the request
+	// must carry UploadHeaders.
+	MediaUploadResponse struct {
+		ID            uint              `json:"id"`
+		UploadMethod  string            `json:"uploadMethod" example:"PUT"`
+		UploadUrl     string            `json:"uploadUrl"`
+		UploadHeaders map[string]string `json:"uploadHeaders"`
+		ExpiresAt     time.Time         `json:"expiresAt"`
+	}
+
+	// ResumableUploadResponse is the state of a resumable upload, chunks are
+	// sent to UploadUrl from Offset on until it reaches Length.
+	ResumableUploadResponse struct {
+		ID        uint   `json:"id"`
+		UploadUrl string `json:"uploadUrl"`
+		Offset    int64  `json:"offset" example:"1048576"`
+		Length    int64  `json:"length" example:"31457280"`
+	}
+
+	// OrphanMediaReport lists the media no entity uses that were last changed
+	// before OlderThan, the ones the next sweep deletes unless DryRun.
+	OrphanMediaReport struct {
+		OlderThan time.Time         `json:"olderThan"`
+		DryRun    bool              `json:"dryRun"`
+		Count     int64             `json:"count" example:"12"`
+		Size      int64             `json:"size" example:"6291456"`
+		Media     *model.Pagination `json:"media"`
+	}
+
+	// MediaSweepResponse is what a sweep deleted, or would have deleted in a
+	// dry run. Failed counts media whose files could not all be deleted.
+	MediaSweepResponse struct {
+		OlderThan time.Time `json:"olderThan"`
+		DryRun    bool      `json:"dryRun"`
+		Count     int       `json:"count" example:"12"`
+		Size      int64     `json:"size" example:"6291456"`
+		Failed    int       `json:"failed"`
 	}
 )
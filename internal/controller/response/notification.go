@@ -0,0 +1,14 @@
+package response
+
+import "github.com/felixlambertv/go-cleanplate/pkg/consttype"
+
+type (
+	// NotificationPreferenceResponse lists the channels a notification type is
+	// sent on and whether the user receives it there. Transactional types are
+	// always sent.
+	NotificationPreferenceResponse struct {
+		Type          consttype.NotificationType             `json:"type" example:"product_update"`
+		Transactional bool                                   `json:"transactional" example:"false"`
+		Channels      map[consttype.NotificationChannel]bool `json:"channels"`
+	}
+)
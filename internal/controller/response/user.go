@@ -15,16 +15,21 @@ type (
 		UserLevel              uint           `json:"userLevel" example:"1"`
 		Country                string         `json:"country" example:"country"`
 		CountryCode            uint           `json:"countryCode" example:"62"`
+		Phone                  string         `json:"phone,omitempty" example:"+6281234567890"`
+		Locale                 string         `json:"locale" example:"id"`
+		AvatarMediaID          *uint          `json:"avatarMediaId,omitempty" example:"3"`
 		ScenarioCount          int            `json:"scenarioCount"`
 		ResetPasswordToken     string         `json:"-"`
 		ResetPasswordSentAt    time.Time      `json:"-"`
 		ConfirmationToken      int            `json:"-"`
 		ConfirmedAt            time.Time      `json:"confirmedAt"`
 		ConfirmationSentAt     time.Time      `json:"-"`
+		ConfirmationRemindedAt time.Time      `json:"-"`
 		RefreshToken           string         `json:"-"`
 		RefreshTokenExpiration string         `json:"-"`
 		CreatedAt              time.Time      `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
 		UpdatedAt              time.Time      `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
 		DeletedAt              gorm.DeletedAt `json:"-"`
+		Highlight              string         `json:"highlight,omitempty" example:"<mark>user</mark> name email@email.com"`
 	}
 )
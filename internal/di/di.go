@@ -1,46 +1,168 @@
 package di
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/felixlambertv/go-cleanplate/config"
+	deviceTokenR "github.com/felixlambertv/go-cleanplate/internal/repository/devicetoken"
+	emailLogR "github.com/felixlambertv/go-cleanplate/internal/repository/emaillog"
+	emailSuppressionR "github.com/felixlambertv/go-cleanplate/internal/repository/emailsuppression"
+	inAppNotificationR "github.com/felixlambertv/go-cleanplate/internal/repository/inappnotification"
+	jobR "github.com/felixlambertv/go-cleanplate/internal/repository/job"
+	mediaR "github.com/felixlambertv/go-cleanplate/internal/repository/media"
+	notificationPreferenceR "github.com/felixlambertv/go-cleanplate/internal/repository/notificationpreference"
+	outboxR "github.com/felixlambertv/go-cleanplate/internal/repository/outbox"
+	processedMessageR "github.com/felixlambertv/go-cleanplate/internal/repository/processedmessage"
 	userR "github.com/felixlambertv/go-cleanplate/internal/repository/user"
+	"github.com/felixlambertv/go-cleanplate/internal/scanner"
 	"github.com/felixlambertv/go-cleanplate/internal/service/auth"
 	"github.com/felixlambertv/go-cleanplate/internal/service/mail"
 	"github.com/felixlambertv/go-cleanplate/internal/service/media"
+	"github.com/felixlambertv/go-cleanplate/internal/service/notification"
+	"github.com/felixlambertv/go-cleanplate/internal/service/outbox"
+	"github.com/felixlambertv/go-cleanplate/internal/service/push"
 	"github.com/felixlambertv/go-cleanplate/internal/service/queue"
+	"github.com/felixlambertv/go-cleanplate/internal/service/scheduler"
+	"github.com/felixlambertv/go-cleanplate/internal/service/sms"
 	"github.com/felixlambertv/go-cleanplate/internal/service/user"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 )
 
 type DependencyInjection struct {
-	UserService  *user.UserService
-	MailService  *mail.MailService
-	AuthService  *auth.AuthService
-	QueueService *queue.QueueService
-	MediaService *media.MediaService
+	BlobStore   storage.BlobStore
+	UserService *user.UserService
+	MailService *mail.MailService
+	// MailCatcherService is only set when mail is captured instead of sent.
+	MailCatcherService  *mail.MailCatcherService
+	AuthService         *auth.AuthService
+	QueueService        *queue.QueueService
+	OutboxService       *outbox.OutboxService
+	MediaService        *media.MediaService
+	ImageService        *media.ImageService
+	SchedulerService    *scheduler.SchedulerService
+	NotificationService *notification.NotificationService
+	InboxService        *notification.InboxService
+	PushService         *push.PushService
 }
 
 func NewDependencyInjection(db *gorm.DB, l *logger.Logger, cfg *config.Config) *DependencyInjection {
 	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String(cfg.AWS.Region)}))
 	sqsClient := sqs.New(sess)
 
+	blobStore, err := storage.NewBlobStore(cfg, sess)
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - blob store: %w", err))
+	}
+
 	userRepo := userR.NewUserRepo(db, l)
-	userService := user.NewUserService(userRepo)
+	mediaRepo := mediaR.NewMediaRepo(db, l)
+	userService := user.NewUserService(userRepo, mediaRepo)
 
-	mailService := mail.NewMailService(l, cfg, userRepo)
-	queueService := queue.NewQueueService(cfg, mailService, sqsClient)
-	authService := auth.NewAuthService(userRepo, cfg, mailService, queueService)
+	mailTransport, err := mail.NewTransport(cfg, ses.New(sess))
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - mail transport: %w", err))
+	}
+	mailTemplates, err := mail.NewTemplateRegistry(l, "templates", cfg.App.Env == "local")
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - mail templates: %w", err))
+	}
+	validate := validator.New()
+	if err := mailTemplates.RegisterValidation(validate); err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - mail template validation: %w", err))
+	}
+	mailAttachments := mail.NewBlobAttachmentStore(blobStore)
+	emailLogRepo := emailLogR.NewEmailLogRepo(db, l)
+	emailSuppressionRepo := emailSuppressionR.NewEmailSuppressionRepo(db, l)
+	snsWebhook := mail.NewSNSWebhook(nil, cfg.Mail.SNSTopicArn, nil)
+	mailService := mail.NewMailService(l, cfg, userRepo, emailLogRepo, emailSuppressionRepo, mailTemplates, mailTransport, mailAttachments, snsWebhook)
+	var mailCatcherService *mail.MailCatcherService
+	if capture, ok := mailTransport.(*mail.CaptureTransport); ok {
+		mailCatcherService = mail.NewMailCatcherService(capture)
+	}
+	pushSenders, err := push.NewSenders(cfg, nil)
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - push senders: %w", err))
+	}
+	deviceTokenRepo := deviceTokenR.NewDeviceTokenRepo(db, l)
+	pushService := push.NewPushService(l, deviceTokenRepo, pushSenders)
+	smsSender, err := sms.NewSender(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - sms sender: %w", err))
+	}
+	smsService := sms.NewSmsService(l, smsSender)
+	mediaVariants, err := media.ParseVariantSpecs(cfg.Media.Variants)
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - media variants: %w", err))
+	}
+	imageService := media.NewImageService(cfg, blobStore, mediaRepo, mediaVariants)
+	mediaScanner, err := scanner.NewScanner(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - media scanner: %w", err))
+	}
+	scanService := media.NewScanService(l, blobStore, mediaRepo, mediaScanner, imageService)
+	processedMessageRepo := processedMessageR.NewProcessedMessageRepo(db, l)
+	queueService := queue.NewQueueService(cfg, mailService, pushService, smsService, imageService, scanService, sqsClient, processedMessageRepo, validate)
 
-	mediaService := media.NewMediaService(cfg)
+	outboxRepo := outboxR.NewOutboxRepo(db, l)
+	outboxService := outbox.NewOutboxService(outboxRepo, queueService)
+
+	notificationPreferenceRepo := notificationPreferenceR.NewNotificationPreferenceRepo(db, l)
+	inAppNotificationRepo := inAppNotificationR.NewInAppNotificationRepo(db, l)
+	inboxService := notification.NewInboxService(l, inAppNotificationRepo)
+	notificationService := notification.NewNotificationService(cfg, notificationPreferenceRepo,
+		notification.NewEmailChannel(outboxService),
+		notification.NewSmsChannel(outboxService),
+		notification.NewInAppChannel(inAppNotificationRepo),
+		notification.NewPushChannel(deviceTokenRepo, outboxService),
+	)
+
+	authService := auth.NewAuthService(userRepo, cfg, mailService, notificationService)
+
+	mediaValidator, err := media.NewValidator(cfg.Media.Types)
+	if err != nil {
+		l.Fatal(fmt.Errorf("di - NewDependencyInjection - media validator: %w", err))
+	}
+	mediaService := media.NewMediaService(l, cfg, blobStore, mediaValidator, mediaRepo, outboxService)
+
+	jobRepo := jobR.NewJobRepo(db, l)
+	schedulerService := scheduler.NewSchedulerService(jobRepo, l)
+	registerJobs(schedulerService, l, authService, queueService, mediaService)
 
 	return &DependencyInjection{
-		UserService:  userService,
-		MailService:  mailService,
-		AuthService:  authService,
-		QueueService: queueService,
-		MediaService: mediaService,
+		BlobStore:           blobStore,
+		UserService:         userService,
+		MailService:         mailService,
+		MailCatcherService:  mailCatcherService,
+		AuthService:         authService,
+		QueueService:        queueService,
+		OutboxService:       outboxService,
+		MediaService:        mediaService,
+		ImageService:        imageService,
+		SchedulerService:    schedulerService,
+		NotificationService: notificationService,
+		InboxService:        inboxService,
+		PushService:         pushService,
+	}
+}
+
+func registerJobs(s *scheduler.SchedulerService, l *logger.Logger, authService *auth.AuthService, queueService *queue.QueueService, mediaService *media.MediaService) {
+	jobs := []scheduler.Job{
+		{Name: "purge-expired-reset-tokens", Spec: "@every 15m", Run: authService.PurgeExpiredResetTokens},
+		{Name: "remind-unverified-users", Spec: "0 9 * * *", Run: authService.RemindUnverifiedUsers},
+		{Name: "purge-processed-messages", Spec: "@daily", Run: queueService.PurgeProcessedMessages},
+		{Name: "media-gc", Spec: "@hourly", Run: mediaService.SweepOrphanMedia},
+	}
+
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			l.Fatal(fmt.Errorf("di - registerJobs: %w", err))
+		}
 	}
 }
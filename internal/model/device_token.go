@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// DeviceToken is a push token of an app install. Android tokens are FCM
+	// registration tokens, iOS tokens are APNs device tokens. A token belongs to
+	// the user last logged in on the device.
+	DeviceToken struct {
+		ID        uint                     `gorm:"primary_key" json:"id"`
+		UserID    uint                     `json:"userId" gorm:"not null;index"`
+		Token     string                   `json:"token" gorm:"not null;unique"`
+		Platform  consttype.DevicePlatform `json:"platform" gorm:"not null" example:"android"`
+		CreatedAt time.Time                `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt time.Time                `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// EmailLog is one recipient of an outgoing email. Recipients of the same
+	// email share its ProviderMessageId.
+	EmailLog struct {
+		ID                uint                  `gorm:"primary_key" json:"id"`
+		UserID            *uint                 `json:"userId" gorm:"index"`
+		Template          string                `json:"template" gorm:"not null;index"`
+		Subject           string                `json:"subject"`
+		Recipient         string                `json:"recipient" gorm:"not null;index"`
+		Status            consttype.EmailStatus `json:"status" gorm:"not null;index"`
+		ProviderMessageId string                `json:"providerMessageId" gorm:"index"`
+		Error             string                `json:"error,omitempty"`
+		CreatedAt         time.Time             `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt         time.Time             `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
+
+var EmailLogQuery = QuerySpec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"template":  "template",
+		"recipient": "recipient",
+		"status":    "status",
+		"createdAt": "created_at",
+	},
+	Filters: map[string]FilterSpec{
+		"template":  {Column: "template", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"status":    {Column: "status", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"createdAt": {Column: "created_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	Search:      []string{"recipient", "subject"},
+	DefaultSort: "-id",
+}
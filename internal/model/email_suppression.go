@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// EmailSuppression is an address mail is no longer sent to, because it hard
+	// bounced or its owner marked our mail as spam.
+	EmailSuppression struct {
+		ID        uint                        `gorm:"primary_key" json:"id"`
+		Email     string                      `json:"email" gorm:"not null;unique"`
+		Reason    consttype.SuppressionReason `json:"reason" gorm:"not null"`
+		Detail    string                      `json:"detail,omitempty"`
+		CreatedAt time.Time                   `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt time.Time                   `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
+
+var EmailSuppressionQuery = QuerySpec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"email":     "email",
+		"reason":    "reason",
+		"createdAt": "created_at",
+	},
+	Filters: map[string]FilterSpec{
+		"reason":    {Column: "reason", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"createdAt": {Column: "created_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	Search:      []string{"email"},
+	DefaultSort: "id",
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// InAppNotification is an entry of the notification inbox of a user,
+	// ReadAt is nil while it is unread.
+	InAppNotification struct {
+		ID        uint                       `gorm:"primary_key" json:"id"`
+		UserID    uint                       `json:"userId" gorm:"not null;index"`
+		Type      consttype.NotificationType `json:"type" gorm:"not null" example:"product_update"`
+		Title     string                     `json:"title" gorm:"not null" example:"New feature"`
+		Body      string                     `json:"body,omitempty" example:"Try it out."`
+		Link      string                     `json:"link,omitempty" example:"https://example.com/features/new"`
+		ReadAt    *time.Time                 `json:"readAt"`
+		CreatedAt time.Time                  `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+	}
+)
+
+var InAppNotificationQuery = QuerySpec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"type":      "type",
+		"createdAt": "created_at",
+	},
+	Filters: map[string]FilterSpec{
+		"type":      {Column: "type", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"createdAt": {Column: "created_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	Search:      []string{"title", "body"},
+	DefaultSort: "-id",
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	JobRun struct {
+		ID         uint                 `gorm:"primary_key" json:"id"`
+		JobName    string               `json:"jobName" gorm:"not null;index"`
+		Trigger    consttype.JobTrigger `json:"trigger" gorm:"not null"`
+		Status     consttype.JobStatus  `json:"status" gorm:"not null"`
+		Error      string               `json:"error,omitempty"`
+		StartedAt  time.Time            `json:"startedAt"`
+		FinishedAt *time.Time           `json:"finishedAt"`
+		CreatedAt  time.Time            `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt  time.Time            `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
+
+var JobRunQuery = QuerySpec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"status":    "status",
+		"startedAt": "started_at",
+	},
+	Filters: map[string]FilterSpec{
+		"status":    {Column: "status", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"trigger":   {Column: "trigger", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ}},
+		"startedAt": {Column: "started_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	DefaultSort: "id",
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// Media is an uploaded file kept in the blob store under StorageKey.
+	// Checksum is the hex SHA-256 of the content. Width and Height are set for
+	// images, Duration in seconds for videos. UploadOffset is how much of a
+	// resumable upload has been received. Private media are only downloaded
+	// through URLs that expire. RefCount is how many entities use the media,
+	// collectable media used by none are garbage collected. Media stored
+	// before the collector existed are not collectable.
+	Media struct {
+		ID           uint                      `gorm:"primary_key" json:"id"`
+		UserID       uint                      `json:"userId" gorm:"not null;index"`
+		StorageKey   string                    `json:"-" gorm:"not null;unique"`
+		Filename     string                    `json:"filename" example:"holiday.png"`
+		MimeType     string                    `json:"mimeType" gorm:"not null" example:"image/png"`
+		Size         int64                     `json:"size" gorm:"not null" example:"524288"`
+		Checksum     string                    `json:"checksum,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+		Width        *int                      `json:"width,omitempty" example:"1920"`
+		Height       *int                      `json:"height,omitempty" example:"1080"`
+		Duration     *float64                  `json:"duration,omitempty" example:"12.5"`
+		Status       consttype.MediaStatus     `json:"status" gorm:"not null;index" example:"ready"`
+		Visibility   consttype.MediaVisibility `json:"visibility" gorm:"not null;default:public" example:"private"`
+		UploadOffset int64                     `json:"-" gorm:"not null;default:0"`
+		RefCount     int                       `json:"refCount" gorm:"not null;default:0;index"`
+		Collectable  bool                      `json:"-" gorm:"not null;default:false"`
+		Variants     []MediaVariant            `json:"variants,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+		UploadChunks []MediaUploadChunk        `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+		CreatedAt    time.Time                 `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt    time.Time                 `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+
+	// MediaUploadChunk is a part of a resumable upload received in one
+	// request, kept as its own blob until the upload is complete.
+	MediaUploadChunk struct {
+		ID         uint      `gorm:"primary_key" json:"id"`
+		MediaID    uint      `json:"mediaId" gorm:"not null;uniqueIndex:idx_media_upload_chunks_media_offset"`
+		Offset     int64     `json:"offset" gorm:"not null;uniqueIndex:idx_media_upload_chunks_media_offset"`
+		Size       int64     `json:"size" gorm:"not null"`
+		StorageKey string    `json:"-" gorm:"not null"`
+		CreatedAt  time.Time `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+	}
+
+	// MediaVariant is a resized copy of an image media, one per configured
+	// variant Name.
+	MediaVariant struct {
+		ID         uint      `gorm:"primary_key" json:"id"`
+		MediaID    uint      `json:"mediaId" gorm:"not null;uniqueIndex:idx_media_variants_media_name"`
+		Name       string    `json:"name" gorm:"not null;uniqueIndex:idx_media_variants_media_name" example:"thumbnail"`
+		StorageKey string    `json:"-" gorm:"not null"`
+		MimeType   string    `json:"mimeType" gorm:"not null" example:"image/jpeg"`
+		Width      int       `json:"width" example:"200"`
+		Height     int       `json:"height" example:"200"`
+		Size       int64     `json:"size" example:"8192"`
+		CreatedAt  time.Time `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt  time.Time `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
+
+var MediaQuery = QuerySpec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"filename":  "filename",
+		"mimeType":  "mime_type",
+		"size":      "size",
+		"createdAt": "created_at",
+	},
+	Filters: map[string]FilterSpec{
+		"mimeType":   {Column: "mime_type", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN, consttype.FILTER_LIKE}},
+		"visibility": {Column: "visibility", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ}},
+		"size":       {Column: "size", Type: consttype.FIELD_INT, Operators: []consttype.FilterOperator{consttype.FILTER_GTE, consttype.FILTER_LTE}},
+		"createdAt":  {Column: "created_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	Search:      []string{"filename"},
+	DefaultSort: "-id",
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// NotificationPreference overrides the default of one channel of a
+	// notification type for a user. Without a row the default applies.
+	NotificationPreference struct {
+		ID        uint                          `gorm:"primary_key" json:"id"`
+		UserID    uint                          `json:"userId" gorm:"not null;uniqueIndex:idx_notification_preference"`
+		Type      consttype.NotificationType    `json:"type" gorm:"not null;uniqueIndex:idx_notification_preference"`
+		Channel   consttype.NotificationChannel `json:"channel" gorm:"not null;uniqueIndex:idx_notification_preference"`
+		Enabled   bool                          `json:"enabled" gorm:"not null"`
+		CreatedAt time.Time                     `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt time.Time                     `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
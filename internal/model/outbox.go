@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	OutboxMessage struct {
+		ID              uint                `gorm:"primary_key" json:"id"`
+		QueueType       consttype.QueueType `json:"queueType" gorm:"not null"`
+		Body            string              `json:"body" gorm:"not null"`
+		DeduplicationId string              `json:"deduplicationId" gorm:"not null;unique"`
+		Attempts        int                 `json:"attempts" gorm:"not null;default:0"`
+		LastError       string              `json:"lastError"`
+		DeliverAt       *time.Time          `json:"deliverAt"`
+		PublishedAt     *time.Time          `json:"publishedAt" gorm:"index"`
+		CreatedAt       time.Time           `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
+		UpdatedAt       time.Time           `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
+	}
+)
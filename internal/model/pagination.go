@@ -1,23 +1,180 @@
 package model
 
-import "time"
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
 
 type (
+	// Pagination is a page of a list. Sort and Direction echo the request,
+	// the query itself only uses Sorts, Filters and SearchColumns, which come
+	// from the QuerySpec of the resource.
+	//
+	// In cursor mode the page starts after the position of Keyset, or at the
+	// start without one, and NextCursor and PrevCursor lead to the pages
+	// around it. TotalDatas and TotalPages stay empty with SkipCount.
 	Pagination struct {
-		Limit      int         `json:"limit,omitempty"`
-		Page       int         `json:"page,omitempty"`
-		Direction  string      `json:"direction,omitempty"`
-		Sort       string      `json:"sort,omitempty"`
-		Search     string      `json:"search,omitempty"`
-		Filter     Filter      `json:"-"`
-		TotalDatas int64       `json:"totalDatas"`
-		TotalPages int         `json:"totalPages"`
-		Data       interface{} `json:"datas"`
-	}
-
-	Filter struct {
-		Level       string `json:"level,omitempty"`
-		CreatedFrom time.Time
-		CreatedTo   time.Time
+		Limit         int               `json:"limit,omitempty"`
+		Page          int               `json:"page,omitempty"`
+		Direction     string            `json:"direction,omitempty"`
+		Sort          string            `json:"sort,omitempty"`
+		Search        string            `json:"search,omitempty"`
+		Sorts         []SortField       `json:"-"`
+		Filters       []FilterCondition `json:"-"`
+		SearchColumns []string          `json:"-"`
+		TextSearch    *TextSearch       `json:"-"`
+		CursorMode    bool              `json:"-"`
+		Keyset        *Keyset           `json:"-"`
+		SkipCount     bool              `json:"-"`
+		NextCursor    string            `json:"nextCursor,omitempty"`
+		PrevCursor    string            `json:"prevCursor,omitempty"`
+		TotalDatas    int64             `json:"totalDatas"`
+		TotalPages    int               `json:"totalPages"`
+		Data          interface{}       `json:"datas"`
+	}
+
+	// Page is a Pagination with typed items, as read by pagination.Page. It
+	// has the same JSON as Pagination.
+	Page[T any] struct {
+		Limit      int    `json:"limit,omitempty"`
+		Page       int    `json:"page,omitempty"`
+		Direction  string `json:"direction,omitempty"`
+		Sort       string `json:"sort,omitempty"`
+		Search     string `json:"search,omitempty"`
+		NextCursor string `json:"nextCursor,omitempty"`
+		PrevCursor string `json:"prevCursor,omitempty"`
+		TotalDatas int64  `json:"totalDatas"`
+		TotalPages int    `json:"totalPages"`
+		Items      []T    `json:"datas"`
+	}
+
+	// QuerySpec declares how a list can be queried. Sorts and Filters map the
+	// field names clients use to columns, Search lists the columns q is
+	// looked up in, unless TextSearch looks it up in full text. DefaultSort
+	// is used without a sort parameter, such as "-createdAt" for the newest
+	// first. Sort fields are also the JSON names of the listed rows, cursors
+	// are built from them, and id is required for cursors as it breaks ties.
+	QuerySpec struct {
+		Sorts       map[string]string
+		Filters     map[string]FilterSpec
+		Search      []string
+		TextSearch  *TextSearch
+		DefaultSort string
+	}
+
+	// TextSearch is the full-text search of a table. Vector is a tsvector
+	// column of its text, in the text search Config, and the words of q are
+	// looked up in it by prefix. The Fuzzy columns also match q by trigram
+	// similarity, for misspelled names. Both rank the rows for the relevance
+	// sort, and the matches in Headline, the SQL of the text shown to
+	// clients, are highlighted with <mark> in the highlight of the rows. The
+	// Like columns also match q anywhere with ILIKE, for text the parser
+	// keeps as one word such as emails.
+	TextSearch struct {
+		Table    string
+		Vector   string
+		Config   string
+		Fuzzy    []string
+		Like     []string
+		Headline string
+	}
+
+	FilterSpec struct {
+		Column    string
+		Type      consttype.FieldType
+		Operators []consttype.FilterOperator
+	}
+
+	SortField struct {
+		Field  string
+		Column string
+		Desc   bool
+	}
+
+	// FilterCondition is a filter of a request, Value is parsed as the type of
+	// the field and is a slice for FILTER_IN.
+	FilterCondition struct {
+		Column   string
+		Type     consttype.FieldType
+		Operator consttype.FilterOperator
+		Value    interface{}
+	}
+
+	// Keyset is a position in a list, the values of its sort fields in a row.
+	// A Backward keyset reads the rows before it.
+	Keyset struct {
+		Sort     string        `json:"s"`
+		Values   []interface{} `json:"v"`
+		Backward bool          `json:"b,omitempty"`
 	}
 )
+
+// NewPage returns the page of p with its items.
+func NewPage[T any](p Pagination, items []T) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &Page[T]{
+		Limit:      p.Limit,
+		Page:       p.Page,
+		Direction:  p.Direction,
+		Sort:       p.Sort,
+		Search:     p.Search,
+		NextCursor: p.NextCursor,
+		PrevCursor: p.PrevCursor,
+		TotalDatas: p.TotalDatas,
+		TotalPages: p.TotalPages,
+		Items:      items,
+	}
+}
+
+// RelevanceSort is the sort field of a TextSearch, from the best match.
+const RelevanceSort = "relevance"
+
+var ErrInvalidCursor = errors.New("cursor not valid")
+
+// EncodeCursor returns the opaque cursor clients send back for keyset.
+func EncodeCursor(keyset Keyset) (string, error) {
+	data, err := json.Marshal(keyset)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor reads a cursor of EncodeCursor. Whole numbers come back as
+// int64, so they compare with integer columns.
+func DecodeCursor(cursor string) (*Keyset, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var keyset Keyset
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&keyset); err != nil || len(keyset.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, value := range keyset.Values {
+		switch value := value.(type) {
+		case json.Number:
+			if n, err := value.Int64(); err == nil {
+				keyset.Values[i] = n
+			} else if f, err := value.Float64(); err == nil {
+				keyset.Values[i] = f
+			} else {
+				return nil, ErrInvalidCursor
+			}
+		case string, bool:
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return &keyset, nil
+}
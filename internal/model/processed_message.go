@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	ProcessedMessage struct {
+		ID             uint                `gorm:"primary_key" json:"id"`
+		IdempotencyKey string              `json:"idempotencyKey" gorm:"not null;unique"`
+		QueueType      consttype.QueueType `json:"queueType" gorm:"not null"`
+		ProcessedAt    time.Time           `json:"processedAt" gorm:"not null;index"`
+	}
+)
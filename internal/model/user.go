@@ -3,18 +3,22 @@ package model
 import (
 	"time"
 
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"gorm.io/gorm"
 )
 
 type (
 	User struct {
 		ID                     uint           `gorm:"primary_key" json:"id"`
-		FullName               string         `json:"fullName" gorm:"not null" example:"user name"`
-		Email                  string         `json:"email" gorm:"not null;unique" example:"email@email.com"`
+		FullName               string         `json:"fullName" gorm:"not null;index:idx_users_full_name_trgm,type:gin,expression:full_name gin_trgm_ops" example:"user name"`
+		Email                  string         `json:"email" gorm:"not null;unique;index:idx_users_email_trgm,type:gin,expression:email gin_trgm_ops" example:"email@email.com"`
 		Password               string         `json:"-" gorm:"not null" example:"password123"`
 		UserLevel              uint           `json:"userLevel" gorm:"not null" example:"1"`
 		Country                string         `json:"country" example:"country"`
 		CountryCode            uint           `json:"countryCode" example:"62"`
+		Phone                  string         `json:"phone,omitempty" example:"+6281234567890"`
+		Locale                 string         `json:"locale" example:"id"`
+		AvatarMediaID          *uint          `json:"avatarMediaId,omitempty" example:"3"`
 		RefreshToken           string         `json:"-"`
 		RefreshTokenExpiration string         `json:"-"`
 		ResetPasswordToken     string         `json:"-"`
@@ -22,8 +26,38 @@ type (
 		ConfirmationToken      int            `json:"-"`
 		ConfirmedAt            time.Time      `json:"-"`
 		ConfirmationSentAt     time.Time      `json:"-"`
+		ConfirmationRemindedAt time.Time      `json:"-"`
 		CreatedAt              time.Time      `json:"createdAt,omitempty" example:"2023-01-01T15:01:00+00:00"`
 		UpdatedAt              time.Time      `json:"updatedAt,omitempty" example:"2023-02-11T15:01:00+00:00"`
 		DeletedAt              gorm.DeletedAt `json:"-"`
+		SearchVector           string         `json:"-" gorm:"->;type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(full_name, '') || ' ' || coalesce(email, ''))) STORED;index:idx_users_search_vector,type:gin"`
 	}
 )
+
+var UserQuery = QuerySpec{
+	Sorts: map[string]string{
+		"id":        "users.id",
+		"fullName":  "full_name",
+		"email":     "email",
+		"userLevel": "user_level",
+		"country":   "country",
+		"createdAt": "users.created_at",
+		"updatedAt": "users.updated_at",
+	},
+	Filters: map[string]FilterSpec{
+		"userLevel": {Column: "user_level", Type: consttype.FIELD_INT, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"country":   {Column: "country", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN, consttype.FILTER_LIKE}},
+		"email":     {Column: "email", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_LIKE}},
+		"createdAt": {Column: "users.created_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	Search: []string{"full_name", "email"},
+	TextSearch: &TextSearch{
+		Table:    "users",
+		Vector:   "users.search_vector",
+		Config:   "simple",
+		Fuzzy:    []string{"full_name"},
+		Like:     []string{"email"},
+		Headline: "full_name || ' ' || email",
+	},
+	DefaultSort: "id",
+}
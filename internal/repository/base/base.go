@@ -0,0 +1,80 @@
+package base
+
+import (
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Repository is the CRUD of an entity T. Repositories embed it and add only
+// the queries of their entity, which read DB.
+type Repository[T any] struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewRepository[T any](db *gorm.DB, l logger.Interface) *Repository[T] {
+	return &Repository[T]{db: db, l: l}
+}
+
+// WithTrx returns the repository on the transaction, or itself without one.
+func (r *Repository[T]) WithTrx(trxHandle *gorm.DB) *Repository[T] {
+	if trxHandle == nil {
+		r.l.Error("transaction db not found")
+		return r
+	}
+	return &Repository[T]{db: trxHandle, l: r.l}
+}
+
+// DB is the connection of the repository, on its transaction if any.
+func (r *Repository[T]) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *Repository[T]) Create(entity *T) (*T, error) {
+	err := r.db.Create(entity).Error
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Update saves the non-zero fields of entity on the row with the id.
+func (r *Repository[T]) Update(entity T, id uint) (*T, error) {
+	err := r.db.Model(&entity).Where("id = ?", id).Updates(entity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (r *Repository[T]) FindByID(id uint) (*T, error) {
+	var entity T
+	err := r.db.First(&entity, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// FindOne returns the first row matching the conditions, which take the
+// arguments of gorm's Where.
+func (r *Repository[T]) FindOne(query interface{}, args ...interface{}) (*T, error) {
+	var entity T
+	err := r.db.Where(query, args...).Take(&entity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// List reads a page of the rows, narrowed by the scopes.
+func (r *Repository[T]) List(p model.Pagination, scopes ...func(db *gorm.DB) *gorm.DB) (*model.Page[T], error) {
+	return pagination.Page[T](r.db.Model(new(T)).Scopes(scopes...), p)
+}
+
+// Delete deletes the entity, softly if it has a DeletedAt.
+func (r *Repository[T]) Delete(entity *T) error {
+	return r.db.Delete(entity).Error
+}
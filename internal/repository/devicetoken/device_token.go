@@ -0,0 +1,67 @@
+package devicetoken
+
+import (
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceTokenRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewDeviceTokenRepo(db *gorm.DB, l logger.Interface) *DeviceTokenRepo {
+	return &DeviceTokenRepo{db: db, l: l}
+}
+
+func (d *DeviceTokenRepo) WithTrx(trxHandle *gorm.DB) repository.IDeviceTokenRepo {
+	if trxHandle == nil {
+		d.l.Error("transaction db not found")
+		return d
+	}
+	return &DeviceTokenRepo{db: trxHandle, l: d.l}
+}
+
+// Store registers the token, a token already registered moves to the user of
+// the new registration.
+func (d *DeviceTokenRepo) Store(device *model.DeviceToken) (*model.DeviceToken, error) {
+	err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "updated_at"}),
+	}).Create(device).Error
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (d *DeviceTokenRepo) FindByUserID(userID uint) ([]model.DeviceToken, error) {
+	var devices []model.DeviceToken
+	err := d.db.Where("user_id = ?", userID).Order("id asc").Find(&devices).Error
+	if err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Delete unregisters a token of the user, it returns gorm.ErrRecordNotFound
+// when the user has no such token.
+func (d *DeviceTokenRepo) Delete(userID uint, token string) error {
+	result := d.db.Where("user_id = ? AND token = ?", userID, token).Delete(&model.DeviceToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteByToken removes a token the push provider no longer accepts.
+func (d *DeviceTokenRepo) DeleteByToken(token string) (int64, error) {
+	result := d.db.Where("token = ?", token).Delete(&model.DeviceToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,66 @@
+package emaillog
+
+import (
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type EmailLogRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewEmailLogRepo(db *gorm.DB, l logger.Interface) *EmailLogRepo {
+	return &EmailLogRepo{db: db, l: l}
+}
+
+func (e *EmailLogRepo) Store(logs []model.EmailLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return e.db.Create(&logs).Error
+}
+
+// UpdateStatus sets the status of one recipient of a sent email. Bounces and
+// complaints are final, a late delivery notification does not override them.
+func (e *EmailLogRepo) UpdateStatus(providerMessageId string, recipient string, status consttype.EmailStatus, detail string) (int64, error) {
+	query := e.db.Model(&model.EmailLog{}).
+		Where("provider_message_id = ? AND lower(recipient) = lower(?)", providerMessageId, recipient)
+	if status == consttype.EMAIL_DELIVERED {
+		query = query.Where("status NOT IN ?", []consttype.EmailStatus{consttype.EMAIL_BOUNCED, consttype.EMAIL_COMPLAINED})
+	}
+
+	result := query.Updates(map[string]interface{}{"status": status, "error": detail})
+	return result.RowsAffected, result.Error
+}
+
+func (e *EmailLogRepo) FindAll(filter request.EmailLogFilter, p model.Pagination) (*model.Pagination, error) {
+	var logs []model.EmailLog
+
+	result := e.db.Model(&logs)
+	if filter.UserID != 0 {
+		result = result.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Recipient != "" {
+		result = result.Where("lower(recipient) = lower(?)", filter.Recipient)
+	}
+	if filter.Template != "" {
+		result = result.Where("template = ?", filter.Template)
+	}
+	if filter.Status != "" {
+		result = result.Where("status = ?", filter.Status)
+	}
+	result = result.Scopes(pagination.Filter(&p))
+
+	result = result.Scopes(pagination.Paginate(&logs, &p, result)).Find(&logs)
+	if result.Error != nil {
+		return &p, result.Error
+	}
+
+	err := pagination.SetData(&p, logs)
+	return &p, err
+}
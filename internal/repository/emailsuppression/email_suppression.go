@@ -0,0 +1,80 @@
+package emailsuppression
+
+import (
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailSuppressionRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewEmailSuppressionRepo(db *gorm.DB, l logger.Interface) *EmailSuppressionRepo {
+	return &EmailSuppressionRepo{db: db, l: l}
+}
+
+// FindSuppressed returns the given addresses that are suppressed, lower cased.
+func (e *EmailSuppressionRepo) FindSuppressed(emails []string) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	lowered := make([]string, 0, len(emails))
+	for _, email := range emails {
+		lowered = append(lowered, strings.ToLower(email))
+	}
+
+	var suppressed []string
+	err := e.db.Model(&model.EmailSuppression{}).Where("email IN ?", lowered).Pluck("email", &suppressed).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return suppressed, nil
+}
+
+// Store suppresses an address, a complaint replaces an earlier bounce reason.
+func (e *EmailSuppressionRepo) Store(suppression *model.EmailSuppression) (*model.EmailSuppression, error) {
+	suppression.Email = strings.ToLower(suppression.Email)
+
+	err := e.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "updated_at"}),
+	}).Create(suppression).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return suppression, nil
+}
+
+func (e *EmailSuppressionRepo) FindAll(p model.Pagination) (*model.Pagination, error) {
+	var suppressions []model.EmailSuppression
+
+	result := e.db.Model(&suppressions).Scopes(pagination.Filter(&p))
+
+	result = result.Scopes(pagination.Paginate(&suppressions, &p, result)).Find(&suppressions)
+	if result.Error != nil {
+		return &p, result.Error
+	}
+
+	err := pagination.SetData(&p, suppressions)
+	return &p, err
+}
+
+func (e *EmailSuppressionRepo) Delete(email string) error {
+	result := e.db.Where("email = ?", strings.ToLower(email)).Delete(&model.EmailSuppression{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
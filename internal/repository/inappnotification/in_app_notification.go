@@ -0,0 +1,173 @@
+package inappnotification
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// notifyChannel is the Postgres channel new notifications are announced on,
+// so every replica can push them to the users connected to it.
+const notifyChannel = "in_app_notification"
+
+type (
+	InAppNotificationRepo struct {
+		l  logger.Interface
+		db *gorm.DB
+	}
+
+	notifyPayload struct {
+		ID     uint `json:"id"`
+		UserID uint `json:"userId"`
+	}
+)
+
+func NewInAppNotificationRepo(db *gorm.DB, l logger.Interface) *InAppNotificationRepo {
+	return &InAppNotificationRepo{db: db, l: l}
+}
+
+func (i *InAppNotificationRepo) WithTrx(trxHandle *gorm.DB) repository.IInAppNotificationRepo {
+	if trxHandle == nil {
+		i.l.Error("transaction db not found")
+		return i
+	}
+	return &InAppNotificationRepo{db: trxHandle, l: i.l}
+}
+
+// Store saves the notification and announces it. Inside a transaction the
+// announcement is only delivered once the transaction commits.
+func (i *InAppNotificationRepo) Store(notification *model.InAppNotification) (*model.InAppNotification, error) {
+	err := i.db.Create(notification).Error
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(notifyPayload{ID: notification.ID, UserID: notification.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.db.Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+func (i *InAppNotificationRepo) FindByID(id uint) (*model.InAppNotification, error) {
+	var notification model.InAppNotification
+	err := i.db.First(&notification, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (i *InAppNotificationRepo) FindAll(userID uint, filter request.InAppNotificationFilter, p model.Pagination) (*model.Pagination, error) {
+	var notifications []model.InAppNotification
+
+	result := i.db.Model(&notifications).Where("user_id = ?", userID)
+	if filter.Unread {
+		result = result.Where("read_at IS NULL")
+	}
+	result = result.Scopes(pagination.Filter(&p))
+
+	result = result.Scopes(pagination.Paginate(&notifications, &p, result)).Find(&notifications)
+	if result.Error != nil {
+		return &p, result.Error
+	}
+
+	err := pagination.SetData(&p, notifications)
+	return &p, err
+}
+
+// FindAfter returns the oldest notifications of the user created after afterID.
+func (i *InAppNotificationRepo) FindAfter(userID uint, afterID uint, limit int) ([]model.InAppNotification, error) {
+	var notifications []model.InAppNotification
+	err := i.db.Where("user_id = ? AND id > ?", userID, afterID).Order("id asc").Limit(limit).Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkRead returns gorm.ErrRecordNotFound when the user has no such
+// notification, marking a read notification again keeps the first read time.
+func (i *InAppNotificationRepo) MarkRead(userID uint, id uint) error {
+	result := i.db.Model(&model.InAppNotification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now().UTC()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (i *InAppNotificationRepo) MarkAllRead(userID uint) (int64, error) {
+	result := i.db.Model(&model.InAppNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now().UTC())
+	return result.RowsAffected, result.Error
+}
+
+// Listen calls handle for every notification announced until ctx is done or
+// the connection fails. It holds one connection of the pool while listening.
+func (i *InAppNotificationRepo) Listen(ctx context.Context, handle func(id uint, userID uint)) error {
+	sqlDB, err := i.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("listen needs a pgx connection")
+		}
+		pgConn := stdlibConn.Conn()
+
+		_, err := pgConn.Exec(ctx, "LISTEN "+notifyChannel)
+		if err != nil {
+			return err
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// The connection is still listening, or broken, either way it
+				// must not go back to the pool.
+				if ctx.Err() == nil {
+					i.l.Error(fmt.Errorf("in app notification listen: %w", err))
+				}
+				return driver.ErrBadConn
+			}
+
+			var payload notifyPayload
+			if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+				i.l.Error(fmt.Errorf("in app notification payload %q: %w", notification.Payload, err))
+				continue
+			}
+			handle(payload.ID, payload.UserID)
+		}
+	})
+}
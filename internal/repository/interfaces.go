@@ -1,8 +1,13 @@
 package repository
 
 import (
+	"context"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
 	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"gorm.io/gorm"
 )
 
@@ -11,11 +16,95 @@ import (
 type (
 	IUserRepo interface {
 		WithTrx(trxHandle *gorm.DB) IUserRepo
-		FindAll(p model.Pagination) (*model.Pagination, error)
-		Store(user *model.User) (*model.User, error)
+		FindAll(p model.Pagination) (*model.Page[response.UserResponse], error)
+		Create(user *model.User) (*model.User, error)
 		Update(user model.User, userID uint) (*model.User, error)
 		FindById(id uint) (*response.UserResponse, error)
 		FindByEmail(email string) (*response.UserResponse, error)
+		FindUnconfirmed(createdFrom time.Time, createdTo time.Time) ([]response.UserResponse, error)
+		ClearExpiredResetPasswordTokens(sentBefore time.Time) (int64, error)
+		SetAvatar(id uint, mediaID *uint) error
 		DeleteUser(user model.User) error
 	}
+
+	IOutboxRepo interface {
+		WithTrx(trxHandle *gorm.DB) IOutboxRepo
+		Transaction(fn func(repo IOutboxRepo) error) error
+		Store(message *model.OutboxMessage) (*model.OutboxMessage, error)
+		FindUnpublished(limit int, maxAttempts int) ([]model.OutboxMessage, error)
+		MarkPublished(id uint) error
+		MarkFailed(id uint, reason string) error
+	}
+
+	IJobRepo interface {
+		TryLock(name string) (func(), bool, error)
+		StoreRun(run *model.JobRun) (*model.JobRun, error)
+		UpdateRun(run model.JobRun) error
+		FindLastRun(name string) (*model.JobRun, error)
+		FindRuns(name string, p model.Pagination) (*model.Pagination, error)
+	}
+
+	IEmailLogRepo interface {
+		Store(logs []model.EmailLog) error
+		UpdateStatus(providerMessageId string, recipient string, status consttype.EmailStatus, detail string) (int64, error)
+		FindAll(filter request.EmailLogFilter, p model.Pagination) (*model.Pagination, error)
+	}
+
+	IEmailSuppressionRepo interface {
+		FindSuppressed(emails []string) ([]string, error)
+		Store(suppression *model.EmailSuppression) (*model.EmailSuppression, error)
+		FindAll(p model.Pagination) (*model.Pagination, error)
+		Delete(email string) error
+	}
+
+	INotificationPreferenceRepo interface {
+		WithTrx(trxHandle *gorm.DB) INotificationPreferenceRepo
+		FindByUserID(userID uint) ([]model.NotificationPreference, error)
+		Store(preferences []model.NotificationPreference) error
+	}
+
+	IInAppNotificationRepo interface {
+		WithTrx(trxHandle *gorm.DB) IInAppNotificationRepo
+		Store(notification *model.InAppNotification) (*model.InAppNotification, error)
+		FindByID(id uint) (*model.InAppNotification, error)
+		FindAll(userID uint, filter request.InAppNotificationFilter, p model.Pagination) (*model.Pagination, error)
+		FindAfter(userID uint, afterID uint, limit int) ([]model.InAppNotification, error)
+		MarkRead(userID uint, id uint) error
+		MarkAllRead(userID uint) (int64, error)
+		Listen(ctx context.Context, handle func(id uint, userID uint)) error
+	}
+
+	IDeviceTokenRepo interface {
+		WithTrx(trxHandle *gorm.DB) IDeviceTokenRepo
+		Store(device *model.DeviceToken) (*model.DeviceToken, error)
+		FindByUserID(userID uint) ([]model.DeviceToken, error)
+		Delete(userID uint, token string) error
+		DeleteByToken(token string) (int64, error)
+	}
+
+	IMediaRepo interface {
+		WithTrx(trxHandle *gorm.DB) IMediaRepo
+		Transaction(fn func(tx *gorm.DB) error) error
+		Store(media *model.Media) (*model.Media, error)
+		FindByID(id uint) (*model.Media, error)
+		FindAll(userID uint, filter request.MediaFilter, p model.Pagination) (*model.Pagination, error)
+		Update(media *model.Media) (*model.Media, error)
+		Delete(media *model.Media) error
+		StoreVariants(variants []model.MediaVariant) error
+		AppendUploadChunk(chunk *model.MediaUploadChunk) (bool, error)
+		FindUploadChunks(mediaID uint) ([]model.MediaUploadChunk, error)
+		DeleteUploadChunks(mediaID uint) error
+		Attach(id uint, userID uint) (bool, error)
+		Detach(id uint) error
+		FindOrphans(olderThan time.Time, p model.Pagination) (*model.Pagination, error)
+		FindOrphanBatch(olderThan time.Time, afterID uint, limit int) ([]model.Media, error)
+		SumOrphans(olderThan time.Time) (int64, int64, error)
+		DeleteOrphan(id uint, olderThan time.Time) (bool, error)
+	}
+
+	IProcessedMessageRepo interface {
+		Exists(idempotencyKey string) (bool, error)
+		Store(message *model.ProcessedMessage) (*model.ProcessedMessage, error)
+		DeleteBefore(processedBefore time.Time) (int64, error)
+	}
 )
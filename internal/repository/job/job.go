@@ -0,0 +1,101 @@
+package job
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type JobRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewJobRepo(db *gorm.DB, l logger.Interface) *JobRepo {
+	return &JobRepo{db: db, l: l}
+}
+
+// TryLock takes a Postgres session advisory lock for the job on a dedicated
+// connection, so only one replica runs the job at a time. The returned
+// function releases the lock and must be called once the job has finished.
+func (j *JobRepo) TryLock(name string) (func(), bool, error) {
+	ctx := context.Background()
+
+	sqlDB, err := j.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := lockKey(name)
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		return nil, false, err
+	}
+
+	unlock := func() {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+		if err != nil {
+			j.l.Error(err)
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+func (j *JobRepo) StoreRun(run *model.JobRun) (*model.JobRun, error) {
+	err := j.db.Create(run).Error
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (j *JobRepo) UpdateRun(run model.JobRun) error {
+	return j.db.Model(&model.JobRun{}).Where("id = ?", run.ID).Updates(map[string]any{
+		"status":      run.Status,
+		"error":       run.Error,
+		"finished_at": run.FinishedAt,
+	}).Error
+}
+
+func (j *JobRepo) FindLastRun(name string) (*model.JobRun, error) {
+	var run *model.JobRun
+	err := j.db.Where("job_name = ?", name).Order("id desc").Take(&run).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (j *JobRepo) FindRuns(name string, p model.Pagination) (*model.Pagination, error) {
+	var runs []model.JobRun
+
+	result := j.db.Model(&runs).Where("job_name = ?", name).Scopes(pagination.Filter(&p))
+	result = result.Scopes(pagination.Paginate(&runs, &p, result)).Find(&runs)
+	if result.Error != nil {
+		return &p, result.Error
+	}
+
+	err := pagination.SetData(&p, runs)
+	return &p, err
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("job:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,201 @@
+package media
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orphanCondition matches the collectable media no entity used since a time.
+const orphanCondition = "collectable AND ref_count = 0 AND updated_at < ?"
+
+type MediaRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewMediaRepo(db *gorm.DB, l logger.Interface) *MediaRepo {
+	return &MediaRepo{db: db, l: l}
+}
+
+func (m *MediaRepo) WithTrx(trxHandle *gorm.DB) repository.IMediaRepo {
+	if trxHandle == nil {
+		m.l.Error("transaction db not found")
+		return m
+	}
+	return &MediaRepo{db: trxHandle, l: m.l}
+}
+
+// Transaction runs fn in a transaction, other repositories and services join
+// it with WithTrx.
+func (m *MediaRepo) Transaction(fn func(tx *gorm.DB) error) error {
+	return m.db.Transaction(fn)
+}
+
+func (m *MediaRepo) Store(media *model.Media) (*model.Media, error) {
+	err := m.db.Create(media).Error
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (m *MediaRepo) FindByID(id uint) (*model.Media, error) {
+	var media model.Media
+	err := m.db.Preload("Variants").First(&media, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// Update saves the media alone, its variants are stored by StoreVariants. The
+// reference count is only changed by Attach and Detach, so concurrent
+// updates never lose a reference.
+func (m *MediaRepo) Update(media *model.Media) (*model.Media, error) {
+	err := m.db.Omit(clause.Associations, "RefCount").Save(media).Error
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (m *MediaRepo) FindAll(userID uint, filter request.MediaFilter, p model.Pagination) (*model.Pagination, error) {
+	var media []model.Media
+
+	result := m.db.Model(&media).Where("user_id = ?", userID)
+	if filter.Status != "" {
+		result = result.Where("status = ?", filter.Status)
+	}
+	result = result.Scopes(pagination.Filter(&p))
+
+	result = result.Scopes(pagination.Paginate(&media, &p, result)).Preload("Variants").Find(&media)
+	if result.Error != nil {
+		return &p, result.Error
+	}
+
+	err := pagination.SetData(&p, media)
+	return &p, err
+}
+
+func (m *MediaRepo) Delete(media *model.Media) error {
+	return m.db.Delete(media).Error
+}
+
+// StoreVariants replaces the variants of the same name, so processing a media
+// again keeps one row per variant.
+func (m *MediaRepo) StoreVariants(variants []model.MediaVariant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	return m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "media_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"storage_key", "mime_type", "width", "height", "size", "updated_at"}),
+	}).Create(&variants).Error
+}
+
+// AppendUploadChunk records chunk and moves the upload offset of its media past
+// it, only when the offset is still where the chunk starts. It returns false
+// when another request got there first.
+func (m *MediaRepo) AppendUploadChunk(chunk *model.MediaUploadChunk) (bool, error) {
+	appended := false
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Media{}).
+			Where("id = ? AND upload_offset = ?", chunk.MediaID, chunk.Offset).
+			Update("upload_offset", chunk.Offset+chunk.Size)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		err := tx.Create(chunk).Error
+		if err != nil {
+			return err
+		}
+		appended = true
+		return nil
+	})
+	return appended, err
+}
+
+func (m *MediaRepo) FindUploadChunks(mediaID uint) ([]model.MediaUploadChunk, error) {
+	var chunks []model.MediaUploadChunk
+	err := m.db.Where("media_id = ?", mediaID).Order("\"offset\" asc").Find(&chunks).Error
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+func (m *MediaRepo) DeleteUploadChunks(mediaID uint) error {
+	return m.db.Where("media_id = ?", mediaID).Delete(&model.MediaUploadChunk{}).Error
+}
+
+// Attach adds a reference to a media of the user. It returns false when the
+// user has no such media or it is infected.
+func (m *MediaRepo) Attach(id uint, userID uint) (bool, error) {
+	result := m.db.Model(&model.Media{}).
+		Where("id = ? AND user_id = ? AND status <> ?", id, userID, consttype.MEDIA_INFECTED).
+		Update("ref_count", gorm.Expr("ref_count + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (m *MediaRepo) Detach(id uint) error {
+	return m.db.Model(&model.Media{}).
+		Where("id = ? AND ref_count > 0", id).
+		Update("ref_count", gorm.Expr("ref_count - 1")).Error
+}
+
+func (m *MediaRepo) FindOrphans(olderThan time.Time, p model.Pagination) (*model.Pagination, error) {
+	var media []model.Media
+
+	result := m.db.Model(&media).Where(orphanCondition, olderThan).Scopes(pagination.Filter(&p))
+	result = result.Scopes(pagination.Paginate(&media, &p, result)).Find(&media)
+	if result.Error != nil {
+		return &p, result.Error
+	}
+
+	err := pagination.SetData(&p, media)
+	return &p, err
+}
+
+// FindOrphanBatch returns up to limit orphans after afterID in ID order, so a
+// sweep walks them all once whatever it deletes on the way.
+func (m *MediaRepo) FindOrphanBatch(olderThan time.Time, afterID uint, limit int) ([]model.Media, error) {
+	var media []model.Media
+	err := m.db.Where(orphanCondition, olderThan).Where("id > ?", afterID).
+		Order("id asc").Limit(limit).Find(&media).Error
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// SumOrphans returns how many orphans there are and their total size.
+func (m *MediaRepo) SumOrphans(olderThan time.Time) (int64, int64, error) {
+	var sum struct {
+		Count int64
+		Size  int64
+	}
+	err := m.db.Model(&model.Media{}).
+		Select("count(*) AS count, coalesce(sum(size), 0) AS size").
+		Where(orphanCondition, olderThan).
+		Scan(&sum).Error
+	return sum.Count, sum.Size, err
+}
+
+// DeleteOrphan deletes the media only while it is still an orphan. It returns
+// false when the media was attached or changed since it was found.
+func (m *MediaRepo) DeleteOrphan(id uint, olderThan time.Time) (bool, error) {
+	result := m.db.Where(orphanCondition, olderThan).Delete(&model.Media{}, id)
+	return result.RowsAffected > 0, result.Error
+}
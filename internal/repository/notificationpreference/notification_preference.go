@@ -0,0 +1,47 @@
+package notificationpreference
+
+import (
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepo(db *gorm.DB, l logger.Interface) *NotificationPreferenceRepo {
+	return &NotificationPreferenceRepo{db: db, l: l}
+}
+
+func (n *NotificationPreferenceRepo) WithTrx(trxHandle *gorm.DB) repository.INotificationPreferenceRepo {
+	if trxHandle == nil {
+		n.l.Error("transaction db not found")
+		return n
+	}
+	return &NotificationPreferenceRepo{db: trxHandle, l: n.l}
+}
+
+func (n *NotificationPreferenceRepo) FindByUserID(userID uint) ([]model.NotificationPreference, error) {
+	var preferences []model.NotificationPreference
+	err := n.db.Where("user_id = ?", userID).Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// Store creates the preferences or updates Enabled of existing ones.
+func (n *NotificationPreferenceRepo) Store(preferences []model.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	return n.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error
+}
@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewOutboxRepo(db *gorm.DB, l logger.Interface) *OutboxRepo {
+	return &OutboxRepo{db: db, l: l}
+}
+
+func (o *OutboxRepo) WithTrx(trxHandle *gorm.DB) repository.IOutboxRepo {
+	if trxHandle == nil {
+		o.l.Error("transaction db not found")
+		return o
+	}
+	return &OutboxRepo{db: trxHandle, l: o.l}
+}
+
+func (o *OutboxRepo) Transaction(fn func(repo repository.IOutboxRepo) error) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		return fn(o.WithTrx(tx))
+	})
+}
+
+func (o *OutboxRepo) Store(message *model.OutboxMessage) (*model.OutboxMessage, error) {
+	err := o.db.Create(message).Error
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// FindUnpublished locks the oldest pending rows so concurrent relays on other
+// replicas skip them instead of publishing the same message twice.
+func (o *OutboxRepo) FindUnpublished(limit int, maxAttempts int) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	err := o.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND attempts < ?", maxAttempts).
+		Order("id asc").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (o *OutboxRepo) MarkPublished(id uint) error {
+	return o.db.Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(map[string]any{
+		"published_at": time.Now().UTC(),
+		"last_error":   "",
+	}).Error
+}
+
+func (o *OutboxRepo) MarkFailed(id uint, reason string) error {
+	return o.db.Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": reason,
+	}).Error
+}
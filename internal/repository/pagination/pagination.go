@@ -1,11 +1,17 @@
 package pagination
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
+	"strings"
 
 	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func getLimit(p *model.Pagination) int {
@@ -22,35 +28,231 @@ func getPage(p *model.Pagination) int {
 	return p.Page
 }
 
-func getSort(p *model.Pagination) string {
-	builtQuery := "id desc"
-	direction := "asc"
-
-	if p.Direction != "" {
-		direction = p.Direction
+// getSort orders by the sorts of the request, whose columns come from the
+// QuerySpec of the resource and never from the request itself. A backward
+// keyset reads the rows before it in reverse. The relevance sort ranks the
+// rows by the search, whose arguments are returned along.
+func getSort(p *model.Pagination) (string, []interface{}) {
+	if len(p.Sorts) == 0 {
+		return "id desc", nil
 	}
 
-	if p.Sort != "" {
-		builtQuery = fmt.Sprintf("%s %s", p.Sort, direction)
+	orders := make([]string, len(p.Sorts))
+	var args []interface{}
+	for i, sort := range p.Sorts {
+		direction := "asc"
+		if sort.Desc != isBackward(p) {
+			direction = "desc"
+		}
+
+		column := sort.Column
+		if sort.Field == model.RelevanceSort && p.TextSearch != nil {
+			var rankArgs []interface{}
+			column, rankArgs = relevance(p)
+			args = append(args, rankArgs...)
+		}
+		orders[i] = fmt.Sprintf("%s %s", column, direction)
 	}
+	return strings.Join(orders, ", "), args
+}
 
-	return builtQuery
+func order(db *gorm.DB, p *model.Pagination) *gorm.DB {
+	sort, args := getSort(p)
+	if len(args) == 0 {
+		return db.Order(sort)
+	}
+	return db.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: sort, Vars: args, WithoutParentheses: true}})
 }
 
 func getOffset(p *model.Pagination) int {
 	return (getPage(p) - 1) * getLimit(p)
 }
 
+func isBackward(p *model.Pagination) bool {
+	return p.Keyset != nil && p.Keyset.Backward
+}
+
+// getKeyset is the condition of the rows after the keyset in the sort order,
+// (a > ?) OR (a = ? AND b > ?) and so on, flipped for descending sorts.
+func getKeyset(p *model.Pagination) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for i, sort := range p.Sorts {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, p.Sorts[j].Column+" = ?")
+			args = append(args, p.Keyset.Values[j])
+		}
+
+		operator := ">"
+		if sort.Desc != isBackward(p) {
+			operator = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s ?", sort.Column, operator))
+		args = append(args, p.Keyset.Values[i])
+		conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// Paginate counts the rows of db, unless SkipCount, and reads a page of them.
+// In cursor mode it reads one row past the limit, which SetData uses to know
+// whether there is more and then drops.
 func Paginate(model interface{}, pagination *model.Pagination, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
-	var totalDatas int64
-	db.Count(&totalDatas)
+	if !pagination.SkipCount {
+		var totalDatas int64
+		db.Count(&totalDatas)
+
+		totalPages := int(math.Ceil(float64(totalDatas) / float64(pagination.Limit)))
+
+		pagination.TotalDatas = totalDatas
+		pagination.TotalPages = totalPages
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if pagination.CursorMode {
+			if pagination.Keyset != nil {
+				condition, args := getKeyset(pagination)
+				db = db.Where(condition, args...)
+			}
+			return order(db.Limit(getLimit(pagination)+1), pagination)
+		}
+		return order(db.Offset(getOffset(pagination)).Limit(getLimit(pagination)), pagination)
+	}
+}
+
+// SetData sets the rows read by Paginate as the data of the page. In cursor
+// mode it drops the extra row, puts rows read backward back in order and sets
+// the cursors to the pages around them.
+func SetData(p *model.Pagination, data interface{}) error {
+	if !p.CursorMode {
+		p.Data = data
+		return nil
+	}
+
+	rows := reflect.ValueOf(data)
+	more := rows.Len() > getLimit(p)
+	if more {
+		rows = rows.Slice(0, getLimit(p))
+	}
+
+	backward := isBackward(p)
+	if backward {
+		reversed := reflect.MakeSlice(rows.Type(), rows.Len(), rows.Len())
+		for i := 0; i < rows.Len(); i++ {
+			reversed.Index(rows.Len() - 1 - i).Set(rows.Index(i))
+		}
+		rows = reversed
+	}
+	p.Data = rows.Interface()
+
+	if rows.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if more || backward {
+		p.NextCursor, err = cursorOf(p, rows.Index(rows.Len()-1).Interface(), false)
+		if err != nil {
+			return err
+		}
+	}
+	if (more && backward) || (!backward && p.Keyset != nil) {
+		p.PrevCursor, err = cursorOf(p, rows.Index(0).Interface(), true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Page filters db by p and reads a page of it into typed items, the way
+// Filter, Paginate and SetData do for a Pagination, with the highlight of
+// a text search.
+func Page[T any](db *gorm.DB, p model.Pagination) (*model.Page[T], error) {
+	var items []T
+	db = db.Scopes(Filter(&p))
+	err := db.Scopes(Paginate(&items, &p, db), Highlight(&p)).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = SetData(&p, items)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewPage(p, p.Data.([]T)), nil
+}
+
+// cursorOf encodes the position of row, read from its JSON fields named as
+// the sort fields.
+func cursorOf(p *model.Pagination, row interface{}, backward bool) (string, error) {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return "", err
+	}
 
-	totalPages := int(math.Ceil(float64(totalDatas) / float64(pagination.Limit)))
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return "", err
+	}
 
-	pagination.TotalDatas = totalDatas
-	pagination.TotalPages = totalPages
+	keyset := model.Keyset{Sort: p.Sort, Backward: backward, Values: make([]interface{}, len(p.Sorts))}
+	for i, sort := range p.Sorts {
+		value, ok := fields[sort.Field]
+		if !ok || value == nil {
+			return "", fmt.Errorf("pagination - cursor: row has no %s", sort.Field)
+		}
+		keyset.Values[i] = value
+	}
+	return model.EncodeCursor(keyset)
+}
 
+// Filter applies the filters and the search of p, in full text with a
+// TextSearch. Otherwise every column searched is matched with ILIKE, as LIKE
+// is case sensitive in Postgres.
+func Filter(p *model.Pagination) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Offset(getOffset(pagination)).Limit(getLimit(pagination)).Order(getSort(pagination))
+		for _, filter := range p.Filters {
+			column := filter.Column
+			if filter.Type == consttype.FIELD_DATE {
+				column = fmt.Sprintf("date(%s)", column)
+			}
+
+			switch filter.Operator {
+			case consttype.FILTER_EQ:
+				db = db.Where(column+" = ?", filter.Value)
+			case consttype.FILTER_IN:
+				db = db.Where(column+" IN ?", filter.Value)
+			case consttype.FILTER_GTE:
+				db = db.Where(column+" >= ?", filter.Value)
+			case consttype.FILTER_LTE:
+				db = db.Where(column+" <= ?", filter.Value)
+			case consttype.FILTER_LIKE:
+				db = db.Where(column+" ILIKE ?", likePattern(fmt.Sprint(filter.Value)))
+			}
+		}
+
+		if p.Search != "" && p.TextSearch != nil {
+			condition, args := textSearch(p)
+			db = db.Where(condition, args...)
+		} else if p.Search != "" && len(p.SearchColumns) > 0 {
+			conditions := make([]string, len(p.SearchColumns))
+			args := make([]interface{}, len(p.SearchColumns))
+			for i, column := range p.SearchColumns {
+				conditions[i] = column + " ILIKE ?"
+				args[i] = likePattern(p.Search)
+			}
+			db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		}
+
+		return db
 	}
 }
+
+// likePattern matches value anywhere, its own wildcards match themselves.
+func likePattern(value string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value) + "%"
+}
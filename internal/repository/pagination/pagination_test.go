@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type row struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+var sorts = []model.SortField{
+	{Field: "name", Column: "full_name", Desc: false},
+	{Field: "id", Column: "id", Desc: true},
+}
+
+func TestPagination_GetKeysetShouldCompareEverySortField(t *testing.T) {
+	p := &model.Pagination{Sorts: sorts, Keyset: &model.Keyset{Values: []interface{}{"ann", int64(7)}}}
+
+	condition, args := getKeyset(p)
+
+	assert.Equal(t, "((full_name > ?) OR (full_name = ? AND id < ?))", condition)
+	assert.Equal(t, []interface{}{"ann", "ann", int64(7)}, args)
+	sort, _ := getSort(p)
+	assert.Equal(t, "full_name asc, id desc", sort)
+
+	p.Keyset.Backward = true
+	condition, _ = getKeyset(p)
+
+	assert.Equal(t, "((full_name < ?) OR (full_name = ? AND id > ?))", condition)
+	sort, _ = getSort(p)
+	assert.Equal(t, "full_name desc, id asc", sort)
+}
+
+func TestPagination_SetDataShouldDropExtraRowAndSetCursors(t *testing.T) {
+	p := &model.Pagination{Limit: 2, Sort: "name", Sorts: sorts, CursorMode: true}
+
+	err := SetData(p, []row{{ID: 3, Name: "ann"}, {ID: 1, Name: "bob"}, {ID: 2, Name: "cat"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []row{{ID: 3, Name: "ann"}, {ID: 1, Name: "bob"}}, p.Data)
+	assert.Empty(t, p.PrevCursor)
+	next, err := model.DecodeCursor(p.NextCursor)
+	assert.Nil(t, err)
+	assert.Equal(t, model.Keyset{Sort: "name", Values: []interface{}{"bob", int64(1)}}, *next)
+}
+
+func TestPagination_SetDataShouldPutBackwardRowsInOrder(t *testing.T) {
+	p := &model.Pagination{Limit: 2, Sort: "name", Sorts: sorts, CursorMode: true, Keyset: &model.Keyset{Backward: true}}
+
+	err := SetData(p, []row{{ID: 1, Name: "bob"}, {ID: 3, Name: "ann"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []row{{ID: 3, Name: "ann"}, {ID: 1, Name: "bob"}}, p.Data)
+	assert.Empty(t, p.PrevCursor)
+	next, _ := model.DecodeCursor(p.NextCursor)
+	assert.Equal(t, []interface{}{"bob", int64(1)}, next.Values)
+	assert.False(t, next.Backward)
+}
+
+func TestPagination_PageShouldHaveTheJSONOfPagination(t *testing.T) {
+	p := model.Pagination{Limit: 2, Page: 1, Sort: "name", TotalDatas: 3, TotalPages: 2, Data: []row{{ID: 3, Name: "ann"}}}
+
+	want, _ := json.Marshal(p)
+	got, _ := json.Marshal(model.NewPage(p, []row{{ID: 3, Name: "ann"}}))
+
+	assert.JSONEq(t, string(want), string(got))
+	assert.Equal(t, []row{}, model.NewPage[row](p, nil).Items)
+}
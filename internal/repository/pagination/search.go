@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"gorm.io/gorm"
+)
+
+// tsQuery matches every word of search by prefix, so "jo sm" finds
+// "John Smith". Anything but letters and digits separates words, which keeps
+// the tsquery syntax out of it.
+func tsQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func tsQueryExpr(search *model.TextSearch) string {
+	return fmt.Sprintf("to_tsquery('%s', ?)", search.Config)
+}
+
+// textSearch is the condition of the rows matching the search of p, in
+// full text, by similarity of a fuzzy column or anywhere in a like column.
+func textSearch(p *model.Pagination) (string, []interface{}) {
+	conditions := []string{fmt.Sprintf("%s @@ %s", p.TextSearch.Vector, tsQueryExpr(p.TextSearch))}
+	args := []interface{}{tsQuery(p.Search)}
+	for _, column := range p.TextSearch.Fuzzy {
+		conditions = append(conditions, column+" % ?")
+		args = append(args, p.Search)
+	}
+	for _, column := range p.TextSearch.Like {
+		conditions = append(conditions, column+" ILIKE ?")
+		args = append(args, likePattern(p.Search))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// relevance ranks a row by how well it matches the search of p, the full
+// text rank along with the similarity of the fuzzy columns.
+func relevance(p *model.Pagination) (string, []interface{}) {
+	terms := []string{fmt.Sprintf("ts_rank(%s, %s)", p.TextSearch.Vector, tsQueryExpr(p.TextSearch))}
+	args := []interface{}{tsQuery(p.Search)}
+	for _, column := range p.TextSearch.Fuzzy {
+		terms = append(terms, fmt.Sprintf("similarity(%s, ?)", column))
+		args = append(args, p.Search)
+	}
+	return "(" + strings.Join(terms, " + ") + ")", args
+}
+
+// escapeHTML is the SQL of expr with the HTML special characters of its text
+// escaped.
+func escapeHTML(expr string) string {
+	return fmt.Sprintf("replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')", expr)
+}
+
+// Highlight selects the headline of the rows as highlight, with the matches
+// of the search of p in <mark>. The text is escaped before the marks are put
+// in, so the highlight is safe to render as HTML.
+func Highlight(p *model.Pagination) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.TextSearch == nil || p.TextSearch.Headline == "" || p.Search == "" {
+			return db
+		}
+
+		return db.Select(
+			fmt.Sprintf("%s.*, ts_headline('%s', %s, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight",
+				p.TextSearch.Table, p.TextSearch.Config, escapeHTML(p.TextSearch.Headline), tsQueryExpr(p.TextSearch)),
+			tsQuery(p.Search),
+		)
+	}
+}
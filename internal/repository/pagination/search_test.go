@@ -0,0 +1,47 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestSearch_TsQueryShouldMatchWordsByPrefix(t *testing.T) {
+	assert.Equal(t, "jo:* & sm:*", tsQuery(" jo  sm "))
+	assert.Equal(t, "ann:* & example:* & com:*", tsQuery("ann@example.com"))
+	assert.Equal(t, "a:* & b:*", tsQuery("a:* | !b"))
+}
+
+func TestSearch_ShouldSearchRankAndHighlight(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.Nil(t, err)
+
+	p := model.Pagination{
+		Limit:      10,
+		Page:       1,
+		Search:     "jon",
+		SkipCount:  true,
+		Sorts:      []model.SortField{{Field: model.RelevanceSort, Desc: true}},
+		TextSearch: model.UserQuery.TextSearch,
+	}
+	var users []model.User
+	query := db.Model(&users).Scopes(Filter(&p))
+	stmt := query.Scopes(Paginate(&users, &p, query), Highlight(&p)).Find(&users).Statement
+
+	assert.Equal(t, `SELECT users.*, ts_headline('simple', replace(replace(replace(full_name || ' ' || email, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), to_tsquery('simple', $1), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight FROM "users" `+
+		`WHERE ((users.search_vector @@ to_tsquery('simple', $2) OR full_name % $3 OR email ILIKE $4)) AND "users"."deleted_at" IS NULL `+
+		`ORDER BY (ts_rank(users.search_vector, to_tsquery('simple', $5)) + similarity(full_name, $6)) desc LIMIT 10`, stmt.SQL.String())
+	assert.Equal(t, []interface{}{"jon:*", "jon:*", "jon", "%jon%", "jon:*", "jon"}, stmt.Vars)
+}
+
+func TestSearch_ShouldFindEmailsWhole(t *testing.T) {
+	p := model.Pagination{Search: "ann@example.com", TextSearch: model.UserQuery.TextSearch}
+
+	condition, args := textSearch(&p)
+
+	assert.Equal(t, "(users.search_vector @@ to_tsquery('simple', ?) OR full_name % ? OR email ILIKE ?)", condition)
+	assert.Equal(t, []interface{}{"ann:* & example:* & com:*", "ann@example.com", "%ann@example.com%"}, args)
+}
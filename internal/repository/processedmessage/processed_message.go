@@ -0,0 +1,44 @@
+package processedmessage
+
+import (
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProcessedMessageRepo struct {
+	l  logger.Interface
+	db *gorm.DB
+}
+
+func NewProcessedMessageRepo(db *gorm.DB, l logger.Interface) *ProcessedMessageRepo {
+	return &ProcessedMessageRepo{db: db, l: l}
+}
+
+func (p *ProcessedMessageRepo) Exists(idempotencyKey string) (bool, error) {
+	var count int64
+	err := p.db.Model(&model.ProcessedMessage{}).Where("idempotency_key = ?", idempotencyKey).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Store ignores keys that are already recorded, which happens when two
+// workers handled the same delivery concurrently.
+func (p *ProcessedMessageRepo) Store(message *model.ProcessedMessage) (*model.ProcessedMessage, error) {
+	err := p.db.Clauses(clause.OnConflict{DoNothing: true}).Create(message).Error
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (p *ProcessedMessageRepo) DeleteBefore(processedBefore time.Time) (int64, error) {
+	result := p.db.Where("processed_at < ?", processedBefore).Delete(&model.ProcessedMessage{})
+	return result.RowsAffected, result.Error
+}
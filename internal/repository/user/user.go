@@ -1,97 +1,100 @@
 package user
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
 	"github.com/felixlambertv/go-cleanplate/internal/model"
 	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/repository/base"
 	"github.com/felixlambertv/go-cleanplate/internal/repository/pagination"
-	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
 	"gorm.io/gorm"
 )
 
+// userColumns are the columns users are read with. Responses have fields that
+// are not columns, such as the highlight only a search selects, so gorm must
+// not select their fields.
+const userColumns = "users.id, users.full_name, users.email, users.password, users.user_level, users.country, users.country_code, users.phone, users.locale, users.avatar_media_id, " +
+	"users.reset_password_token, users.reset_password_sent_at, users.confirmation_token, users.confirmed_at, users.confirmation_sent_at, users.confirmation_reminded_at, " +
+	"users.refresh_token, users.refresh_token_expiration, users.created_at, users.updated_at, users.deleted_at"
+
+// UserRepo has the CRUD of users from base.Repository, along with the
+// queries of users. Users are read as responses, with their tokens, for the
+// services to check them.
 type UserRepo struct {
-	l  logger.Interface
-	db *gorm.DB
+	*base.Repository[model.User]
+	l logger.Interface
 }
 
 func NewUserRepo(db *gorm.DB, l logger.Interface) *UserRepo {
-	return &UserRepo{db: db, l: l}
+	return &UserRepo{Repository: base.NewRepository[model.User](db, l), l: l}
 }
 
 func (u *UserRepo) WithTrx(trxHandle *gorm.DB) repository.IUserRepo {
-	if trxHandle == nil {
-		u.l.Error("transaction db not found")
-		return u
-	}
-	u.db = trxHandle
-	return u
+	return &UserRepo{Repository: u.Repository.WithTrx(trxHandle), l: u.l}
 }
 
-func (u *UserRepo) Store(user *model.User) (*model.User, error) {
-	err := u.db.Create(user).Error
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+// users reads users with userColumns.
+func (u *UserRepo) users() *gorm.DB {
+	return u.DB().Model(&model.User{}).Select(userColumns)
+}
+
+func (u *UserRepo) FindAll(p model.Pagination) (*model.Page[response.UserResponse], error) {
+	return pagination.Page[response.UserResponse](u.users(), p)
 }
 
-func (u *UserRepo) Update(user model.User, userID uint) (*model.User, error) {
-	err := u.db.Model(&user).Where("id = ?", userID).Updates(user).Error
+func (u *UserRepo) FindById(id uint) (*response.UserResponse, error) {
+	var user response.UserResponse
+	err := u.users().First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (u *UserRepo) FindAll(p model.Pagination) (*model.Pagination, error) {
-	var users []model.User
-	var usersResponse []response.UserResponse
-
-	result := u.db.Model(&users).Select("users.id as id, full_name, email, password, user_level, country, country_code, reset_password_token, reset_password_sent_at, confirmation_token, confirmed_at, confirmation_sent_at, users.created_at as created_at,refresh_token, refresh_token_expiration, users.updated_at as updated_at")
-
-	if p.Search != "" {
-		result = result.Where("full_name LIKE ?", fmt.Sprintf("%%%s%%", p.Search)).Or("email LIKE ?", fmt.Sprintf("%%%s%%", p.Search))
-	}
-
-	if !p.Filter.CreatedFrom.IsZero() && !p.Filter.CreatedTo.IsZero() {
-		result = result.Where("date(users.created_at) between ? and ?", p.Filter.CreatedFrom.Format(consttype.DATEFORMAT), p.Filter.CreatedTo.Format(consttype.DATEFORMAT))
-	}
-
-	result = result.Group("users.id").Scopes(pagination.Paginate(&users, &p, result)).Find(&usersResponse)
-
-	if result.Error != nil {
-		return &p, result.Error
+func (u *UserRepo) FindByEmail(email string) (*response.UserResponse, error) {
+	var user response.UserResponse
+	err := u.users().Where("email = ?", email).Take(&user).Error
+	if err != nil {
+		return nil, err
 	}
-
-	p.Data = usersResponse
-	return &p, nil
+	return &user, nil
 }
 
-func (u *UserRepo) FindById(id uint) (*response.UserResponse, error) {
-	var user *response.UserResponse
-	err := u.db.Model(&model.User{}).Select("users.id as id, full_name, email, password, user_level, country, country_code, reset_password_token, reset_password_sent_at, confirmation_token, confirmed_at, confirmation_sent_at, refresh_token, refresh_token_expiration, users.created_at as created_at, users.updated_at as updated_at").Group("users.id").First(&user, id).Error
+// FindUnconfirmed returns users created in the given window that have not
+// confirmed their email, have not been sent a verification email since
+// createdTo and have not been reminded yet.
+func (u *UserRepo) FindUnconfirmed(createdFrom time.Time, createdTo time.Time) ([]response.UserResponse, error) {
+	var users []response.UserResponse
+	err := u.DB().Model(&model.User{}).Select("users.id as id, full_name, email, user_level, country, country_code, confirmation_token, confirmed_at, confirmation_sent_at, users.created_at as created_at, users.updated_at as updated_at").
+		Where("(confirmed_at IS NULL OR confirmed_at = ?)", time.Time{}).
+		Where("(confirmation_reminded_at IS NULL OR confirmation_reminded_at = ?)", time.Time{}).
+		Where("users.created_at between ? and ?", createdFrom, createdTo).
+		Where("confirmation_sent_at < ?", createdTo).
+		Find(&users).Error
 	if err != nil {
 		return nil, err
 	}
 
-	return user, err
+	return users, nil
 }
 
-func (u *UserRepo) FindByEmail(email string) (*response.UserResponse, error) {
-	var user *response.UserResponse
-	err := u.db.Model(&model.User{}).Select("users.id as id, full_name, email, password, user_level, country, country_code, reset_password_token, reset_password_sent_at, confirmation_token, confirmed_at, confirmation_sent_at, refresh_token, refresh_token_expiration, users.created_at as created_at, users.updated_at as updated_at").Where("email = ?", email).Group("users.id").Take(&user).Error
-	if err != nil {
-		return nil, err
-	}
+func (u *UserRepo) ClearExpiredResetPasswordTokens(sentBefore time.Time) (int64, error) {
+	result := u.DB().Model(&model.User{}).
+		Where("reset_password_token <> '' AND reset_password_sent_at < ?", sentBefore).
+		Update("reset_password_token", "")
+
+	return result.RowsAffected, result.Error
+}
 
-	return user, err
+// SetAvatar sets the avatar media of the user, nil removes it.
+func (u *UserRepo) SetAvatar(id uint, mediaID *uint) error {
+	return u.DB().Model(&model.User{}).Where("id = ?", id).Update("avatar_media_id", mediaID).Error
 }
 
 func (u *UserRepo) DeleteUser(user model.User) error {
-	err := u.db.Unscoped().Delete(&user).Error
+	err := u.DB().Unscoped().Delete(&user).Error
 	if err != nil {
 		return err
 	}
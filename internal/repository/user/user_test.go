@@ -0,0 +1,74 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newDryRunRepo returns a repository whose queries are only built, along with
+// the SQL of the queries it ran. A dry run keeps the SQL of a statement, it is
+// reset as a real run does so the find after a count is built.
+func newDryRunRepo(t *testing.T) (*UserRepo, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.Nil(t, err)
+
+	var queries []string
+	err = db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		queries = append(queries, db.Statement.SQL.String())
+		db.Statement.SQL.Reset()
+		db.Statement.Vars = nil
+	})
+	assert.Nil(t, err)
+	return NewUserRepo(db, logger.NewLogger("error")), &queries
+}
+
+const selectUser = `SELECT users.id, users.full_name, users.email, users.password, users.user_level, users.country, users.country_code, users.phone, users.locale, users.avatar_media_id, ` +
+	`users.reset_password_token, users.reset_password_sent_at, users.confirmation_token, users.confirmed_at, users.confirmation_sent_at, users.confirmation_reminded_at, ` +
+	`users.refresh_token, users.refresh_token_expiration, users.created_at, users.updated_at, users.deleted_at FROM "users" `
+
+func TestUserRepo_FindByIdShouldSelectUserColumns(t *testing.T) {
+	repo, queries := newDryRunRepo(t)
+
+	_, err := repo.FindById(3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{selectUser + `WHERE "users"."id" = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT 1`}, *queries)
+}
+
+func TestUserRepo_FindByEmailShouldSelectUserColumns(t *testing.T) {
+	repo, queries := newDryRunRepo(t)
+
+	_, err := repo.FindByEmail("ann@example.com")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{selectUser + `WHERE email = $1 AND "users"."deleted_at" IS NULL LIMIT 1`}, *queries)
+}
+
+func TestUserRepo_FindAllShouldNotSelectHighlightWithoutSearch(t *testing.T) {
+	repo, queries := newDryRunRepo(t)
+
+	_, err := repo.FindAll(model.Pagination{Limit: 10, Page: 1})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`SELECT count(*) FROM "users" WHERE "users"."deleted_at" IS NULL`,
+		selectUser + `WHERE "users"."deleted_at" IS NULL ORDER BY id desc LIMIT 10`,
+	}, *queries)
+}
+
+func TestUserRepo_FindAllShouldSelectHighlightWithSearch(t *testing.T) {
+	repo, queries := newDryRunRepo(t)
+
+	_, err := repo.FindAll(model.Pagination{Limit: 10, Page: 1, Search: "jon", TextSearch: model.UserQuery.TextSearch})
+
+	assert.Nil(t, err)
+	if assert.Len(t, *queries, 2) {
+		assert.Contains(t, (*queries)[1], `SELECT users.*, ts_headline(`)
+		assert.Contains(t, (*queries)[1], `AS highlight FROM "users"`)
+	}
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamAVChunkSize is the size of the chunks content is streamed to clamd in.
+const clamAVChunkSize = 32 << 10
+
+// ClamAVScanner streams content to clamd with the INSTREAM command of its TCP
+// protocol. Content larger than StreamMaxLength of clamd fails to scan.
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{address: address, timeout: timeout}
+}
+
+func (c *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamav : %w", err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if c.timeout > 0 && (!ok || time.Now().Add(c.timeout).Before(deadline)) {
+		deadline = time.Now().Add(c.timeout)
+	}
+	if !deadline.IsZero() {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	writeErr := stream(conn, r)
+	// clamd answers before closing a stream it refuses, the answer explains
+	// a failed write better
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		if writeErr != nil {
+			return nil, fmt.Errorf("clamav : %w", writeErr)
+		}
+		return nil, fmt.Errorf("clamav : %w", err)
+	}
+
+	reply = strings.TrimSpace(strings.TrimSuffix(reply, "\x00"))
+	switch {
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return &Result{Infected: true, Signature: signature}, nil
+	case reply == "stream: OK":
+		if writeErr != nil {
+			return nil, fmt.Errorf("clamav : %w", writeErr)
+		}
+		return &Result{}, nil
+	default:
+		return nil, errors.New("clamav : " + reply)
+	}
+}
+
+// stream sends r as INSTREAM chunks, each prefixed with its length, followed
+// by an empty chunk.
+func stream(w io.Writer, r io.Reader) error {
+	_, err := w.Write([]byte("zINSTREAM\x00"))
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, readErr := r.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			_, err = w.Write(buf[:4+n])
+			if err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	_, err = w.Write([]byte{0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+)
+
+type (
+	// Scanner checks content for malware. An error means the content could
+	// not be scanned, not that it is infected.
+	Scanner interface {
+		Scan(ctx context.Context, r io.Reader) (*Result, error)
+	}
+
+	// Result of a scan, Signature names the malware found in infected
+	// content.
+	Result struct {
+		Infected  bool
+		Signature string
+	}
+)
+
+// NewScanner returns the scanner selected by SCANNER_DRIVER. clamav sends
+// content to clamd at SCANNER_CLAMAV_ADDRESS, noop passes everything and
+// eicar only finds the EICAR test file.
+func NewScanner(cfg *config.Config) (Scanner, error) {
+	switch strings.ToLower(cfg.Scanner.Driver) {
+	case "", "noop":
+		return NoopScanner{}, nil
+	case "clamav":
+		return NewClamAVScanner(cfg.Scanner.ClamAVAddress, time.Duration(cfg.Scanner.Timeout)*time.Second), nil
+	case "eicar":
+		return EICARScanner{}, nil
+	default:
+		return nil, fmt.Errorf("unknown scanner driver: %s", cfg.Scanner.Driver)
+	}
+}
+
+// NoopScanner finds every content clean, for environments without a
+// scanner.
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	_, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{}, nil
+}
+
+// EICAR is the standard antivirus test file, harmless but reported as
+// malware by every scanner.
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// EICARScanner is a test double that finds content containing the EICAR test
+// file infected, so the handling of malware can be tried without clamd.
+type EICARScanner struct{}
+
+func (EICARScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte(EICAR)) {
+		return &Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return &Result{}, nil
+}
@@ -0,0 +1,106 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClamd answers INSTREAM commands like clamd, content containing the
+// EICAR test file is reported infected.
+func fakeClamd(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				command, err := r.ReadString(0)
+				if err != nil || command != "zINSTREAM\x00" {
+					_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+
+				var content strings.Builder
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(r, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					if _, err := io.CopyN(&content, r, int64(n)); err != nil {
+						return
+					}
+				}
+
+				if strings.Contains(content.String(), EICAR) {
+					_, _ = conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					return
+				}
+				_, _ = conn.Write([]byte("stream: OK\x00"))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestClamAVScanner_ShouldReportInfectedContent(t *testing.T) {
+	scanner := NewClamAVScanner(fakeClamd(t), time.Second*5)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("a", 100<<10)+EICAR))
+	assert.Nil(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("a", 100<<10)))
+	assert.Nil(t, err)
+	assert.False(t, result.Infected)
+}
+
+func TestClamAVScanner_ShouldFailWithoutClamd(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err := NewClamAVScanner(address, time.Second).Scan(context.Background(), strings.NewReader("content"))
+
+	assert.NotNil(t, err)
+}
+
+func TestEICARScanner_ShouldOnlyFindTestFile(t *testing.T) {
+	result, err := EICARScanner{}.Scan(context.Background(), strings.NewReader("prefix "+EICAR))
+	assert.Nil(t, err)
+	assert.True(t, result.Infected)
+
+	result, err = NoopScanner{}.Scan(context.Background(), strings.NewReader(EICAR))
+	assert.Nil(t, err)
+	assert.False(t, result.Infected)
+}
+
+func TestNewScanner_ShouldRejectUnknownDriver(t *testing.T) {
+	_, err := NewScanner(&config.Config{Scanner: config.Scanner{Driver: "virustotal"}})
+	assert.NotNil(t, err)
+
+	scanner, err := NewScanner(&config.Config{Scanner: config.Scanner{Driver: "clamav", ClamAVAddress: "localhost:3310"}})
+	assert.Nil(t, err)
+	assert.IsType(t, &ClamAVScanner{}, scanner)
+}
@@ -23,11 +23,20 @@ type AuthService struct {
 	cfg      *config.Config
 	userRepo repository.IUserRepo
 	ms       service.IMailService
-	qs       service.IQueueService
+	ns       service.INotificationService
 }
 
-func NewAuthService(userRepo repository.IUserRepo, cfg *config.Config, ms service.IMailService, qs service.IQueueService) *AuthService {
-	return &AuthService{userRepo: userRepo, cfg: cfg, ms: ms, qs: qs}
+func NewAuthService(userRepo repository.IUserRepo, cfg *config.Config, ms service.IMailService, ns service.INotificationService) *AuthService {
+	return &AuthService{userRepo: userRepo, cfg: cfg, ms: ms, ns: ns}
+}
+
+func (a *AuthService) WithTrx(trxHandle *gorm.DB) service.IAuthService {
+	return &AuthService{
+		cfg:      a.cfg,
+		userRepo: a.userRepo.WithTrx(trxHandle),
+		ms:       a.ms,
+		ns:       a.ns.WithTrx(trxHandle),
+	}
 }
 
 func (a *AuthService) Login(req request.LoginRequest) (*response.UserResponse, *utils.TokenHeader, error) {
@@ -76,10 +85,11 @@ func (a *AuthService) Register(req request.RegisterRequest) (*response.UserRespo
 		Email:     req.Email,
 		Password:  hashedPassword,
 		Country:   req.Country,
+		Locale:    req.Locale,
 		UserLevel: consttype.USER,
 	}
 
-	userModel, err := a.userRepo.Store(userCreate)
+	userModel, err := a.userRepo.Create(userCreate)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -106,7 +116,7 @@ func (a *AuthService) ForgotPassword(req request.ForgotPasswordRequest) error {
 
 	token := utils.GenerateRandomStringToken(16)
 
-	err = a.SendResetPasswordEmail(user.ID, token)
+	err = a.SendResetPasswordEmail(user.ID, token, req.Locale)
 	if err != nil {
 		return err
 	}
@@ -146,7 +156,9 @@ func (a *AuthService) ResetPassword(req request.ResetPasswordRequest) error {
 	return nil
 }
 
-func (a *AuthService) SendResetPasswordEmail(id uint, token string) error {
+// SendResetPasswordEmail sends the email in the locale of the user profile, or
+// in the given request locale when the user has none.
+func (a *AuthService) SendResetPasswordEmail(id uint, token string, locale string) error {
 	user, err := a.userRepo.FindById(id)
 	if err != nil {
 		return err
@@ -166,16 +178,14 @@ func (a *AuthService) SendResetPasswordEmail(id uint, token string) error {
 		return err
 	}
 
-	emailData := request.SendEmailRequest{
-		Template: "reset_password.html",
-		Subject:  "Reset Password",
-		Name:     user.FullName,
-		Email:    user.Email,
-		Token:    0,
-		LinkUrl:  fmt.Sprintf("%s/app/reset-password/%s", a.cfg.App.Url, token),
-	}
-
-	err = a.qs.SendMessage(emailData.ToString(), consttype.SEND_EMAIL)
+	err = a.ns.Notify(user, request.NotificationRequest{
+		Type:   consttype.NOTIFICATION_RESET_PASSWORD,
+		Locale: userLocale(user, locale),
+		Email: request.ResetPasswordData{
+			Name:    user.FullName,
+			LinkUrl: fmt.Sprintf("%s/app/reset-password/%s", a.cfg.App.Url, token),
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -183,7 +193,9 @@ func (a *AuthService) SendResetPasswordEmail(id uint, token string) error {
 	return nil
 }
 
-func (a *AuthService) SendVerificationEmail(id uint, token int) error {
+// SendVerificationEmail sends the email in the locale of the user profile, or
+// in the given request locale when the user has none.
+func (a *AuthService) SendVerificationEmail(id uint, token int, locale string) error {
 	user, err := a.userRepo.FindById(id)
 	if err != nil {
 		return err
@@ -203,16 +215,14 @@ func (a *AuthService) SendVerificationEmail(id uint, token int) error {
 		return err
 	}
 
-	emailData := request.SendEmailRequest{
-		Template: "verify_email.html",
-		Subject:  "Verification Code",
-		Name:     user.FullName,
-		Email:    user.Email,
-		Token:    token,
-		LinkUrl:  "",
-	}
-
-	err = a.qs.SendMessage(emailData.ToString(), consttype.SEND_EMAIL)
+	err = a.ns.Notify(user, request.NotificationRequest{
+		Type:   consttype.NOTIFICATION_VERIFY_EMAIL,
+		Locale: userLocale(user, locale),
+		Email: request.VerifyEmailData{
+			Name:  user.FullName,
+			Token: token,
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -250,6 +260,46 @@ func (a *AuthService) VerifyToken(req request.VerifyTokenRequest) error {
 	return nil
 }
 
+// PurgeExpiredResetTokens clears reset password tokens that can no longer be
+// used, so a leaked token stops being valid even if it was never redeemed.
+func (a *AuthService) PurgeExpiredResetTokens() error {
+	_, err := a.userRepo.ClearExpiredResetPasswordTokens(time.Now().UTC().Add(-time.Minute * 5))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemindUnverifiedUsers reminds users who registered in the past week and
+// still have not confirmed their email after a day, once. The reminder
+// carries their pending code, so a code they are about to enter stays valid.
+func (a *AuthService) RemindUnverifiedUsers() error {
+	now := time.Now().UTC()
+	users, err := a.userRepo.FindUnconfirmed(now.AddDate(0, 0, -7), now.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, user := range users {
+		token := user.ConfirmationToken
+		if token == 0 {
+			token = utils.GenerateRandomToken()
+		}
+
+		err = a.SendVerificationEmail(user.ID, token, "")
+		if err == nil {
+			_, err = a.userRepo.Update(model.User{ConfirmationRemindedAt: now}, user.ID)
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("remind user %d: %w", user.ID, err)
+		}
+	}
+
+	return lastErr
+}
+
 func (a *AuthService) RefreshAuthToken(refreshToken string) (*response.UserResponse, *utils.TokenHeader, error) {
 	parsedToken, err := utils.ParseToken(refreshToken, a.cfg.App.Secret)
 	if err != nil {
@@ -277,6 +327,13 @@ func (a *AuthService) RefreshAuthToken(refreshToken string) (*response.UserRespo
 	return user, tokenHeader, err
 }
 
+func userLocale(user *response.UserResponse, requestLocale string) string {
+	if user.Locale != "" {
+		return user.Locale
+	}
+	return requestLocale
+}
+
 func verifyPassword(u *response.UserResponse, password string) error {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 
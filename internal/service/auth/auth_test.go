@@ -37,9 +37,9 @@ var cfg = &config.Config{
 }
 var userRepoMock = new(mocks.IUserRepo)
 var mailServiceMock = new(mocks.IMailService)
-var queueServiceMock = new(mocks.IQueueService)
+var notificationServiceMock = new(mocks.INotificationService)
 
-var authService = NewAuthService(userRepoMock, cfg, mailServiceMock, queueServiceMock)
+var authService = NewAuthService(userRepoMock, cfg, mailServiceMock, notificationServiceMock)
 
 var VerifyTokenRequest = request.VerifyTokenRequest{
 	Email: "user@test.com",
@@ -149,22 +149,14 @@ var userRegisterResult = &response.UserResponse{
 	DeletedAt:     gorm.DeletedAt{},
 }
 
-var emailData = request.SendEmailRequest{
-	Template: "verify_email.html",
-	Subject:  "Verification Code",
-	Name:     userDummy.FullName,
-	Email:    userDummy.Email,
-	Token:    8128,
-	LinkUrl:  "",
+var emailData = request.VerifyEmailData{
+	Name:  userDummy.FullName,
+	Token: 8128,
 }
 
-var resetPasswordEmailData = request.SendEmailRequest{
-	Template: "reset_password.html",
-	Subject:  "Reset Password",
-	Name:     userDummy.FullName,
-	Email:    userDummy.Email,
-	Token:    0,
-	LinkUrl:  fmt.Sprintf("%sreset-password/%s", cfg.DeeplinkUrl, "2l5hlPdxEdSi9bT5"),
+var resetPasswordEmailData = request.ResetPasswordData{
+	Name:    userDummy.FullName,
+	LinkUrl: fmt.Sprintf("%sreset-password/%s", cfg.DeeplinkUrl, "2l5hlPdxEdSi9bT5"),
 }
 
 var updateUserRequest = model.User{
@@ -260,7 +252,7 @@ func TestAuth_LoginShouldReturnInvalidPassword(t *testing.T) {
 
 func TestAuth_RegisterSuccess(t *testing.T) {
 	userRepoMock.On("FindByEmail", strings.ToLower(RegisterRequest.Email)).Return(nil, gorm.ErrRecordNotFound).Once()
-	userRepoMock.On("Store", mock.Anything).Return(userRegisterDummy, nil).Once()
+	userRepoMock.On("Create", mock.Anything).Return(userRegisterDummy, nil).Once()
 
 	user, token, err := authService.Register(RegisterRequest)
 	if err != nil {
@@ -273,7 +265,7 @@ func TestAuth_RegisterSuccess(t *testing.T) {
 
 func TestAuth_RegisterShouldReturnEmailFound(t *testing.T) {
 	userRepoMock.On("FindByEmail", notFoundEmailRegisterRequest.Email).Return(userResponseDummy, nil).Once()
-	userRepoMock.On("Store", mock.Anything).Return(nil, errors.New("something went wrong")).Once()
+	userRepoMock.On("Create", mock.Anything).Return(nil, errors.New("something went wrong")).Once()
 
 	user, token, err := authService.Register(notFoundEmailRegisterRequest)
 	if err != nil {
@@ -287,7 +279,7 @@ func TestAuth_RegisterShouldReturnEmailFound(t *testing.T) {
 
 func TestAuth_RegisterShouldReturnStoreError(t *testing.T) {
 	userRepoMock.On("FindByEmail", errorStoreRegisterRequest.Email).Return(nil, nil).Once()
-	userRepoMock.On("Store", mock.Anything).Return(nil, errors.New("something went wrong")).Once()
+	userRepoMock.On("Create", mock.Anything).Return(nil, errors.New("something went wrong")).Once()
 
 	user, token, err := authService.Register(errorStoreRegisterRequest)
 
@@ -299,18 +291,51 @@ func TestAuth_RegisterShouldReturnStoreError(t *testing.T) {
 func TestAuth_SendVerificationEmailSuccessful(t *testing.T) {
 	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
-	queueServiceMock.On("SendMessage", mock.Anything, mock.Anything).Return(nil).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token, "")
+
+	assert.Nil(t, err)
+}
+
+func TestAuth_SendVerificationEmailShouldUseRequestLocaleWhenUserHasNone(t *testing.T) {
+	BeforeEachVerificationTest(time.Now().UTC().Add(time.Minute*time.Duration(-10)), time.Time{})
+	notificationServiceMock.Calls = nil
+	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
+	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token, "id-ID")
+
+	assert.Nil(t, err)
+	notification := notificationServiceMock.Calls[0].Arguments.Get(1).(request.NotificationRequest)
+	assert.Equal(t, consttype.NOTIFICATION_VERIFY_EMAIL, notification.Type)
+	assert.Equal(t, "id-ID", notification.Locale)
+	assert.Equal(t, emailData.Token, notification.Email.(request.VerifyEmailData).Token)
+}
+
+func TestAuth_SendResetPasswordEmailShouldPreferUserLocale(t *testing.T) {
+	BeforeEachVerificationTest(time.Now().UTC().Add(time.Minute*time.Duration(-10)), time.Time{})
+	notificationServiceMock.Calls = nil
+	localizedUser := *userResponseDummy
+	localizedUser.Locale = "id"
+	userRepoMock.On("FindById", userDummy.ID).Return(&localizedUser, nil).Once()
+	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
 
-	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token)
+	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken, "en-US")
 
 	assert.Nil(t, err)
+	notification := notificationServiceMock.Calls[0].Arguments.Get(1).(request.NotificationRequest)
+	assert.Equal(t, consttype.NOTIFICATION_RESET_PASSWORD, notification.Type)
+	assert.Equal(t, "id", notification.Locale)
 }
 
 func TestAuth_SendVerificationEmailShouldReturnUserNotFound(t *testing.T) {
 	userRepoMock.On("FindById", userDummy.ID).Return(nil, gorm.ErrRecordNotFound).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
 
-	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token)
+	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token, "")
 
 	assert.Equal(t, err, gorm.ErrRecordNotFound)
 }
@@ -319,10 +344,10 @@ func TestAuth_SendVerificationEmailShouldReturnSendEmailError(t *testing.T) {
 	BeforeEachVerificationTest(time.Now().UTC().Add(time.Minute*time.Duration(-5)), time.Time{})
 
 	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
-	queueServiceMock.On("SendMessage", mock.Anything, mock.Anything).Return(errors.New("send to queue error")).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(errors.New("send to queue error")).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
 
-	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token)
+	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token, "")
 
 	assert.Equal(t, err, errors.New("send to queue error"))
 }
@@ -334,7 +359,7 @@ func TestAuth_SendVerificationEmailShouldReturnUpdateError(t *testing.T) {
 	mailServiceMock.On("SendEmail", emailData).Return(nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(nil, errors.New("update went wrong")).Once()
 
-	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token)
+	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token, "")
 
 	assert.Equal(t, err, errors.New("update went wrong"))
 }
@@ -346,7 +371,7 @@ func TestAuth_SendVerificationEmailShouldReturnAlreadyRequestedError(t *testing.
 	mailServiceMock.On("SendEmail", emailData).Return(errors.New("you already requested a verification message in less than 5 minutes")).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(nil, errors.New("something update went wrong")).Once()
 
-	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token)
+	err := authService.SendVerificationEmail(userDummy.ID, emailData.Token, "")
 
 	assert.Equal(t, err, errors.New("you already requested a verification message in less than 5 minutes"))
 }
@@ -414,7 +439,7 @@ func TestAuth_ForgotPasswordSuccessful(t *testing.T) {
 	userRepoMock.On("FindByEmail", userDummy.Email).Return(userResponseDummy, nil).Once()
 	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
-	queueServiceMock.On("SendMessage", mock.Anything, mock.Anything).Return(nil).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
 
 	err := authService.ForgotPassword(request.ForgotPasswordRequest{
 		Email: "user@test.com",
@@ -482,9 +507,9 @@ func TestAuth_ResetPasswordShouldReturnTokenExpired(t *testing.T) {
 func TestAuth_SendResetPasswordEmailSuccessful(t *testing.T) {
 	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
-	queueServiceMock.On("SendMessage", mock.Anything, mock.Anything).Return(nil).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
 
-	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken)
+	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken, "")
 
 	assert.Nil(t, err)
 }
@@ -494,7 +519,7 @@ func TestAuth_SendResetPasswordEmailShouldReturnUserNotFound(t *testing.T) {
 	mailServiceMock.On("SendEmail", resetPasswordEmailData).Return(nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
 
-	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken)
+	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken, "")
 
 	assert.Equal(t, err, gorm.ErrRecordNotFound)
 }
@@ -504,9 +529,9 @@ func TestAuth_SendResetPasswordEmailShouldReturnSendEmailError(t *testing.T) {
 
 	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(&userDummy, nil).Once()
-	queueServiceMock.On("SendMessage", mock.Anything, mock.Anything).Return(errors.New("sending email went wrong")).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(errors.New("sending email went wrong")).Once()
 
-	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken)
+	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken, "")
 
 	assert.Equal(t, err, errors.New("sending email went wrong"))
 }
@@ -518,7 +543,7 @@ func TestAuth_SendResetPasswordEmailShouldReturnUpdateError(t *testing.T) {
 	mailServiceMock.On("SendEmail", resetPasswordEmailData).Return(nil).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(nil, errors.New("update went wrong")).Once()
 
-	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken)
+	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken, "")
 
 	assert.Equal(t, err, errors.New("update went wrong"))
 }
@@ -530,7 +555,7 @@ func TestAuth_SendResetPasswordEmailShouldReturnAlreadyRequestedError(t *testing
 	mailServiceMock.On("SendEmail", resetPasswordEmailData).Return(errors.New("you already requested a verification message in less than 5 minutes")).Once()
 	userRepoMock.On("Update", mock.Anything, userDummy.ID).Return(nil, errors.New("something update went wrong")).Once()
 
-	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken)
+	err := authService.SendResetPasswordEmail(userDummy.ID, userDummy.ResetPasswordToken, "")
 
 	assert.Equal(t, err, errors.New("you already requested a reset password email in less than 5 minutes"))
 }
@@ -547,3 +572,40 @@ func TestAuth_RefreshAuthTokenSuccessful(t *testing.T) {
 	assert.Equal(t, assert.NotNil(t, token), true)
 	assert.Equal(t, err, nil)
 }
+
+func TestAuth_PurgeExpiredResetTokensSuccessful(t *testing.T) {
+	userRepoMock.On("ClearExpiredResetPasswordTokens", mock.Anything).Return(int64(2), nil).Once()
+
+	err := authService.PurgeExpiredResetTokens()
+
+	assert.Nil(t, err)
+}
+
+func TestAuth_PurgeExpiredResetTokensShouldReturnRepoError(t *testing.T) {
+	userRepoMock.On("ClearExpiredResetPasswordTokens", mock.Anything).Return(int64(0), errors.New("update went wrong")).Once()
+
+	err := authService.PurgeExpiredResetTokens()
+
+	assert.Equal(t, errors.New("update went wrong"), err)
+}
+
+func TestAuth_RemindUnverifiedUsersShouldSendVerificationEmail(t *testing.T) {
+	BeforeEachVerificationTest(time.Now().UTC().AddDate(0, 0, -2), time.Time{})
+	notificationServiceMock.Calls = nil
+
+	userRepoMock.On("FindUnconfirmed", mock.Anything, mock.Anything).Return([]response.UserResponse{*userResponseDummy}, nil).Once()
+	userRepoMock.On("FindById", userResponseDummy.ID).Return(userResponseDummy, nil).Once()
+	userRepoMock.On("Update", mock.MatchedBy(func(user model.User) bool {
+		return user.ConfirmationToken == userResponseDummy.ConfirmationToken
+	}), userResponseDummy.ID).Return(&userDummy, nil).Once()
+	userRepoMock.On("Update", mock.MatchedBy(func(user model.User) bool {
+		return !user.ConfirmationRemindedAt.IsZero()
+	}), userResponseDummy.ID).Return(&userDummy, nil).Once()
+	notificationServiceMock.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := authService.RemindUnverifiedUsers()
+
+	assert.Nil(t, err)
+	notificationServiceMock.AssertNumberOfCalls(t, "Notify", 1)
+	userRepoMock.AssertExpectations(t)
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
 	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
 	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/felixlambertv/go-cleanplate/pkg/utils"
 	"gorm.io/gorm"
@@ -17,32 +20,117 @@ type (
 		WithTrx(trxHandle *gorm.DB) IUserService
 		CreateUser(req request.CreateUserRequest) (*response.UserResponse, error)
 		UpdateUserCountry(req request.UpdateUserCountryRequest, userID uint) (*response.UserResponse, error)
+		UpdateUserPhone(req request.UpdateUserPhoneRequest, userID uint) (*response.UserResponse, error)
 		GetUser(id uint) (*response.UserResponse, error)
-		GetUsers(paginationReq model.Pagination) (*model.Pagination, error)
+		GetUsers(paginationReq model.Pagination) (*model.Page[response.UserResponse], error)
+		SetAvatar(userID uint, mediaID *uint) (*response.UserResponse, error)
 		DeleteUser(id uint) error
 	}
 
 	IAuthService interface {
+		WithTrx(trxHandle *gorm.DB) IAuthService
 		Login(req request.LoginRequest) (*response.UserResponse, *utils.TokenHeader, error)
 		Register(req request.RegisterRequest) (*response.UserResponse, *utils.TokenHeader, error)
 		ForgotPassword(req request.ForgotPasswordRequest) error
 		ResetPassword(req request.ResetPasswordRequest) error
-		SendVerificationEmail(id uint, token int) error
+		SendVerificationEmail(id uint, token int, locale string) error
 		VerifyToken(req request.VerifyTokenRequest) error
-		SendResetPasswordEmail(id uint, token string) error
+		SendResetPasswordEmail(id uint, token string, locale string) error
 		RefreshAuthToken(token string) (*response.UserResponse, *utils.TokenHeader, error)
+		PurgeExpiredResetTokens() error
+		RemindUnverifiedUsers() error
 	}
 
 	IMailService interface {
 		SendEmail(emailData request.SendEmailRequest) error
+		GetTemplates() []string
+		GetLocales() []string
+		PreviewTemplate(name string, locale string) (*response.MailPreviewResponse, error)
+		HandleSNSMessage(body []byte) error
+		GetEmailLogs(filter request.EmailLogFilter, p model.Pagination) (*model.Pagination, error)
+		GetSuppressions(p model.Pagination) (*model.Pagination, error)
+		DeleteSuppression(email string) error
+	}
+
+	INotificationService interface {
+		WithTrx(trxHandle *gorm.DB) INotificationService
+		Notify(user *response.UserResponse, req request.NotificationRequest) error
+		GetPreferences(userID uint) ([]response.NotificationPreferenceResponse, error)
+		UpdatePreferences(userID uint, req request.UpdateNotificationPreferencesRequest) ([]response.NotificationPreferenceResponse, error)
+		CheckUnsubscribe(token string) (consttype.NotificationType, consttype.NotificationChannel, error)
+		Unsubscribe(token string) error
+	}
+
+	IInboxService interface {
+		GetNotifications(userID uint, filter request.InAppNotificationFilter, p model.Pagination) (*model.Pagination, error)
+		GetNotificationsAfter(userID uint, afterID uint) ([]model.InAppNotification, error)
+		MarkRead(userID uint, id uint) error
+		MarkAllRead(userID uint) error
+		Subscribe(userID uint) (<-chan model.InAppNotification, func())
+	}
+
+	IPushService interface {
+		GetDevices(userID uint) ([]model.DeviceToken, error)
+		RegisterDevice(userID uint, req request.RegisterDeviceRequest) (*model.DeviceToken, error)
+		UnregisterDevice(userID uint, token string) error
+		SendPush(req request.SendPushRequest) error
+	}
+
+	ISmsService interface {
+		SendSms(req request.SendSmsRequest) error
+	}
+
+	IMailCatcherService interface {
+		GetMessages() ([]response.CapturedMailResponse, error)
+		GetMessage(id string) (*response.CapturedMailResponse, error)
+		DeleteMessages() error
 	}
 
 	IQueueService interface {
 		SendMessage(messageBody string, messageType consttype.QueueType) error
+		SendMessageWithDeduplication(messageBody string, messageType consttype.QueueType, deduplicationId string) error
+		SendDelayedMessage(messageBody string, messageType consttype.QueueType, deduplicationId string, deliverAt time.Time) error
 		ReceiveMessage() error
+		PurgeProcessedMessages() error
+	}
+
+	IOutboxService interface {
+		WithTrx(trxHandle *gorm.DB) IOutboxService
+		Enqueue(messageBody string, messageType consttype.QueueType) error
+		EnqueueAt(messageBody string, messageType consttype.QueueType, deliverAt time.Time) error
+		Relay() error
+	}
+
+	ISchedulerService interface {
+		GetJobs() ([]response.JobResponse, error)
+		GetJobRuns(name string, p model.Pagination) (*model.Pagination, error)
+		TriggerJob(name string) error
 	}
 
 	IMediaService interface {
-		UploadMedia(req request.MediaUploadRequest, ctx context.Context) (string, error)
+		UploadMedia(userID uint, req request.MediaUploadRequest, ctx context.Context) (*response.MediaResponse, error)
+		GetMediaList(userID uint, filter request.MediaFilter, p model.Pagination, ctx context.Context) (*model.Pagination, error)
+		GetMedia(userID uint, id uint, ctx context.Context) (*response.MediaResponse, error)
+		DownloadMedia(userID uint, id uint, ctx context.Context) (io.ReadCloser, *response.MediaDownload, error)
+		DeleteMedia(userID uint, id uint, ctx context.Context) error
+		CreateUpload(userID uint, req request.CreateMediaUploadRequest, ctx context.Context) (*response.MediaUploadResponse, error)
+		CompleteUpload(userID uint, id uint, ctx context.Context) (*response.MediaResponse, error)
+		CreateResumableUpload(userID uint, req request.CreateMediaUploadRequest) (*response.ResumableUploadResponse, error)
+		GetResumableUpload(userID uint, id uint) (*response.ResumableUploadResponse, error)
+		AppendUpload(userID uint, id uint, offset int64, chunk io.Reader, ctx context.Context) (*response.ResumableUploadResponse, error)
+		AttachMedia(userID uint, id uint) error
+		DetachMedia(id uint) error
+		GetOrphanReport(p model.Pagination) (*response.OrphanMediaReport, error)
+		SweepOrphans(dryRun bool, ctx context.Context) (*response.MediaSweepResponse, error)
+	}
+
+	IImageService interface {
+		ProcessMedia(req request.ProcessMediaRequest) error
+		ResizeUrl(userID uint, id uint, req request.ResizeMediaRequest) (*response.ResizeUrlResponse, error)
+		ResizeMedia(id uint, req request.SignedResizeMediaRequest, ctx context.Context) (io.ReadCloser, *storage.BlobInfo, error)
+	}
+
+	IScanService interface {
+		ScanMedia(req request.ScanMediaRequest) error
 	}
 )
@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+)
+
+var ErrAttachmentTooLarge = errors.New("email attachments are too large")
+
+// AttachmentStore loads attachment content by storage key. Get fails with
+// ErrAttachmentTooLarge when the content is larger than maxSize bytes.
+type AttachmentStore interface {
+	Get(key string, maxSize int) ([]byte, string, error)
+}
+
+// BlobAttachmentStore loads attachments from the blob store the app keeps
+// its media in.
+type BlobAttachmentStore struct {
+	store storage.BlobStore
+}
+
+func NewBlobAttachmentStore(store storage.BlobStore) *BlobAttachmentStore {
+	return &BlobAttachmentStore{store: store}
+}
+
+func (b *BlobAttachmentStore) Get(key string, maxSize int) ([]byte, string, error) {
+	body, info, err := b.store.Get(context.Background(), key)
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+
+	data, err := readLimited(body, maxSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, info.ContentType, nil
+}
+
+func readLimited(r io.Reader, maxSize int) ([]byte, error) {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	return data, nil
+}
@@ -0,0 +1,169 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	CapturedMessage struct {
+		ID     string    `json:"id"`
+		SentAt time.Time `json:"sentAt"`
+		Message
+	}
+
+	// CaptureTransport keeps sent messages instead of delivering them. Messages
+	// live in memory, or as one JSON file each when a directory is set so they
+	// survive restarts and can be shared between processes.
+	CaptureTransport struct {
+		mu       sync.RWMutex
+		dir      string
+		messages []CapturedMessage
+	}
+)
+
+func NewMemoryTransport() *CaptureTransport {
+	return &CaptureTransport{}
+}
+
+func NewFileTransport(dir string) (*CaptureTransport, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CaptureTransport{dir: dir}, nil
+}
+
+func (c *CaptureTransport) Send(message Message) (string, error) {
+	captured := CapturedMessage{
+		ID:      uuid.Must(uuid.NewRandom()).String(),
+		SentAt:  time.Now().UTC(),
+		Message: message,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dir == "" {
+		c.messages = append(c.messages, captured)
+		return captured.ID, nil
+	}
+
+	b, err := json.MarshalIndent(captured, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(c.dir, captured.ID+".json"), b, 0o644)
+	if err != nil {
+		return "", err
+	}
+
+	return captured.ID, nil
+}
+
+// Messages returns captured messages, newest first.
+func (c *CaptureTransport) Messages() ([]CapturedMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var messages []CapturedMessage
+	if c.dir == "" {
+		messages = append(messages, c.messages...)
+	} else {
+		entries, err := os.ReadDir(c.dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+
+			message, err := c.readFile(strings.TrimSuffix(entry.Name(), ".json"))
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, *message)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].SentAt.After(messages[j].SentAt)
+	})
+
+	return messages, nil
+}
+
+func (c *CaptureTransport) Find(id string) (*CapturedMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.dir != "" {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, errors.New("message not found")
+		}
+		return c.readFile(id)
+	}
+
+	for _, message := range c.messages {
+		if message.ID == id {
+			found := message
+			return &found, nil
+		}
+	}
+
+	return nil, errors.New("message not found")
+}
+
+// Reset removes every captured message.
+func (c *CaptureTransport) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = nil
+	if c.dir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *CaptureTransport) readFile(id string) (*CapturedMessage, error) {
+	b, err := os.ReadFile(filepath.Join(c.dir, id+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("message not found")
+		}
+		return nil, err
+	}
+
+	var message CapturedMessage
+	err = json.Unmarshal(b, &message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
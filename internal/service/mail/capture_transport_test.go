@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var capturedMessage = Message{
+	From:    "no-reply@test.com",
+	To:      []string{"test@test.com"},
+	Subject: "Subject",
+	HTML:    "<p>Hello</p>",
+	Text:    "Hello",
+}
+
+func TestCaptureTransport_MemoryShouldKeepSentMessages(t *testing.T) {
+	transport := NewMemoryTransport()
+
+	id, err := transport.Send(capturedMessage)
+	assert.Nil(t, err)
+
+	found, err := transport.Find(id)
+	assert.Nil(t, err)
+	assert.Equal(t, capturedMessage, found.Message)
+
+	assert.Nil(t, transport.Reset())
+	messages, err := transport.Messages()
+	assert.Nil(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestCaptureTransport_FileShouldPersistMessages(t *testing.T) {
+	dir := t.TempDir()
+	transport, err := NewFileTransport(dir)
+	assert.Nil(t, err)
+
+	id, err := transport.Send(capturedMessage)
+	assert.Nil(t, err)
+
+	reopened, err := NewFileTransport(dir)
+	assert.Nil(t, err)
+
+	messages, err := reopened.Messages()
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, id, messages[0].ID)
+	assert.Equal(t, capturedMessage, messages[0].Message)
+
+	_, err = reopened.Find("../../etc/passwd")
+	assert.Equal(t, "message not found", err.Error())
+}
@@ -0,0 +1,111 @@
+package mail
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"golang.org/x/net/html"
+)
+
+var textLinkPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// MailCatcherService exposes the messages kept by a CaptureTransport, so
+// links in development mail can be followed without a real mailbox.
+type MailCatcherService struct {
+	capture *CaptureTransport
+}
+
+func NewMailCatcherService(capture *CaptureTransport) *MailCatcherService {
+	return &MailCatcherService{capture: capture}
+}
+
+// GetMessages lists captured messages, newest first, without their bodies.
+func (mc *MailCatcherService) GetMessages() ([]response.CapturedMailResponse, error) {
+	messages, err := mc.capture.Messages()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.CapturedMailResponse, 0, len(messages))
+	for _, message := range messages {
+		mail := toCapturedMailResponse(message)
+		mail.HTML = ""
+		mail.Text = ""
+		res = append(res, mail)
+	}
+
+	return res, nil
+}
+
+func (mc *MailCatcherService) GetMessage(id string) (*response.CapturedMailResponse, error) {
+	message, err := mc.capture.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	mail := toCapturedMailResponse(*message)
+	return &mail, nil
+}
+
+func (mc *MailCatcherService) DeleteMessages() error {
+	return mc.capture.Reset()
+}
+
+func toCapturedMailResponse(message CapturedMessage) response.CapturedMailResponse {
+	return response.CapturedMailResponse{
+		ID:      message.ID,
+		SentAt:  message.SentAt,
+		From:    message.From,
+		To:      message.To,
+		Cc:      message.Cc,
+		Bcc:     message.Bcc,
+		ReplyTo: message.ReplyTo,
+		Subject: message.Subject,
+		HTML:    message.HTML,
+		Text:    message.Text,
+		Links:   ExtractLinks(message.Message),
+	}
+}
+
+// ExtractLinks returns the http(s) links of a message in the order they
+// appear, anchors of the HTML part first, without duplicates.
+func ExtractLinks(message Message) []string {
+	links := []string{}
+	seen := map[string]bool{}
+	add := func(link string) {
+		link = strings.TrimSpace(link)
+		if seen[link] || !(strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")) {
+			return
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(message.HTML))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		if token.Data != "a" {
+			continue
+		}
+		for _, attr := range token.Attr {
+			if attr.Key == "href" {
+				add(attr.Val)
+			}
+		}
+	}
+
+	for _, link := range textLinkPattern.FindAllString(message.Text, -1) {
+		add(strings.TrimRight(link, ".,;:!?)"))
+	}
+
+	return links
+}
@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractLinks_ShouldCollectHTMLAndTextLinksOnce(t *testing.T) {
+	links := ExtractLinks(Message{
+		HTML: `<p><a href="https://app.test/verify?token=1">Verify</a> <a href="mailto:help@test.com">Help</a>` +
+			`<a class="button" href="https://app.test/verify?token=1">Again</a></p>`,
+		Text: "Verify at https://app.test/verify?token=1 or reset at https://app.test/reset/abc.",
+	})
+
+	assert.Equal(t, []string{"https://app.test/verify?token=1", "https://app.test/reset/abc"}, links)
+}
+
+func TestMailCatcherService_ShouldExposeCapturedMessages(t *testing.T) {
+	transport := NewMemoryTransport()
+	catcher := NewMailCatcherService(transport)
+
+	id, err := transport.Send(Message{
+		From:    "no-reply@test.com",
+		To:      []string{"test@test.com"},
+		Subject: "Subject",
+		HTML:    `<a href="https://app.test/reset/abc">Reset</a>`,
+	})
+	assert.Nil(t, err)
+
+	messages, err := catcher.GetMessages()
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+	assert.Empty(t, messages[0].HTML)
+	assert.Equal(t, []string{"https://app.test/reset/abc"}, messages[0].Links)
+
+	message, err := catcher.GetMessage(id)
+	assert.Nil(t, err)
+	assert.Equal(t, `<a href="https://app.test/reset/abc">Reset</a>`, message.HTML)
+
+	assert.Nil(t, catcher.DeleteMessages())
+	_, err = catcher.GetMessage(id)
+	assert.Equal(t, "message not found", err.Error())
+}
@@ -1,72 +1,365 @@
 package mail
 
 import (
-	"bytes"
-	"crypto/tls"
+	"errors"
 	"fmt"
-	template "html/template"
-	"strconv"
+	netmail "net/mail"
+	"strings"
 
 	"github.com/felixlambertv/go-cleanplate/config"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
 	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/felixlambertv/go-cleanplate/pkg/logger"
-	"github.com/felixlambertv/go-cleanplate/pkg/utils"
-	"gopkg.in/gomail.v2"
 )
 
+// SES rejects raw messages over 10 MB, attachments grow by a third once base64
+// encoded.
+const maxAttachmentsSize = 7 << 20
+
+// reservedHeaders are set from the request fields and cannot be overridden
+// through SendEmailRequest.Headers.
+var reservedHeaders = map[string]bool{
+	"from": true, "to": true, "cc": true, "bcc": true, "reply-to": true, "subject": true,
+	"content-type": true, "content-transfer-encoding": true, "mime-version": true,
+	"list-unsubscribe": true, "list-unsubscribe-post": true,
+}
+
 type MailService struct {
-	l        logger.Interface
-	cfg      *config.Config
-	userRepo repository.IUserRepo
+	l               logger.Interface
+	cfg             *config.Config
+	userRepo        repository.IUserRepo
+	emailLogRepo    repository.IEmailLogRepo
+	suppressionRepo repository.IEmailSuppressionRepo
+	templates       *TemplateRegistry
+	transport       Transport
+	attachments     AttachmentStore
+	sns             *SNSWebhook
 }
 
-func NewMailService(l logger.Interface, cfg *config.Config, userRepo repository.IUserRepo) *MailService {
-	return &MailService{l: l, cfg: cfg, userRepo: userRepo}
+func NewMailService(l logger.Interface, cfg *config.Config, userRepo repository.IUserRepo, emailLogRepo repository.IEmailLogRepo, suppressionRepo repository.IEmailSuppressionRepo, templates *TemplateRegistry, transport Transport, attachments AttachmentStore, sns *SNSWebhook) *MailService {
+	return &MailService{
+		l:               l,
+		cfg:             cfg,
+		userRepo:        userRepo,
+		emailLogRepo:    emailLogRepo,
+		suppressionRepo: suppressionRepo,
+		templates:       templates,
+		transport:       transport,
+		attachments:     attachments,
+		sns:             sns,
+	}
 }
 
+// SendEmail renders the template in the locale of the request. Requests
+// without a subject get the translated subject of the template. Suppressed
+// addresses are skipped and every recipient is recorded in the email log.
 func (ms *MailService) SendEmail(emailData request.SendEmailRequest) error {
-	var body bytes.Buffer
+	emailData.Locale = ms.templates.MatchLocale(emailData.Locale)
+	if emailData.Subject == "" {
+		emailData.Subject = ms.templates.Subject(emailData.Template, emailData.Locale)
+	}
 
-	templates, err := utils.ParseTemplateDir("templates", emailData.Template)
+	suppressed, err := ms.removeSuppressed(&emailData)
 	if err != nil {
-		fmt.Println(err)
-		// ms.l.Error("could not parse template: %s", err)
 		return err
 	}
 
-	templates = templates.Lookup(emailData.Template)
-
-	data := map[string]any{
-		"Name":    emailData.Name,
-		"Token":   strconv.FormatUint(uint64(emailData.Token), 10),
-		"Email":   emailData.Email,
-		"LinkUrl": template.URL(emailData.LinkUrl),
+	if len(emailData.To) == 0 {
+		// Failing would only make the queue retry an email that is never sent.
+		fmt.Println("skip email to suppressed addresses:", strings.Join(suppressed, ", "))
+		ms.storeLogs(emailData, nil, suppressed, "", nil)
+		return nil
 	}
 
-	err = templates.Execute(&body, &data)
+	message, err := ms.buildMessage(emailData)
 	if err != nil {
 		fmt.Println(err)
-		// ms.l.Error("could not parse template file: %s", err)
 		return err
 	}
 
-	m := gomail.NewMessage()
+	messageId, err := ms.transport.Send(*message)
+	ms.storeLogs(emailData, recipients(*message), suppressed, messageId, err)
+	if err != nil {
+		fmt.Println("error send email :", err)
+		return err
+	}
+
+	fmt.Println("Email Sent to address: "+strings.Join(message.To, ", ")+" with message ID : ", messageId)
+	return nil
+}
+
+func (ms *MailService) GetEmailLogs(filter request.EmailLogFilter, p model.Pagination) (*model.Pagination, error) {
+	return ms.emailLogRepo.FindAll(filter, p)
+}
+
+func (ms *MailService) GetSuppressions(p model.Pagination) (*model.Pagination, error) {
+	return ms.suppressionRepo.FindAll(p)
+}
+
+func (ms *MailService) DeleteSuppression(email string) error {
+	return ms.suppressionRepo.Delete(email)
+}
+
+// removeSuppressed drops suppressed addresses from the recipients of emailData
+// and returns them.
+func (ms *MailService) removeSuppressed(emailData *request.SendEmailRequest) ([]string, error) {
+	var emails []string
+	for _, list := range [][]request.EmailAddress{emailData.To, emailData.Cc, emailData.Bcc} {
+		for _, address := range list {
+			emails = append(emails, address.Email)
+		}
+	}
+
+	found, err := ms.suppressionRepo.FindSuppressed(emails)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
 
-	m.SetHeaders(map[string][]string{
-		"From":    {ms.cfg.Mail.From},
-		"To":      {emailData.Email},
-		"Subject": {emailData.Subject},
-	})
-	m.SetBody("text/html", body.String())
+	isSuppressed := map[string]bool{}
+	for _, email := range found {
+		isSuppressed[strings.ToLower(email)] = true
+	}
 
-	d := gomail.NewDialer(ms.cfg.Mail.Host, ms.cfg.Mail.Port, ms.cfg.Mail.User, ms.cfg.Mail.Password)
-	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	var suppressed []string
+	keep := func(list []request.EmailAddress) []request.EmailAddress {
+		var kept []request.EmailAddress
+		for _, address := range list {
+			if isSuppressed[strings.ToLower(address.Email)] {
+				suppressed = append(suppressed, address.Email)
+				continue
+			}
+			kept = append(kept, address)
+		}
+		return kept
+	}
+	emailData.To = keep(emailData.To)
+	emailData.Cc = keep(emailData.Cc)
+	emailData.Bcc = keep(emailData.Bcc)
 
-	err = d.DialAndSend(m)
+	return suppressed, nil
+}
+
+// storeLogs records the outcome for every recipient. Logging failures are only
+// printed, the email is already sent and must not be sent again.
+func (ms *MailService) storeLogs(emailData request.SendEmailRequest, sentTo []string, suppressed []string, messageId string, sendErr error) {
+	var userID *uint
+	if emailData.UserID != 0 {
+		userID = &emailData.UserID
+	}
+
+	newLog := func(recipient string, status consttype.EmailStatus) model.EmailLog {
+		return model.EmailLog{
+			UserID:    userID,
+			Template:  emailData.Template,
+			Subject:   emailData.Subject,
+			Recipient: plainAddress(recipient),
+			Status:    status,
+		}
+	}
+
+	logs := make([]model.EmailLog, 0, len(sentTo)+len(suppressed))
+	for _, recipient := range sentTo {
+		log := newLog(recipient, consttype.EMAIL_SENT)
+		log.ProviderMessageId = messageId
+		if sendErr != nil {
+			log.Status = consttype.EMAIL_FAILED
+			log.Error = sendErr.Error()
+		}
+		logs = append(logs, log)
+	}
+	for _, recipient := range suppressed {
+		logs = append(logs, newLog(recipient, consttype.EMAIL_SUPPRESSED))
+	}
+
+	err := ms.emailLogRepo.Store(logs)
 	if err != nil {
-		return err
+		fmt.Println("error store email logs:", err)
 	}
+}
 
-	return nil
+func (ms *MailService) GetTemplates() []string {
+	return ms.templates.Names()
+}
+
+func (ms *MailService) GetLocales() []string {
+	return ms.templates.Locales()
+}
+
+// PreviewTemplate renders a template with sample data, nothing is sent.
+func (ms *MailService) PreviewTemplate(name string, locale string) (*response.MailPreviewResponse, error) {
+	sample := SampleEmailRequest(name)
+	sample.Locale = ms.templates.MatchLocale(locale)
+	sample.Subject = ms.templates.Subject(name, sample.Locale)
+
+	data, err := templateData(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	html, text, err := ms.templates.Render(name, sample.Locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MailPreviewResponse{
+		Template: name,
+		Locale:   sample.Locale,
+		Subject:  sample.Subject,
+		HTML:     html,
+		Text:     text,
+	}, nil
+}
+
+func (ms *MailService) buildMessage(emailData request.SendEmailRequest) (*Message, error) {
+	if len(emailData.To) == 0 {
+		return nil, errors.New("email has no recipient")
+	}
+
+	data, err := templateData(emailData)
+	if err != nil {
+		return nil, err
+	}
+
+	html, text, err := ms.templates.Render(emailData.Template, emailData.Locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := messageHeaders(emailData.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if emailData.UnsubscribeUrl != "" {
+		// RFC 8058 one-click unsubscribe, mail clients POST to the link.
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers["List-Unsubscribe"] = "<" + emailData.UnsubscribeUrl + ">"
+		headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	}
+
+	attachments, err := ms.loadAttachments(emailData.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &Message{
+		From:        ms.cfg.Mail.From,
+		To:          formatAddresses(emailData.To),
+		Cc:          formatAddresses(emailData.Cc),
+		Bcc:         formatAddresses(emailData.Bcc),
+		Subject:     emailData.Subject,
+		HTML:        html,
+		Text:        text,
+		Headers:     headers,
+		Attachments: attachments,
+	}
+	if emailData.ReplyTo != nil {
+		message.ReplyTo = formatAddresses([]request.EmailAddress{*emailData.ReplyTo})[0]
+	}
+
+	// Local environments never mail real users, everything goes to the test
+	// address instead.
+	if ms.cfg.App.Env == "local" && ms.cfg.Mail.Test != "" {
+		message.To = []string{ms.cfg.Mail.Test}
+		message.Cc = nil
+		message.Bcc = nil
+	}
+
+	return message, nil
+}
+
+func (ms *MailService) loadAttachments(refs []request.EmailAttachment) ([]Attachment, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	if ms.attachments == nil {
+		return nil, errors.New("email attachments are not configured")
+	}
+
+	var size int
+	attachments := make([]Attachment, 0, len(refs))
+	for _, ref := range refs {
+		data, contentType, err := ms.attachments.Get(ref.StorageKey, maxAttachmentsSize-size)
+		if err != nil {
+			return nil, fmt.Errorf("load attachment %s: %w", ref.Filename, err)
+		}
+
+		size += len(data)
+		if ref.ContentType != "" {
+			contentType = ref.ContentType
+		}
+
+		attachments = append(attachments, Attachment{
+			Filename:    ref.Filename,
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+
+	return attachments, nil
+}
+
+// SampleEmailRequest is the data templates are checked and previewed with.
+func SampleEmailRequest(name string) request.SendEmailRequest {
+	return request.SendEmailRequest{
+		Template:       name,
+		Subject:        "Preview of " + name,
+		To:             []request.EmailAddress{{Name: "Jane Doe", Email: "jane.doe@example.com"}},
+		Data:           []byte(`{"Name":"Jane Doe","Token":123456,"LinkUrl":"https://example.com/app/reset-password/sample-token","Title":"Sample title","Body":"Sample body of the notification."}`),
+		UnsubscribeUrl: "https://example.com/api/v1/notifications/unsubscribe?token=sample-token",
+	}
+}
+
+// templateData is the request data plus the Subject, Locale, UnsubscribeUrl
+// and the Email of the first recipient, which every template can use.
+func templateData(emailData request.SendEmailRequest) (map[string]any, error) {
+	data, err := emailData.TemplateData()
+	if err != nil {
+		return nil, err
+	}
+
+	data["Subject"] = emailData.Subject
+	data["Locale"] = emailData.Locale
+	data["UnsubscribeUrl"] = emailData.UnsubscribeUrl
+	if len(emailData.To) > 0 {
+		data["Email"] = emailData.To[0].Email
+	}
+
+	return data, nil
+}
+
+func messageHeaders(headers map[string]string) (map[string]string, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if reservedHeaders[strings.ToLower(key)] {
+			return nil, fmt.Errorf("email header %s cannot be set", key)
+		}
+		if key == "" || strings.ContainsAny(key, "\r\n: ") || strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("email header %q is not valid", key)
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func formatAddresses(addresses []request.EmailAddress) []string {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		result = append(result, (&netmail.Address{Name: address.Name, Address: address.Email}).String())
+	}
+
+	return result
 }
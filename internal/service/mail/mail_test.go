@@ -0,0 +1,333 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var cfg = &config.Config{
+	App: config.App{
+		Env: "local",
+	},
+	Mail: config.Mail{
+		From: "no-reply@test.com",
+		Test: "catch-all@test.com",
+	},
+}
+
+var emailInputRequest = request.SendEmailRequest{
+	Template: "verify_email.html",
+	Subject:  "Subject",
+	To:       []request.EmailAddress{{Name: "Name", Email: "test@test.com"}},
+	Data:     []byte(`{"Name":"Name","Token":1}`),
+}
+
+var userRepoMock = new(mocks.IUserRepo)
+var emailLogRepoMock = new(mocks.IEmailLogRepo)
+var suppressionRepoMock = new(mocks.IEmailSuppressionRepo)
+
+// newMailService resets the repository mocks to a store without suppressions.
+func newMailService(cfg *config.Config, transport Transport, attachments AttachmentStore) *MailService {
+	emailLogRepoMock.ExpectedCalls = nil
+	emailLogRepoMock.Calls = nil
+	suppressionRepoMock.ExpectedCalls = nil
+	suppressionRepoMock.Calls = nil
+	emailLogRepoMock.On("Store", mock.Anything).Return(nil).Maybe()
+	suppressionRepoMock.On("FindSuppressed", mock.Anything).Return(nil, nil).Maybe()
+
+	return NewMailService(nil, cfg, userRepoMock, emailLogRepoMock, suppressionRepoMock, templates, transport, attachments, NewSNSWebhook(nil, "", nil))
+}
+
+type failingTransport struct {
+	err error
+}
+
+func (f *failingTransport) Send(message Message) (string, error) {
+	return "", f.err
+}
+
+func TestMain(m *testing.M) {
+	m.Run()
+}
+
+var templates, _ = NewTemplateRegistry(logger.NewLogger("error"), "../../../templates", false)
+
+func TestMailService_SendEmail_ShouldRenderAndDeliverThroughTransport(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(cfg, transport, nil)
+
+	err := mailService.SendEmail(emailInputRequest)
+	assert.Equal(t, nil, err)
+
+	data, err := templateData(emailInputRequest)
+	assert.Nil(t, err)
+	expectedHTML, expectedText, err := templates.Render(emailInputRequest.Template, DefaultLocale, data)
+	assert.Nil(t, err)
+
+	messages, err := transport.Messages()
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, expectedHTML, messages[0].HTML)
+	assert.Equal(t, expectedText, messages[0].Text)
+	assert.Equal(t, []string{cfg.Mail.Test}, messages[0].To)
+	assert.Equal(t, emailInputRequest.Subject, messages[0].Subject)
+	assert.Equal(t, cfg.Mail.From, messages[0].From)
+}
+
+func TestMailService_SendEmail_ShouldReturnErrorWhenTemplateNotFound(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(cfg, transport, nil)
+
+	req := emailInputRequest
+	req.Template = "missing.html"
+	err := mailService.SendEmail(req)
+
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+	messages, _ := transport.Messages()
+	assert.Empty(t, messages)
+}
+
+func TestMailService_PreviewTemplate_ShouldRenderSampleData(t *testing.T) {
+	mailService := newMailService(cfg, NewMemoryTransport(), nil)
+
+	preview, err := mailService.PreviewTemplate("verify_email.html", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Verification Code", preview.Subject)
+	assert.Contains(t, preview.HTML, "<h1>123456</h1>")
+	assert.Contains(t, preview.Text, "123456")
+	assert.Equal(t, []string{"product_update.html", "reset_password.html", "verify_email.html"}, mailService.GetTemplates())
+}
+
+func TestMailService_SendEmail_ShouldUseLocaleTemplateAndSubject(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(cfg, transport, nil)
+
+	req := emailInputRequest
+	req.Subject = ""
+	req.Locale = "id-ID"
+	err := mailService.SendEmail(req)
+	assert.Nil(t, err)
+
+	messages, _ := transport.Messages()
+	assert.Equal(t, "Kode Verifikasi", messages[0].Subject)
+	assert.Contains(t, messages[0].HTML, "Masukkan kode berikut")
+	assert.Contains(t, messages[0].HTML, "<title>Kode Verifikasi</title>")
+}
+
+func TestMailService_SendEmail_ShouldFallBackToDefaultLocale(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(cfg, transport, nil)
+
+	req := emailInputRequest
+	req.Subject = ""
+	req.Locale = "fr"
+	err := mailService.SendEmail(req)
+	assert.Nil(t, err)
+
+	messages, _ := transport.Messages()
+	assert.Equal(t, "Verification Code", messages[0].Subject)
+	assert.Contains(t, messages[0].HTML, "Enter the following code")
+}
+
+func TestNewTransport_ShouldSelectDriverFromConfig(t *testing.T) {
+	transport, err := NewTransport(&config.Config{Mail: config.Mail{Driver: "memory"}}, nil)
+	assert.Nil(t, err)
+	assert.IsType(t, &CaptureTransport{}, transport)
+
+	transport, err = NewTransport(&config.Config{Mail: config.Mail{Driver: "ses"}}, new(mocks.SESAPI))
+	assert.Nil(t, err)
+	assert.IsType(t, &SESTransport{}, transport)
+
+	_, err = NewTransport(&config.Config{Mail: config.Mail{Driver: "file"}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewTransport(&config.Config{Mail: config.Mail{Driver: "pigeon"}}, nil)
+	assert.Equal(t, "unknown mail driver: pigeon", err.Error())
+}
+
+type fakeAttachmentStore map[string][]byte
+
+func (f fakeAttachmentStore) Get(key string, maxSize int) ([]byte, string, error) {
+	data, ok := f[key]
+	if !ok {
+		return nil, "", errors.New("no such key")
+	}
+	if len(data) > maxSize {
+		return nil, "", ErrAttachmentTooLarge
+	}
+	return data, "application/octet-stream", nil
+}
+
+var productionCfg = &config.Config{
+	Mail: config.Mail{
+		From: "no-reply@test.com",
+	},
+}
+
+func TestMailService_SendEmail_ShouldDeliverToEveryRecipient(t *testing.T) {
+	transport := NewMemoryTransport()
+	store := fakeAttachmentStore{"invoices/1.pdf": []byte("%PDF-1.4")}
+	mailService := newMailService(productionCfg, transport, store)
+
+	req := emailInputRequest
+	req.To = []request.EmailAddress{{Name: "Jane Doe", Email: "jane@test.com"}, {Email: "john@test.com"}}
+	req.Cc = []request.EmailAddress{{Email: "cc@test.com"}}
+	req.Bcc = []request.EmailAddress{{Email: "audit@test.com"}}
+	req.ReplyTo = &request.EmailAddress{Name: "Support", Email: "support@test.com"}
+	req.Headers = map[string]string{"X-Campaign": "welcome"}
+	req.Attachments = []request.EmailAttachment{{Filename: "invoice.pdf", ContentType: "application/pdf", StorageKey: "invoices/1.pdf"}}
+
+	err := mailService.SendEmail(req)
+	assert.Nil(t, err)
+
+	messages, _ := transport.Messages()
+	message := messages[0]
+	assert.Equal(t, []string{`"Jane Doe" <jane@test.com>`, "<john@test.com>"}, message.To)
+	assert.Equal(t, []string{"<cc@test.com>"}, message.Cc)
+	assert.Equal(t, []string{"<audit@test.com>"}, message.Bcc)
+	assert.Equal(t, `"Support" <support@test.com>`, message.ReplyTo)
+	assert.Equal(t, map[string]string{"X-Campaign": "welcome"}, message.Headers)
+	assert.Equal(t, []Attachment{{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}}, message.Attachments)
+	assert.Contains(t, message.HTML, "jane@test.com")
+}
+
+func TestMailService_SendEmail_ShouldRejectReservedAndInvalidHeaders(t *testing.T) {
+	mailService := newMailService(productionCfg, NewMemoryTransport(), nil)
+
+	req := emailInputRequest
+	req.Headers = map[string]string{"Bcc": "attacker@test.com"}
+	assert.Equal(t, "email header Bcc cannot be set", mailService.SendEmail(req).Error())
+
+	req.Headers = map[string]string{"X-Note": "hi\r\nBcc: attacker@test.com"}
+	assert.Equal(t, `email header "X-Note" is not valid`, mailService.SendEmail(req).Error())
+}
+
+func TestMailService_SendEmail_ShouldFailWhenAttachmentsCannotBeLoaded(t *testing.T) {
+	req := emailInputRequest
+	req.Attachments = []request.EmailAttachment{{Filename: "big.bin", StorageKey: "big"}}
+
+	mailService := newMailService(productionCfg, NewMemoryTransport(), nil)
+	assert.Equal(t, "email attachments are not configured", mailService.SendEmail(req).Error())
+
+	store := fakeAttachmentStore{"big": make([]byte, maxAttachmentsSize+1)}
+	mailService = newMailService(productionCfg, NewMemoryTransport(), store)
+	assert.ErrorIs(t, mailService.SendEmail(req), ErrAttachmentTooLarge)
+}
+
+func TestSendEmailRequest_ShouldSurviveQueueRoundTrip(t *testing.T) {
+	req, err := request.NewSendEmailRequest(request.ResetPasswordData{Name: "Jane", LinkUrl: "https://app.test/reset"},
+		request.EmailAddress{Name: "Jane", Email: "jane@test.com"})
+	assert.Nil(t, err)
+	req.Cc = []request.EmailAddress{{Email: "cc@test.com"}}
+	req.Attachments = []request.EmailAttachment{{Filename: "a.txt", StorageKey: "files/a.txt"}}
+
+	var decoded request.SendEmailRequest
+	assert.Nil(t, json.Unmarshal([]byte(req.ToString()), &decoded))
+
+	assert.Equal(t, req, decoded)
+	assert.Equal(t, "reset_password.html", decoded.Template)
+}
+
+func TestMailService_SendEmail_ShouldSkipSuppressedRecipients(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(productionCfg, transport, nil)
+	suppressionRepoMock.ExpectedCalls = nil
+	emailLogRepoMock.ExpectedCalls = nil
+
+	req := emailInputRequest
+	req.UserID = 7
+	req.To = []request.EmailAddress{{Email: "bounced@test.com"}, {Email: "ok@test.com"}}
+	suppressionRepoMock.On("FindSuppressed", []string{"bounced@test.com", "ok@test.com"}).Return([]string{"Bounced@test.com"}, nil).Once()
+	emailLogRepoMock.On("Store", mock.MatchedBy(func(logs []model.EmailLog) bool {
+		return len(logs) == 2 &&
+			logs[0].Recipient == "ok@test.com" && logs[0].Status == consttype.EMAIL_SENT && *logs[0].UserID == 7 &&
+			logs[1].Recipient == "bounced@test.com" && logs[1].Status == consttype.EMAIL_SUPPRESSED
+	})).Return(nil).Once()
+
+	err := mailService.SendEmail(req)
+
+	assert.Nil(t, err)
+	messages, _ := transport.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, []string{"<ok@test.com>"}, messages[0].To)
+	emailLogRepoMock.AssertExpectations(t)
+}
+
+func TestMailService_SendEmail_ShouldNotSendWhenAllRecipientsSuppressed(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(productionCfg, transport, nil)
+	suppressionRepoMock.ExpectedCalls = nil
+	emailLogRepoMock.ExpectedCalls = nil
+
+	suppressionRepoMock.On("FindSuppressed", []string{"test@test.com"}).Return([]string{"test@test.com"}, nil).Once()
+	emailLogRepoMock.On("Store", mock.MatchedBy(func(logs []model.EmailLog) bool {
+		return len(logs) == 1 && logs[0].Status == consttype.EMAIL_SUPPRESSED
+	})).Return(nil).Once()
+
+	err := mailService.SendEmail(emailInputRequest)
+
+	assert.Nil(t, err)
+	messages, _ := transport.Messages()
+	assert.Empty(t, messages)
+	emailLogRepoMock.AssertExpectations(t)
+}
+
+func TestMailService_SendEmail_ShouldLogFailedDelivery(t *testing.T) {
+	transport := &failingTransport{err: errors.New("smtp down")}
+	mailService := newMailService(productionCfg, transport, nil)
+	emailLogRepoMock.ExpectedCalls = nil
+
+	emailLogRepoMock.On("Store", mock.MatchedBy(func(logs []model.EmailLog) bool {
+		return len(logs) == 1 && logs[0].Status == consttype.EMAIL_FAILED && logs[0].Error == "smtp down"
+	})).Return(nil).Once()
+
+	err := mailService.SendEmail(emailInputRequest)
+
+	assert.EqualError(t, err, "smtp down")
+	emailLogRepoMock.AssertExpectations(t)
+}
+
+func TestMailService_SendEmail_ShouldAddUnsubscribeLink(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailService := newMailService(productionCfg, transport, nil)
+
+	req, err := request.NewSendEmailRequest(request.ProductUpdateData{Name: "Name", Title: "New feature", Body: "Try it out."}, request.EmailAddress{Email: "test@test.com"})
+	assert.Nil(t, err)
+	req.UnsubscribeUrl = "https://example.com/api/v1/notifications/unsubscribe?token=abc"
+	err = mailService.SendEmail(req)
+	assert.Nil(t, err)
+
+	messages, _ := transport.Messages()
+	assert.Equal(t, "<https://example.com/api/v1/notifications/unsubscribe?token=abc>", messages[0].Headers["List-Unsubscribe"])
+	assert.Equal(t, "List-Unsubscribe=One-Click", messages[0].Headers["List-Unsubscribe-Post"])
+	assert.Contains(t, messages[0].HTML, `href="https://example.com/api/v1/notifications/unsubscribe?token=abc"`)
+	assert.Contains(t, messages[0].Text, "Unsubscribe from product updates: https://example.com/api/v1/notifications/unsubscribe?token=abc")
+}
+
+func TestBlobAttachmentStore_GetShouldLimitSize(t *testing.T) {
+	blobs := storage.NewMemoryStore(storage.NewURLSigner("https://api.test.com/api/v1/storage", "secret"))
+	_, _ = blobs.Put(context.Background(), "invoices/1.pdf", strings.NewReader("%PDF-1.4"), storage.PutOptions{ContentType: "application/pdf"})
+	store := NewBlobAttachmentStore(blobs)
+
+	data, contentType, err := store.Get("invoices/1.pdf", 1024)
+	assert.Nil(t, err)
+	assert.Equal(t, "%PDF-1.4", string(data))
+	assert.Equal(t, "application/pdf", contentType)
+
+	_, _, err = store.Get("invoices/1.pdf", 4)
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+}
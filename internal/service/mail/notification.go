@@ -0,0 +1,140 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	netmail "net/mail"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+type (
+	// sesNotification covers SES feedback notifications and the equivalent
+	// event publishing records, which name the type eventType instead.
+	sesNotification struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Mail             struct {
+			MessageId string `json:"messageId"`
+		} `json:"mail"`
+		Bounce *struct {
+			BounceType        string `json:"bounceType"`
+			BounceSubType     string `json:"bounceSubType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint *struct {
+			ComplaintFeedbackType string `json:"complaintFeedbackType"`
+			ComplainedRecipients  []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+		Delivery *struct {
+			Recipients []string `json:"recipients"`
+		} `json:"delivery"`
+	}
+)
+
+// HandleSNSMessage ingests a message posted by SNS: subscriptions are
+// confirmed and SES bounce, complaint and delivery notifications update the
+// email log. Hard bounces and complaints suppress the address.
+func (ms *MailService) HandleSNSMessage(body []byte) error {
+	message, err := ParseSNSMessage(body)
+	if err != nil {
+		return err
+	}
+
+	err = ms.sns.Verify(message)
+	if err != nil {
+		return err
+	}
+
+	switch message.Type {
+	case "SubscriptionConfirmation":
+		return ms.sns.ConfirmSubscription(message)
+	case "Notification":
+		return ms.handleSESNotification([]byte(message.Message))
+	default:
+		return nil
+	}
+}
+
+func (ms *MailService) handleSESNotification(body []byte) error {
+	var notification sesNotification
+	err := json.Unmarshal(body, &notification)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSNSMessage, err)
+	}
+
+	notificationType := notification.NotificationType
+	if notificationType == "" {
+		notificationType = notification.EventType
+	}
+	messageId := notification.Mail.MessageId
+
+	switch {
+	case notificationType == "Bounce" && notification.Bounce != nil:
+		permanent := notification.Bounce.BounceType == "Permanent"
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			detail := strings.TrimSpace(notification.Bounce.BounceType + " " + notification.Bounce.BounceSubType + ": " + recipient.DiagnosticCode)
+			err = ms.trackRecipient(messageId, recipient.EmailAddress, consttype.EMAIL_BOUNCED, detail)
+			if err != nil {
+				return err
+			}
+			if permanent {
+				err = ms.suppress(recipient.EmailAddress, consttype.SUPPRESSION_BOUNCE, detail)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case notificationType == "Complaint" && notification.Complaint != nil:
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			detail := notification.Complaint.ComplaintFeedbackType
+			err = ms.trackRecipient(messageId, recipient.EmailAddress, consttype.EMAIL_COMPLAINED, detail)
+			if err != nil {
+				return err
+			}
+			err = ms.suppress(recipient.EmailAddress, consttype.SUPPRESSION_COMPLAINT, detail)
+			if err != nil {
+				return err
+			}
+		}
+	case notificationType == "Delivery" && notification.Delivery != nil:
+		for _, recipient := range notification.Delivery.Recipients {
+			err = ms.trackRecipient(messageId, recipient, consttype.EMAIL_DELIVERED, "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ms *MailService) trackRecipient(messageId string, recipient string, status consttype.EmailStatus, detail string) error {
+	_, err := ms.emailLogRepo.UpdateStatus(messageId, plainAddress(recipient), status, detail)
+	return err
+}
+
+func (ms *MailService) suppress(email string, reason consttype.SuppressionReason, detail string) error {
+	_, err := ms.suppressionRepo.Store(&model.EmailSuppression{
+		Email:  plainAddress(email),
+		Reason: reason,
+		Detail: detail,
+	})
+	return err
+}
+
+// plainAddress strips the display name of an RFC 5322 address.
+func plainAddress(address string) string {
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil {
+		return strings.TrimSpace(address)
+	}
+	return parsed.Address
+}
@@ -0,0 +1,149 @@
+package mail
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testTopicArn    = "arn:aws:sns:us-east-1:123456789012:ses-feedback"
+	testSigningCert = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-test.pem"
+)
+
+var snsKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+// newSignedSNSService returns a MailService trusting snsKey for the test topic.
+func newSignedSNSService(t *testing.T) *MailService {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &snsKey.PublicKey, snsKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	mailService := newMailService(productionCfg, NewMemoryTransport(), nil)
+	mailService.sns = NewSNSWebhook(nil, testTopicArn, func(certURL string) (*x509.Certificate, error) {
+		if certURL != testSigningCert {
+			return nil, errors.New("unknown certificate")
+		}
+		return cert, nil
+	})
+	return mailService
+}
+
+// snsNotification wraps the SES fixture in a signed SNS envelope.
+func snsNotification(t *testing.T, fixture string, signatureVersion string) []byte {
+	payload, err := os.ReadFile("testdata/" + fixture)
+	assert.Nil(t, err)
+
+	message := &SNSMessage{
+		Type:             "Notification",
+		MessageId:        "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+		TopicArn:         testTopicArn,
+		Message:          string(payload),
+		Timestamp:        "2023-03-01T12:00:00.000Z",
+		SignatureVersion: signatureVersion,
+		SigningCertURL:   testSigningCert,
+	}
+	hash := crypto.SHA1
+	if signatureVersion == "2" {
+		hash = crypto.SHA256
+	}
+	signature, err := rsa.SignPKCS1v15(rand.Reader, snsKey, hash, digest(hash, stringToSign(message)))
+	assert.Nil(t, err)
+	message.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	body, err := json.Marshal(message)
+	assert.Nil(t, err)
+	return body
+}
+
+func TestMailService_HandleSNSMessage_ShouldSuppressHardBounce(t *testing.T) {
+	mailService := newSignedSNSService(t)
+	detail := "Permanent General: smtp; 550 5.1.1 user unknown"
+	emailLogRepoMock.On("UpdateStatus", "0100018697a5b5c1-msg", "bounce@example.com", consttype.EMAIL_BOUNCED, detail).Return(int64(1), nil).Once()
+	suppressionRepoMock.On("Store", mock.MatchedBy(func(s *model.EmailSuppression) bool {
+		return s.Email == "bounce@example.com" && s.Reason == consttype.SUPPRESSION_BOUNCE
+	})).Return(&model.EmailSuppression{}, nil).Once()
+
+	err := mailService.HandleSNSMessage(snsNotification(t, "ses_bounce.json", "1"))
+
+	assert.Nil(t, err)
+	emailLogRepoMock.AssertExpectations(t)
+	suppressionRepoMock.AssertExpectations(t)
+}
+
+func TestMailService_HandleSNSMessage_ShouldSuppressComplaint(t *testing.T) {
+	mailService := newSignedSNSService(t)
+	emailLogRepoMock.On("UpdateStatus", "0100018697a5b5c1-msg", "complaint@example.com", consttype.EMAIL_COMPLAINED, "abuse").Return(int64(1), nil).Once()
+	suppressionRepoMock.On("Store", mock.MatchedBy(func(s *model.EmailSuppression) bool {
+		return s.Email == "complaint@example.com" && s.Reason == consttype.SUPPRESSION_COMPLAINT
+	})).Return(&model.EmailSuppression{}, nil).Once()
+
+	err := mailService.HandleSNSMessage(snsNotification(t, "ses_complaint.json", "2"))
+
+	assert.Nil(t, err)
+	emailLogRepoMock.AssertExpectations(t)
+	suppressionRepoMock.AssertExpectations(t)
+}
+
+func TestMailService_HandleSNSMessage_ShouldMarkDelivered(t *testing.T) {
+	mailService := newSignedSNSService(t)
+	emailLogRepoMock.On("UpdateStatus", "0100018697a5b5c1-msg", "delivered@example.com", consttype.EMAIL_DELIVERED, "").Return(int64(1), nil).Once()
+
+	err := mailService.HandleSNSMessage(snsNotification(t, "ses_delivery.json", "2"))
+
+	assert.Nil(t, err)
+	emailLogRepoMock.AssertExpectations(t)
+	suppressionRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestMailService_HandleSNSMessage_ShouldRejectTamperedMessage(t *testing.T) {
+	mailService := newSignedSNSService(t)
+
+	var message SNSMessage
+	_ = json.Unmarshal(snsNotification(t, "ses_delivery.json", "2"), &message)
+	message.Message = `{"notificationType":"Complaint"}`
+	body, _ := json.Marshal(message)
+
+	err := mailService.HandleSNSMessage(body)
+
+	assert.ErrorIs(t, err, ErrInvalidSNSMessage)
+	emailLogRepoMock.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMailService_HandleSNSMessage_ShouldRejectOtherTopic(t *testing.T) {
+	mailService := newSignedSNSService(t)
+	mailService.sns.topicArn = "arn:aws:sns:us-east-1:123456789012:other"
+
+	err := mailService.HandleSNSMessage(snsNotification(t, "ses_bounce.json", "1"))
+
+	assert.ErrorIs(t, err, ErrInvalidSNSMessage)
+}
+
+func TestSNSWebhook_ConfirmSubscription_ShouldRejectUntrustedURL(t *testing.T) {
+	webhook := NewSNSWebhook(nil, "", nil)
+
+	err := webhook.ConfirmSubscription(&SNSMessage{SubscribeURL: "https://sns.example.com/confirm"})
+
+	assert.ErrorIs(t, err, ErrInvalidSNSMessage)
+}
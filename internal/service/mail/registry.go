@@ -0,0 +1,463 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmlTemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the templates at the root of the template
+// directory. Other locales live in a sub directory named after their tag, e.g.
+// templates/id, and fall back to the root for anything they do not define.
+const DefaultLocale = "en"
+
+// catalogDir holds one message catalog per locale, e.g. templates/locales/id.json.
+const catalogDir = "locales"
+
+// layoutFiles are shared by every HTML template and are not templates of their
+// own.
+var layoutFiles = []string{"base.html", "styles.html"}
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+type (
+	mailTemplate struct {
+		html *htmlTemplate.Template
+		// text is nil when the template has no .txt file, the plain text part is
+		// then generated from the rendered HTML.
+		text *textTemplate.Template
+	}
+
+	localeTemplates struct {
+		templates map[string]mailTemplate
+		messages  map[string]string
+	}
+
+	// TemplateRegistry parses every template in dir once. With reload set the
+	// templates are parsed again whenever a file in dir changes, which is meant
+	// for local development only.
+	TemplateRegistry struct {
+		l       logger.Interface
+		mu      sync.RWMutex
+		dir     string
+		reload  bool
+		modTime time.Time
+		locales map[string]*localeTemplates
+		// tags lists the supported locales for the matcher, DefaultLocale first.
+		tags    []language.Tag
+		matcher language.Matcher
+	}
+)
+
+func NewTemplateRegistry(l logger.Interface, dir string, reload bool) (*TemplateRegistry, error) {
+	r := &TemplateRegistry{l: l, dir: dir, reload: reload}
+
+	err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Names returns the template names accepted by SendEmailRequest.Template.
+func (r *TemplateRegistry) Names() []string {
+	r.refreshOrLog()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.locales[DefaultLocale].templates))
+	for name := range r.locales[DefaultLocale].templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (r *TemplateRegistry) Has(name string) bool {
+	r.refreshOrLog()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.locales[DefaultLocale].templates[name]
+	return ok
+}
+
+// Locales returns the supported locales, DefaultLocale first.
+func (r *TemplateRegistry) Locales() []string {
+	r.refreshOrLog()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locales := make([]string, 0, len(r.tags))
+	for _, tag := range r.tags {
+		locales = append(locales, tag.String())
+	}
+
+	return locales
+}
+
+// MatchLocale returns the supported locale closest to the given BCP 47 tag,
+// so "id-ID" uses the "id" templates. Unknown or empty tags get DefaultLocale.
+func (r *TemplateRegistry) MatchLocale(locale string) string {
+	r.refreshOrLog()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.matchLocale(locale)
+}
+
+// Render executes the named template in the closest supported locale and
+// returns its HTML and plain text parts.
+func (r *TemplateRegistry) Render(name string, locale string, data any) (string, string, error) {
+	err := r.refresh()
+	if err != nil {
+		return "", "", err
+	}
+
+	r.mu.RLock()
+	tmpl, ok := r.locales[r.matchLocale(locale)].templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	return tmpl.render(data)
+}
+
+// Subject returns the translated subject of the named template, falling back
+// to the DefaultLocale catalog.
+func (r *TemplateRegistry) Subject(name string, locale string) string {
+	return r.Message(strings.TrimSuffix(name, filepath.Ext(name))+".subject", locale)
+}
+
+// Message looks key up in the catalog of the closest supported locale, then in
+// the DefaultLocale catalog. Unknown keys are returned as is.
+func (r *TemplateRegistry) Message(key string, locale string) string {
+	r.refreshOrLog()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if message, ok := r.locales[r.matchLocale(locale)].messages[key]; ok {
+		return message
+	}
+	if message, ok := r.locales[DefaultLocale].messages[key]; ok {
+		return message
+	}
+
+	return key
+}
+
+// RegisterValidation adds the mail_template tag to v, which accepts the names
+// of the registered templates, and validates SendEmailRequest.Data against the
+// EmailData type of its template.
+func (r *TemplateRegistry) RegisterValidation(v *validator.Validate) error {
+	v.RegisterStructValidation(validateEmailData, request.SendEmailRequest{})
+
+	return v.RegisterValidation("mail_template", func(fl validator.FieldLevel) bool {
+		return r.Has(fl.Field().String())
+	})
+}
+
+func validateEmailData(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.SendEmailRequest)
+
+	if _, err := req.TemplateData(); err != nil {
+		sl.ReportError(req.Data, "Data", "Data", "email_data", "")
+		return
+	}
+
+	data := request.NewEmailData(req.Template)
+	if data == nil || len(req.Data) == 0 {
+		if data != nil {
+			sl.ReportError(req.Data, "Data", "Data", "required", "")
+		}
+		return
+	}
+
+	if err := json.Unmarshal(req.Data, data); err != nil {
+		sl.ReportError(req.Data, "Data", "Data", "email_data", "")
+		return
+	}
+
+	err := sl.Validator().Struct(data)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldErr := range validationErrors {
+			sl.ReportError(fieldErr.Value(), "Data."+fieldErr.Field(), fieldErr.StructField(), fieldErr.Tag(), fieldErr.Param())
+		}
+	}
+}
+
+func (r *TemplateRegistry) matchLocale(locale string) string {
+	if locale == "" {
+		return DefaultLocale
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return DefaultLocale
+	}
+
+	_, index, confidence := r.matcher.Match(tag)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return r.tags[index].String()
+}
+
+func (r *TemplateRegistry) refresh() error {
+	if !r.reload {
+		return nil
+	}
+
+	modTime, err := latestModTime(r.dir)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	changed := modTime.After(r.modTime)
+	r.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	r.l.Info("mail - reloading templates of %s", r.dir)
+	return r.load()
+}
+
+// refreshOrLog refreshes the templates for the lookups that cannot return an
+// error. They keep using the templates loaded last when it fails.
+func (r *TemplateRegistry) refreshOrLog() {
+	err := r.refresh()
+	if err != nil {
+		r.l.Error(fmt.Errorf("mail - TemplateRegistry - refresh: %w", err))
+	}
+}
+
+func (r *TemplateRegistry) load() error {
+	modTime, err := latestModTime(r.dir)
+	if err != nil {
+		return err
+	}
+
+	root, err := parseLocale(r.dir, DefaultLocale, "", nil)
+	if err != nil {
+		return err
+	}
+	if len(root.templates) == 0 {
+		return fmt.Errorf("no mail templates found in %s", r.dir)
+	}
+	for name := range root.templates {
+		if _, ok := root.messages[strings.TrimSuffix(name, filepath.Ext(name))+".subject"]; !ok {
+			return fmt.Errorf("mail template %s has no subject in the %s catalog", name, DefaultLocale)
+		}
+	}
+
+	locales := map[string]*localeTemplates{DefaultLocale: root}
+	tags := []language.Tag{language.Make(DefaultLocale)}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == catalogDir {
+			continue
+		}
+
+		tag, err := language.Parse(entry.Name())
+		if err != nil {
+			return fmt.Errorf("mail template directory %s is not a locale: %w", entry.Name(), err)
+		}
+
+		locale := tag.String()
+		if _, ok := locales[locale]; ok {
+			continue
+		}
+
+		locales[locale], err = parseLocale(r.dir, locale, filepath.Join(r.dir, entry.Name()), root)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locales = locales
+	r.tags = tags
+	r.matcher = language.NewMatcher(tags)
+	r.modTime = modTime
+	return nil
+}
+
+// parseLocale parses the templates of one locale. Root locales are parsed from
+// dir, other locales from localeDir with their missing layouts, templates and
+// messages taken from fallback.
+func parseLocale(dir string, locale string, localeDir string, fallback *localeTemplates) (*localeTemplates, error) {
+	messages, err := readCatalog(filepath.Join(dir, catalogDir, locale+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &localeTemplates{templates: map[string]mailTemplate{}, messages: messages}
+
+	pageDir := dir
+	if localeDir != "" {
+		pageDir = localeDir
+	}
+
+	var layouts []string
+	for _, file := range layoutFiles {
+		layout := filepath.Join(pageDir, file)
+		if _, err := os.Stat(layout); err != nil {
+			layout = filepath.Join(dir, file)
+		}
+		layouts = append(layouts, layout)
+	}
+
+	pages, err := filepath.Glob(filepath.Join(pageDir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, page := range pages {
+		name := filepath.Base(page)
+		if isLayout(name) {
+			continue
+		}
+		if fallback != nil {
+			if _, ok := fallback.templates[name]; !ok {
+				return nil, fmt.Errorf("mail template %s/%s has no %s version", locale, name, DefaultLocale)
+			}
+		}
+
+		html, err := htmlTemplate.New(name).ParseFiles(append(layouts, page)...)
+		if err != nil {
+			return nil, fmt.Errorf("parse mail template %s/%s: %w", locale, name, err)
+		}
+
+		tmpl := mailTemplate{html: html}
+
+		textFile := strings.TrimSuffix(page, ".html") + ".txt"
+		if _, err := os.Stat(textFile); err == nil {
+			tmpl.text, err = textTemplate.ParseFiles(textFile)
+			if err != nil {
+				return nil, fmt.Errorf("parse mail template %s/%s: %w", locale, filepath.Base(textFile), err)
+			}
+		}
+
+		// Parsing does not catch calls to undefined templates or fields, a
+		// render with sample data does.
+		data, err := templateData(SampleEmailRequest(name))
+		if err != nil {
+			return nil, err
+		}
+		_, _, err = tmpl.render(data)
+		if err != nil {
+			return nil, fmt.Errorf("render mail template %s/%s: %w", locale, name, err)
+		}
+
+		result.templates[name] = tmpl
+	}
+
+	if fallback != nil {
+		for name, tmpl := range fallback.templates {
+			if _, ok := result.templates[name]; !ok {
+				result.templates[name] = tmpl
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// readCatalog reads a flat JSON object of message keys to translations. A
+// missing catalog is empty.
+func readCatalog(file string) (map[string]string, error) {
+	messages := map[string]string{}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return messages, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &messages)
+	if err != nil {
+		return nil, fmt.Errorf("parse mail catalog %s: %w", filepath.Base(file), err)
+	}
+
+	return messages, nil
+}
+
+func (t mailTemplate) render(data any) (string, string, error) {
+	var html bytes.Buffer
+	err := t.html.Execute(&html, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	if t.text == nil {
+		return html.String(), htmlToText(html.String()), nil
+	}
+
+	var text bytes.Buffer
+	err = t.text.Execute(&text, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return html.String(), text.String(), nil
+}
+
+func isLayout(name string) bool {
+	for _, layout := range layoutFiles {
+		if name == layout {
+			return true
+		}
+	}
+	return false
+}
+
+func latestModTime(dir string) (time.Time, error) {
+	var latest time.Time
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+
+	return latest, err
+}
@@ -0,0 +1,178 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+const testLayout = `{{define "base"}}<html><head><title>{{.Subject}}</title>{{template "styles" .}}</head><body>{{block "content" .}}{{end}}</body></html>{{end}}`
+const testStyles = `{{define "styles"}}<style>p { color: red; }</style>{{end}}`
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	files["base.html"] = testLayout
+	files["styles.html"] = testStyles
+	if _, ok := files["locales/en.json"]; !ok {
+		files["locales/en.json"] = `{"welcome.subject": "Welcome", "broken.subject": "Broken", "missing.subject": "Missing"}`
+	}
+	for name, content := range files {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestTemplateRegistry_ShouldGenerateTextPartFromHTML(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.html": `{{template "base" .}}{{define "content"}}<p>Hi <b>{{.Name}}</b>,</p><p><a href="{{.LinkUrl}}">Open app</a></p>{{end}}`,
+	})
+	registry, err := NewTemplateRegistry(logger.NewLogger("error"), dir, false)
+	assert.Nil(t, err)
+
+	html, text, err := registry.Render("welcome.html", DefaultLocale, map[string]any{"Name": "Jane", "LinkUrl": "https://app.test"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, html, "<b>Jane</b>")
+	assert.Equal(t, "Hi Jane,\n\nOpen app (https://app.test)\n", text)
+	assert.Equal(t, []string{"welcome.html"}, registry.Names())
+}
+
+func TestTemplateRegistry_ShouldLoadTextPartFromFile(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.html": `{{template "base" .}}{{define "content"}}<p>Hi {{.Name}}</p>{{end}}`,
+		"welcome.txt":  `Welcome {{.Name}} & friends`,
+	})
+	registry, err := NewTemplateRegistry(logger.NewLogger("error"), dir, false)
+	assert.Nil(t, err)
+
+	_, text, err := registry.Render("welcome.html", DefaultLocale, map[string]any{"Name": "Jane"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Welcome Jane & friends", text)
+}
+
+func TestTemplateRegistry_ShouldFailFastOnBrokenTemplate(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"broken.html": `{{template "base" .}}{{define "content"}}{{.Name}{{end}}`,
+	})
+	_, err := NewTemplateRegistry(logger.NewLogger("error"), dir, false)
+	assert.ErrorContains(t, err, "parse mail template en/broken.html")
+
+	dir = writeTemplates(t, map[string]string{
+		"missing.html": `{{template "base" .}}{{define "content"}}{{template "footer" .}}{{end}}`,
+	})
+	_, err = NewTemplateRegistry(logger.NewLogger("error"), dir, false)
+	assert.ErrorContains(t, err, "render mail template en/missing.html")
+}
+
+func TestTemplateRegistry_ShouldReloadChangedTemplates(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.html": `{{template "base" .}}{{define "content"}}<p>Hello</p>{{end}}`,
+	})
+	registry, err := NewTemplateRegistry(logger.NewLogger("error"), dir, true)
+	assert.Nil(t, err)
+
+	file := filepath.Join(dir, "welcome.html")
+	assert.Nil(t, os.WriteFile(file, []byte(`{{template "base" .}}{{define "content"}}<p>Goodbye</p>{{end}}`), 0o644))
+	later := time.Now().Add(time.Second)
+	assert.Nil(t, os.Chtimes(file, later, later))
+
+	html, _, err := registry.Render("welcome.html", DefaultLocale, map[string]any{})
+
+	assert.Nil(t, err)
+	assert.Contains(t, html, "Goodbye")
+}
+
+func TestTemplateRegistry_ShouldFallBackToDefaultLocale(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.html":    `{{template "base" .}}{{define "content"}}<p>Hello</p>{{end}}`,
+		"goodbye.html":    `{{template "base" .}}{{define "content"}}<p>Goodbye</p>{{end}}`,
+		"id/welcome.html": `{{template "base" .}}{{define "content"}}<p>Halo</p>{{end}}`,
+		"locales/en.json": `{"welcome.subject": "Welcome", "goodbye.subject": "Goodbye"}`,
+		"locales/id.json": `{"welcome.subject": "Selamat datang"}`,
+	})
+	registry, err := NewTemplateRegistry(logger.NewLogger("error"), dir, false)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"en", "id"}, registry.Locales())
+	assert.Equal(t, "id", registry.MatchLocale("id-ID"))
+	assert.Equal(t, "en", registry.MatchLocale("fr"))
+	assert.Equal(t, "en", registry.MatchLocale("not a locale"))
+
+	html, _, err := registry.Render("welcome.html", "id-ID", map[string]any{})
+	assert.Nil(t, err)
+	assert.Contains(t, html, "Halo")
+
+	html, _, err = registry.Render("goodbye.html", "id", map[string]any{})
+	assert.Nil(t, err)
+	assert.Contains(t, html, "Goodbye")
+
+	assert.Equal(t, "Selamat datang", registry.Subject("welcome.html", "id"))
+	assert.Equal(t, "Goodbye", registry.Subject("goodbye.html", "id"))
+}
+
+func TestTemplateRegistry_ShouldRequireDefaultSubjects(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.html":    `{{template "base" .}}{{define "content"}}<p>Hello</p>{{end}}`,
+		"locales/en.json": `{}`,
+	})
+
+	_, err := NewTemplateRegistry(logger.NewLogger("error"), dir, false)
+
+	assert.ErrorContains(t, err, "mail template welcome.html has no subject in the en catalog")
+}
+
+func TestTemplateRegistry_RegisterValidation_ShouldAcceptRegisteredTemplates(t *testing.T) {
+	v := validator.New()
+	assert.Nil(t, templates.RegisterValidation(v))
+
+	req := emailInputRequest
+	assert.Nil(t, v.Struct(req))
+
+	req.Template = "base.html"
+	assert.Error(t, v.Struct(req))
+}
+
+func TestTemplateRegistry_RegisterValidation_ShouldValidateTemplateData(t *testing.T) {
+	v := validator.New()
+	assert.Nil(t, templates.RegisterValidation(v))
+
+	req := emailInputRequest
+	req.Data = []byte(`{"Name":"Name"}`)
+	err := v.Struct(req)
+	assert.ErrorContains(t, err, "Data.Token")
+
+	req.Data = nil
+	assert.ErrorContains(t, v.Struct(req), "'Data' failed on the 'required' tag")
+
+	req.Data = []byte(`[1, 2]`)
+	assert.ErrorContains(t, v.Struct(req), "'Data' failed on the 'email_data' tag")
+
+	req.To = []request.EmailAddress{{Email: "not an email"}}
+	req.Data = []byte(`{"Token":1}`)
+	assert.ErrorContains(t, v.Struct(req), "'Email' failed on the 'email' tag")
+}
+
+func TestTemplateRegistry_ShouldKeepTemplatesWhenReloadFails(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"welcome.html": `{{template "base" .}}{{define "content"}}<p>Hello</p>{{end}}`,
+	})
+	registry, err := NewTemplateRegistry(logger.NewLogger("error"), dir, true)
+	assert.Nil(t, err)
+
+	file := filepath.Join(dir, "welcome.html")
+	assert.Nil(t, os.WriteFile(file, []byte(`{{template "base" .}}{{define "content"}}<p>{{.Broken</p>{{end}}`), 0o644))
+	later := time.Now().Add(time.Second)
+	assert.Nil(t, os.Chtimes(file, later, later))
+
+	assert.Equal(t, []string{"welcome.html"}, registry.Names())
+	_, _, err = registry.Render("welcome.html", DefaultLocale, map[string]any{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+type SESTransport struct {
+	client sesiface.SESAPI
+}
+
+func NewSESTransport(client sesiface.SESAPI) *SESTransport {
+	return &SESTransport{client: client}
+}
+
+// Send uses the simple SendEmail API for plain HTML mail and falls back to
+// SendRawEmail when the message needs attachments or custom headers, which
+// SendEmail cannot express.
+func (s *SESTransport) Send(message Message) (string, error) {
+	if len(message.Attachments) > 0 || len(message.Headers) > 0 {
+		return s.sendRaw(message)
+	}
+
+	body := &ses.Body{
+		Html: &ses.Content{
+			Charset: aws.String("UTF-8"),
+			Data:    aws.String(message.HTML),
+		},
+	}
+	if message.Text != "" {
+		body.Text = &ses.Content{
+			Charset: aws.String("UTF-8"),
+			Data:    aws.String(message.Text),
+		}
+	}
+
+	input := &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			ToAddresses:  aws.StringSlice(message.To),
+			CcAddresses:  aws.StringSlice(message.Cc),
+			BccAddresses: aws.StringSlice(message.Bcc),
+		},
+		Message: &ses.Message{
+			Body: body,
+			Subject: &ses.Content{
+				Charset: aws.String("UTF-8"),
+				Data:    aws.String(message.Subject),
+			},
+		},
+		Source: aws.String(message.From),
+	}
+	if message.ReplyTo != "" {
+		input.ReplyToAddresses = aws.StringSlice([]string{message.ReplyTo})
+	}
+
+	result, err := s.client.SendEmail(input)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(result.MessageId), nil
+}
+
+func (s *SESTransport) sendRaw(message Message) (string, error) {
+	raw, _, err := rawMIME(message)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := s.client.SendRawEmail(&ses.SendRawEmailInput{
+		Destinations: aws.StringSlice(recipients(message)),
+		RawMessage:   &ses.RawMessage{Data: raw},
+		Source:       aws.String(message.From),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(result.MessageId), nil
+}
@@ -0,0 +1,89 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var sesMock = new(mocks.SESAPI)
+var sesTransport = NewSESTransport(sesMock)
+
+var sesMessage = Message{
+	From:    "no-reply@test.com",
+	To:      []string{"test@test.com"},
+	Subject: "Subject",
+	HTML:    "<p>Hello</p>",
+}
+
+func TestSESTransport_Send_ShouldSuccess_WhenParamsIsValid(t *testing.T) {
+	sesMock.Calls = nil
+	sesMock.On("SendEmail", mock.Anything).Return(&ses.SendEmailOutput{MessageId: aws.String("output")}, nil).Once()
+
+	messageId, err := sesTransport.Send(sesMessage)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "output", messageId)
+	args := sesMock.Calls[0].Arguments[0].(*ses.SendEmailInput)
+	assert.Equal(t, sesMessage.HTML, *args.Message.Body.Html.Data)
+	assert.Nil(t, args.Message.Body.Text)
+	assert.Equal(t, sesMessage.To[0], *args.Destination.ToAddresses[0])
+	assert.Equal(t, sesMessage.Subject, *args.Message.Subject.Data)
+	assert.Equal(t, sesMessage.From, *args.Source)
+}
+
+func TestSESTransport_Send_ShouldReturnErrorWhenClientSendEmailFail(t *testing.T) {
+	sendEmailErr := errors.New("send email error")
+	sesMock.On("SendEmail", mock.Anything).Return(nil, sendEmailErr).Once()
+
+	_, err := sesTransport.Send(sesMessage)
+
+	assert.Equal(t, sendEmailErr, err)
+}
+
+func TestSESTransport_Send_ShouldSendRawEmailWhenMessageHasAttachments(t *testing.T) {
+	sesMock.Calls = nil
+	sesMock.On("SendRawEmail", mock.Anything).Return(&ses.SendRawEmailOutput{MessageId: aws.String("raw-output")}, nil).Once()
+
+	message := sesMessage
+	message.Attachments = []Attachment{
+		{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")},
+	}
+
+	messageId, err := sesTransport.Send(message)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "raw-output", messageId)
+	args := sesMock.Calls[0].Arguments[0].(*ses.SendRawEmailInput)
+	raw := string(args.RawMessage.Data)
+	assert.True(t, strings.Contains(raw, "Content-Type: multipart/mixed"))
+	assert.True(t, strings.Contains(raw, `filename="invoice.pdf"`))
+	assert.True(t, strings.Contains(raw, "Subject: Subject"))
+	assert.Equal(t, message.To[0], *args.Destinations[0])
+	sesMock.AssertNotCalled(t, "SendEmail", mock.Anything)
+}
+
+func TestSESTransport_Send_ShouldKeepBccOutOfRawHeaders(t *testing.T) {
+	sesMock.Calls = nil
+	sesMock.On("SendRawEmail", mock.Anything).Return(&ses.SendRawEmailOutput{MessageId: aws.String("raw-output")}, nil).Once()
+
+	message := sesMessage
+	message.Cc = []string{"cc@test.com"}
+	message.Bcc = []string{"audit@test.com"}
+	message.Headers = map[string]string{"X-Campaign": "welcome"}
+
+	_, err := sesTransport.Send(message)
+
+	assert.Equal(t, nil, err)
+	args := sesMock.Calls[0].Arguments[0].(*ses.SendRawEmailInput)
+	raw := string(args.RawMessage.Data)
+	assert.Equal(t, []string{"test@test.com", "cc@test.com", "audit@test.com"}, aws.StringValueSlice(args.Destinations))
+	assert.True(t, strings.Contains(raw, "Cc: cc@test.com"))
+	assert.False(t, strings.Contains(raw, "audit@test.com"))
+}
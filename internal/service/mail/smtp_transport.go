@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"crypto/tls"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"gopkg.in/gomail.v2"
+)
+
+type SMTPTransport struct {
+	dialer *gomail.Dialer
+}
+
+func NewSMTPTransport(cfg *config.Config) *SMTPTransport {
+	d := gomail.NewDialer(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.User, cfg.Mail.Password)
+	d.TLSConfig = &tls.Config{
+		ServerName: cfg.Mail.Host,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return &SMTPTransport{dialer: d}
+}
+
+func (s *SMTPTransport) Send(message Message) (string, error) {
+	m, messageId := buildMIME(message)
+
+	err := s.dialer.DialAndSend(m)
+	if err != nil {
+		return "", err
+	}
+
+	return messageId, nil
+}
@@ -0,0 +1,220 @@
+package mail
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snsHostPattern matches the hosts SNS signs and sends confirmation links from.
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+var ErrInvalidSNSMessage = errors.New("sns message is not valid")
+
+type (
+	// SNSMessage is the envelope SNS posts to HTTP subscriptions.
+	SNSMessage struct {
+		Type             string
+		MessageId        string
+		Token            string
+		TopicArn         string
+		Subject          string
+		Message          string
+		Timestamp        string
+		SignatureVersion string
+		Signature        string
+		SigningCertURL   string
+		SubscribeURL     string
+	}
+
+	// CertificateFetcher returns the signing certificate at a trusted SNS URL.
+	CertificateFetcher func(certURL string) (*x509.Certificate, error)
+
+	// SNSWebhook verifies SNS message signatures and confirms subscriptions.
+	// Signing certificates are fetched once per URL.
+	SNSWebhook struct {
+		client   *http.Client
+		topicArn string
+		fetch    CertificateFetcher
+		mu       sync.Mutex
+		certs    map[string]*x509.Certificate
+	}
+)
+
+// NewSNSWebhook only accepts messages of topicArn, an empty topicArn accepts
+// any topic. A nil fetch downloads the certificates with client.
+func NewSNSWebhook(client *http.Client, topicArn string, fetch CertificateFetcher) *SNSWebhook {
+	if client == nil {
+		client = &http.Client{Timeout: time.Second * 10}
+	}
+	if fetch == nil {
+		fetch = downloadCertificate(client)
+	}
+	return &SNSWebhook{client: client, topicArn: topicArn, fetch: fetch, certs: map[string]*x509.Certificate{}}
+}
+
+func ParseSNSMessage(body []byte) (*SNSMessage, error) {
+	var message SNSMessage
+	err := json.Unmarshal(body, &message)
+	if err != nil || message.Type == "" {
+		return nil, ErrInvalidSNSMessage
+	}
+	return &message, nil
+}
+
+func (s *SNSWebhook) Verify(message *SNSMessage) error {
+	if s.topicArn != "" && message.TopicArn != s.topicArn {
+		return fmt.Errorf("%w: unexpected topic %s", ErrInvalidSNSMessage, message.TopicArn)
+	}
+
+	var hash crypto.Hash
+	switch message.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("%w: unsupported signature version %q", ErrInvalidSNSMessage, message.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64", ErrInvalidSNSMessage)
+	}
+
+	cert, err := s.certificate(message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: signing certificate has no RSA key", ErrInvalidSNSMessage)
+	}
+
+	err = rsa.VerifyPKCS1v15(publicKey, hash, digest(hash, stringToSign(message)), signature)
+	if err != nil {
+		return fmt.Errorf("%w: bad signature", ErrInvalidSNSMessage)
+	}
+
+	return nil
+}
+
+// ConfirmSubscription visits the SubscribeURL of a verified
+// SubscriptionConfirmation message.
+func (s *SNSWebhook) ConfirmSubscription(message *SNSMessage) error {
+	if err := checkSNSURL(message.SubscribeURL); err != nil {
+		return err
+	}
+
+	res, err := s.client.Get(message.SubscribeURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("confirm sns subscription: status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *SNSWebhook) certificate(certURL string) (*x509.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cert, ok := s.certs[certURL]; ok {
+		return cert, nil
+	}
+
+	if err := checkSNSURL(certURL); err != nil {
+		return nil, err
+	}
+
+	cert, err := s.fetch(certURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s.certs[certURL] = cert
+	return cert, nil
+}
+
+func downloadCertificate(client *http.Client) CertificateFetcher {
+	return func(certURL string) (*x509.Certificate, error) {
+		res, err := client.Get(certURL)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+		if err != nil {
+			return nil, err
+		}
+
+		block, _ := pem.Decode(body)
+		if block == nil {
+			return nil, fmt.Errorf("%w: signing certificate is not PEM", ErrInvalidSNSMessage)
+		}
+
+		return x509.ParseCertificate(block.Bytes)
+	}
+}
+
+func checkSNSURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || !snsHostPattern.MatchString(u.Hostname()) {
+		return fmt.Errorf("%w: untrusted url %q", ErrInvalidSNSMessage, rawURL)
+	}
+	return nil
+}
+
+// stringToSign builds the canonical form SNS signs, see
+// https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func stringToSign(message *SNSMessage) []byte {
+	fields := [][2]string{{"Message", message.Message}, {"MessageId", message.MessageId}}
+	if message.Type == "Notification" {
+		if message.Subject != "" {
+			fields = append(fields, [2]string{"Subject", message.Subject})
+		}
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", message.SubscribeURL})
+	}
+	fields = append(fields, [2]string{"Timestamp", message.Timestamp})
+	if message.Type != "Notification" {
+		fields = append(fields, [2]string{"Token", message.Token})
+	}
+	fields = append(fields, [2]string{"TopicArn", message.TopicArn}, [2]string{"Type", message.Type})
+
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+
+	return []byte(b.String())
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(data)
+		return sum[:]
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
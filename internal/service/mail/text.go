@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+	spacesPattern     = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// blockElements end the current line of the plain text part.
+var blockElements = map[string]bool{
+	"br": true, "p": true, "div": true, "tr": true, "table": true, "li": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// htmlToText builds the plain text alternative of an HTML email. Head, style
+// and script content is dropped and links keep their target after the text.
+func htmlToText(source string) string {
+	var b strings.Builder
+	var href string
+	skip := 0
+
+	tokenizer := html.NewTokenizer(strings.NewReader(source))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch {
+			case token.Data == "head" || token.Data == "style" || token.Data == "script":
+				if tokenType == html.StartTagToken {
+					skip++
+				}
+			case token.Data == "a":
+				href = ""
+				for _, attr := range token.Attr {
+					if attr.Key == "href" {
+						href = attr.Val
+					}
+				}
+			case blockElements[token.Data]:
+				b.WriteString("\n")
+			}
+		case html.EndTagToken:
+			switch {
+			case token.Data == "head" || token.Data == "style" || token.Data == "script":
+				if skip > 0 {
+					skip--
+				}
+			case token.Data == "a":
+				if href != "" {
+					b.WriteString(" (" + href + ")")
+				}
+				href = ""
+			case blockElements[token.Data]:
+				b.WriteString("\n")
+			}
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(spacesPattern.ReplaceAllString(strings.ReplaceAll(token.Data, "\n", " "), " "))
+			}
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacesPattern.ReplaceAllString(line, " "))
+	}
+
+	text := blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n"
+}
@@ -0,0 +1,146 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/google/uuid"
+	"gopkg.in/gomail.v2"
+)
+
+type (
+	// Transport delivers an already rendered message and returns the ID the
+	// provider assigned to it.
+	Transport interface {
+		Send(message Message) (string, error)
+	}
+
+	// Message addresses are RFC 5322 formatted, e.g. "Jane <jane@example.com>".
+	Message struct {
+		From        string
+		To          []string
+		Cc          []string `json:",omitempty"`
+		Bcc         []string `json:",omitempty"`
+		ReplyTo     string   `json:",omitempty"`
+		Subject     string
+		HTML        string
+		Text        string
+		Headers     map[string]string
+		Attachments []Attachment
+	}
+
+	Attachment struct {
+		Filename    string
+		ContentType string
+		Data        []byte
+	}
+)
+
+// NewTransport returns the transport selected by MAIL_DRIVER.
+func NewTransport(cfg *config.Config, sesClient sesiface.SESAPI) (Transport, error) {
+	switch strings.ToLower(cfg.Mail.Driver) {
+	case "", "smtp":
+		return NewSMTPTransport(cfg), nil
+	case "ses":
+		return NewSESTransport(sesClient), nil
+	case "memory":
+		return NewMemoryTransport(), nil
+	case "file":
+		if cfg.Mail.CaptureDir == "" {
+			return nil, fmt.Errorf("mail driver file requires MAIL_CAPTURE_DIR")
+		}
+		return NewFileTransport(cfg.Mail.CaptureDir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Mail.Driver)
+	}
+}
+
+// buildMIME builds the multipart message shared by the SMTP and raw SES
+// transports. The Message-Id header is generated when missing and returned so
+// transports without their own IDs can still report one.
+func buildMIME(message Message) (*gomail.Message, string) {
+	m := gomail.NewMessage()
+
+	messageId := message.Headers["Message-Id"]
+	if messageId == "" {
+		messageId = fmt.Sprintf("<%s@%s>", uuid.Must(uuid.NewRandom()).String(), senderDomain(message.From))
+	}
+
+	m.SetHeaders(map[string][]string{
+		"From":       {message.From},
+		"To":         message.To,
+		"Subject":    {message.Subject},
+		"Message-Id": {messageId},
+	})
+	if len(message.Cc) > 0 {
+		m.SetHeader("Cc", message.Cc...)
+	}
+	// gomail uses Bcc for the envelope only and never writes it out.
+	if len(message.Bcc) > 0 {
+		m.SetHeader("Bcc", message.Bcc...)
+	}
+	if message.ReplyTo != "" {
+		m.SetHeader("Reply-To", message.ReplyTo)
+	}
+	for key, value := range message.Headers {
+		m.SetHeader(key, value)
+	}
+
+	if message.Text != "" {
+		m.SetBody("text/plain", message.Text)
+		m.AddAlternative("text/html", message.HTML)
+	} else {
+		m.SetBody("text/html", message.HTML)
+	}
+
+	for _, attachment := range message.Attachments {
+		data := attachment.Data
+		settings := []gomail.FileSetting{
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			}),
+		}
+		if attachment.ContentType != "" {
+			settings = append(settings, gomail.SetHeader(map[string][]string{
+				"Content-Type": {attachment.ContentType},
+			}))
+		}
+		m.Attach(attachment.Filename, settings...)
+	}
+
+	return m, messageId
+}
+
+func rawMIME(message Message) ([]byte, string, error) {
+	m, messageId := buildMIME(message)
+
+	var raw bytes.Buffer
+	_, err := m.WriteTo(&raw)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return raw.Bytes(), messageId, nil
+}
+
+// recipients returns every envelope recipient of the message.
+func recipients(message Message) []string {
+	var result []string
+	result = append(result, message.To...)
+	result = append(result, message.Cc...)
+	result = append(result, message.Bcc...)
+	return result
+}
+
+func senderDomain(from string) string {
+	from = strings.TrimRight(from, ">")
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		return from[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,121 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/getsentry/sentry-go"
+	"gorm.io/gorm"
+)
+
+// orphanBatchSize is how many orphans a sweep loads at once.
+const orphanBatchSize = 100
+
+// AttachMedia adds a reference from an entity of the user to the media, which
+// is then kept until every entity detaches it. Entities call it when they
+// adopt a media and DetachMedia when they let it go.
+func (ms *MediaService) AttachMedia(userID uint, id uint) error {
+	attached, err := ms.mediaRepo.Attach(id, userID)
+	if err != nil {
+		return err
+	}
+	if !attached {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (ms *MediaService) DetachMedia(id uint) error {
+	return ms.mediaRepo.Detach(id)
+}
+
+func (ms *MediaService) GetOrphanReport(p model.Pagination) (*response.OrphanMediaReport, error) {
+	olderThan := time.Now().Add(-orphanTTL(ms.cfg)).UTC()
+
+	count, size, err := ms.mediaRepo.SumOrphans(olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := ms.mediaRepo.FindOrphans(olderThan, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.OrphanMediaReport{
+		OlderThan: olderThan,
+		DryRun:    ms.cfg.Media.GCDryRun,
+		Count:     count,
+		Size:      size,
+		Media:     media,
+	}, nil
+}
+
+// SweepOrphans deletes the media no entity used since the orphan TTL, in the
+// database first so a media attached meanwhile is kept, then in storage. A
+// dry run only counts them.
+func (ms *MediaService) SweepOrphans(dryRun bool, ctx context.Context) (*response.MediaSweepResponse, error) {
+	olderThan := time.Now().Add(-orphanTTL(ms.cfg)).UTC()
+	sweep := &response.MediaSweepResponse{OlderThan: olderThan, DryRun: dryRun}
+
+	var afterID uint
+	for {
+		batch, err := ms.mediaRepo.FindOrphanBatch(olderThan, afterID, orphanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range batch {
+			media := &batch[i]
+			afterID = media.ID
+
+			if !dryRun {
+				deleted, err := ms.mediaRepo.DeleteOrphan(media.ID, olderThan)
+				if err != nil {
+					return nil, err
+				}
+				if !deleted {
+					continue
+				}
+
+				err = ms.deleteBlobs(media, ctx)
+				if err != nil {
+					sweep.Failed++
+					sentry.CaptureException(err)
+				}
+			}
+
+			sweep.Count++
+			sweep.Size += media.Size
+		}
+
+		if len(batch) < orphanBatchSize {
+			return sweep, nil
+		}
+	}
+}
+
+// SweepOrphanMedia is the scheduled sweep, in the configured mode.
+func (ms *MediaService) SweepOrphanMedia() error {
+	sweep, err := ms.SweepOrphans(ms.cfg.Media.GCDryRun, context.Background())
+	if err != nil {
+		return err
+	}
+
+	if sweep.DryRun {
+		ms.l.Info("media gc - dry run, would delete %d orphan media of %d bytes", sweep.Count, sweep.Size)
+		return nil
+	}
+	ms.l.Info("media gc - deleted %d orphan media of %d bytes, %d with files left", sweep.Count, sweep.Size, sweep.Failed)
+	return nil
+}
+
+func orphanTTL(cfg *config.Config) time.Duration {
+	if cfg.Media.OrphanTTL <= 0 {
+		return time.Hour * 24
+	}
+	return time.Duration(cfg.Media.OrphanTTL) * time.Second
+}
@@ -0,0 +1,83 @@
+package media
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func orphanMedia(store *storage.MemoryStore, id uint, key string) model.Media {
+	_, _ = store.Put(context.Background(), key, strings.NewReader(pngBytes(4, 3)), storage.PutOptions{ContentType: "image/png"})
+	_, _ = store.Put(context.Background(), variantPrefix(&model.Media{ID: id})+"thumbnail.jpeg", strings.NewReader("thumbnail"), storage.PutOptions{ContentType: "image/jpeg"})
+	return model.Media{ID: id, UserID: 7, StorageKey: key, MimeType: "image/png", Size: 100}
+}
+
+func TestMedia_AttachMediaShouldRejectMediaOfOtherUsers(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("Attach", uint(3), uint(8)).Return(false, nil).Once()
+
+	err := mediaService.AttachMedia(8, 3)
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMedia_SweepOrphansShouldDeleteRecordsAndFiles(t *testing.T) {
+	mediaService, store := newMediaService()
+	orphans := []model.Media{orphanMedia(store, 3, "image/a.png"), orphanMedia(store, 4, "image/b.png")}
+	mediaRepoMock.On("FindOrphanBatch", mock.Anything, uint(0), orphanBatchSize).Return(orphans, nil).Once()
+	mediaRepoMock.On("DeleteOrphan", uint(3), mock.Anything).Return(true, nil).Once()
+	// Media 4 was attached after it was found, it is kept.
+	mediaRepoMock.On("DeleteOrphan", uint(4), mock.Anything).Return(false, nil).Once()
+
+	sweep, err := mediaService.SweepOrphans(false, context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sweep.Count)
+	assert.Equal(t, int64(100), sweep.Size)
+	assert.False(t, sweep.DryRun)
+	blobs, _ := store.List(context.Background(), "")
+	keys := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		keys = append(keys, blob.Key)
+	}
+	assert.ElementsMatch(t, []string{"image/b.png", "variants/4/thumbnail.jpeg"}, keys)
+}
+
+func TestMedia_SweepOrphansShouldOnlyCountInDryRun(t *testing.T) {
+	mediaService, store := newMediaService()
+	batch := make([]model.Media, orphanBatchSize)
+	for i := range batch {
+		batch[i] = model.Media{ID: uint(i + 1), Size: 10}
+	}
+	mediaRepoMock.On("FindOrphanBatch", mock.Anything, uint(0), orphanBatchSize).Return(batch, nil).Once()
+	mediaRepoMock.On("FindOrphanBatch", mock.Anything, uint(orphanBatchSize), orphanBatchSize).Return([]model.Media{orphanMedia(store, 101, "image/c.png")}, nil).Once()
+
+	sweep, err := mediaService.SweepOrphans(true, context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, orphanBatchSize+1, sweep.Count)
+	assert.Equal(t, int64(orphanBatchSize*10+100), sweep.Size)
+	mediaRepoMock.AssertNotCalled(t, "DeleteOrphan", mock.Anything, mock.Anything)
+	blobs, _ := store.List(context.Background(), "image/")
+	assert.Len(t, blobs, 1)
+}
+
+func TestMedia_GetOrphanReportShouldSummarizeOrphans(t *testing.T) {
+	mediaService, _ := newMediaService()
+	p := model.Pagination{Limit: 10, Page: 1}
+	mediaRepoMock.On("SumOrphans", mock.Anything).Return(int64(2), int64(300), nil).Once()
+	mediaRepoMock.On("FindOrphans", mock.Anything, p).Return(&model.Pagination{Limit: 10, Page: 1, TotalDatas: 2}, nil).Once()
+
+	report, err := mediaService.GetOrphanReport(p)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), report.Count)
+	assert.Equal(t, int64(300), report.Size)
+	assert.Equal(t, int64(2), report.Media.TotalDatas)
+}
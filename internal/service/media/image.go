@@ -0,0 +1,227 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"gorm.io/gorm"
+)
+
+// processTimeout bounds the work on one media, all its variants included.
+const processTimeout = time.Minute * 2
+
+var (
+	ErrMediaNotImage          = errors.New("media is not an image")
+	ErrInvalidResizeSignature = errors.New("resize signature is not valid")
+	ErrResizeUrlExpired       = errors.New("resize url has expired")
+)
+
+// ImageService renders resized copies of image media. Configured variants are
+// generated through the queue once an image is ready, other sizes on demand
+// through signed URLs.
+type ImageService struct {
+	cfg       *config.Config
+	store     storage.BlobStore
+	mediaRepo repository.IMediaRepo
+	variants  []VariantSpec
+}
+
+func NewImageService(cfg *config.Config, store storage.BlobStore, mediaRepo repository.IMediaRepo, variants []VariantSpec) *ImageService {
+	return &ImageService{cfg: cfg, store: store, mediaRepo: mediaRepo, variants: variants}
+}
+
+// ProcessMedia generates the configured variants of an image. Processing a
+// media again replaces its variants, media deleted since they were queued are
+// skipped.
+func (is *ImageService) ProcessMedia(req request.ProcessMediaRequest) error {
+	media, err := is.mediaRepo.FindByID(req.MediaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !isReadyImage(media) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	src, err := is.loadImage(media, ctx)
+	if err != nil {
+		return err
+	}
+
+	variants := make([]model.MediaVariant, 0, len(is.variants))
+	for _, spec := range is.variants {
+		data, bounds, err := renderVariant(src, spec)
+		if err != nil {
+			return fmt.Errorf("render %s : %w", spec.Name, err)
+		}
+
+		format := imageFormats[spec.Format]
+		key := variantKey(media, spec.Name+format.Extension)
+		_, err = is.store.Put(ctx, key, bytes.NewReader(data), storage.PutOptions{
+			ContentType: format.MimeType,
+			Public:      isPublic(media),
+		})
+		if err != nil {
+			return err
+		}
+
+		variants = append(variants, model.MediaVariant{
+			MediaID:    media.ID,
+			Name:       spec.Name,
+			StorageKey: key,
+			MimeType:   format.MimeType,
+			Width:      bounds.Dx(),
+			Height:     bounds.Dy(),
+			Size:       int64(len(data)),
+		})
+	}
+
+	return is.mediaRepo.StoreVariants(variants)
+}
+
+// ResizeUrl signs a URL of an image of userID at another size. The URL
+// needs no login, so it can be used as the source of an image. URLs of
+// private images expire like their other URLs.
+func (is *ImageService) ResizeUrl(userID uint, id uint, req request.ResizeMediaRequest) (*response.ResizeUrlResponse, error) {
+	media, err := findOwnedMedia(is.mediaRepo, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isReadyImage(media) {
+		return nil, ErrMediaNotImage
+	}
+
+	res := &response.ResizeUrlResponse{}
+	query := url.Values{}
+	query.Set("w", strconv.Itoa(req.Width))
+	query.Set("h", strconv.Itoa(req.Height))
+	query.Set("fit", req.Fit)
+	query.Set("format", req.Format)
+	var expires int64
+	if !isPublic(media) {
+		expiresAt := time.Now().Add(privateUrlExpiry(is.cfg)).UTC().Truncate(time.Second)
+		expires = expiresAt.Unix()
+		res.ExpiresAt = &expiresAt
+		query.Set("expires", strconv.FormatInt(expires, 10))
+	}
+	query.Set("sig", is.resizeSignature(id, req, expires))
+
+	res.Url = fmt.Sprintf("%s/api/v1/media/%d/resize?%s", strings.TrimSuffix(is.cfg.App.Url, "/"), id, query.Encode())
+	return res, nil
+}
+
+// ResizeMedia returns the image at the size of a URL signed by ResizeUrl.
+// Every size is rendered once and kept in the blob store next to the
+// variants.
+func (is *ImageService) ResizeMedia(id uint, req request.SignedResizeMediaRequest, ctx context.Context) (io.ReadCloser, *storage.BlobInfo, error) {
+	if !hmac.Equal([]byte(req.Sig), []byte(is.resizeSignature(id, req.ResizeMediaRequest, req.Expires))) {
+		return nil, nil, ErrInvalidResizeSignature
+	}
+	if req.Expires != 0 && time.Now().Unix() > req.Expires {
+		return nil, nil, ErrResizeUrlExpired
+	}
+
+	media, err := is.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isReadyImage(media) {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	if !isPublic(media) && req.Expires == 0 {
+		return nil, nil, ErrInvalidResizeSignature
+	}
+
+	format := imageFormats[req.Format]
+	key := variantKey(media, fmt.Sprintf("%dx%d-%s%s", req.Width, req.Height, req.Fit, format.Extension))
+	body, info, err := is.store.Get(ctx, key)
+	if err == nil {
+		return body, info, nil
+	}
+	if !errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, err
+	}
+
+	src, err := is.loadImage(media, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, _, err := renderVariant(src, VariantSpec{
+		Width:  req.Width,
+		Height: req.Height,
+		Crop:   req.Fit == "crop",
+		Format: req.Format,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err = is.store.Put(ctx, key, bytes.NewReader(data), storage.PutOptions{ContentType: format.MimeType})
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), info, nil
+}
+
+func (is *ImageService) loadImage(media *model.Media, ctx context.Context) (image.Image, error) {
+	body, _, err := is.store.Get(ctx, media.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(data)
+}
+
+// resizeSignature signs the parameters of a resize, expires is zero for URLs
+// that do not expire.
+func (is *ImageService) resizeSignature(id uint, req request.ResizeMediaRequest, expires int64) string {
+	mac := hmac.New(sha256.New, []byte("resize:"+is.cfg.App.Secret))
+	payload := fmt.Sprintf("%d/%dx%d/%s/%s", id, req.Width, req.Height, req.Fit, req.Format)
+	if expires != 0 {
+		payload += "/" + strconv.FormatInt(expires, 10)
+	}
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func isReadyImage(media *model.Media) bool {
+	return media.Status == consttype.MEDIA_READY && strings.HasPrefix(media.MimeType, "image/")
+}
+
+// variantKey returns the key of a blob derived from media, all of them are
+// under variantPrefix.
+func variantKey(media *model.Media, name string) string {
+	return variantPrefix(media) + name
+}
+
+func variantPrefix(media *model.Media) string {
+	return visibilityPrefix(mediaVisibility(string(media.Visibility))) + fmt.Sprintf("variants/%d/", media.ID)
+}
@@ -0,0 +1,170 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+var imageCfg = &config.Config{App: config.App{Url: "https://api.test.com/", Secret: "secret"}}
+
+func newImageService(t *testing.T) (*ImageService, *storage.MemoryStore) {
+	mediaRepoMock.ExpectedCalls = nil
+	mediaRepoMock.Calls = nil
+
+	variants, err := ParseVariantSpecs(map[string]string{
+		"thumbnail": "10x10 crop jpeg",
+		"webp":      "16x16 fit webp",
+	})
+	assert.Nil(t, err)
+
+	store := storage.NewMemoryStore(storage.NewURLSigner("https://api.test.com/api/v1/storage", "secret"))
+	_, _ = store.Put(context.Background(), "image/upload.png", strings.NewReader(pngBytes(40, 20)), storage.PutOptions{ContentType: "image/png"})
+	return NewImageService(imageCfg, store, mediaRepoMock, variants), store
+}
+
+func readyImage() *model.Media {
+	media := pendingMedia()
+	media.Status = consttype.MEDIA_READY
+	return media
+}
+
+func TestImage_ParseVariantSpecsShouldRejectInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"200x200 crop", "200 crop jpeg", "200x0 fit jpeg", "200x200 stretch jpeg", "200x200 fit gif"} {
+		_, err := ParseVariantSpecs(map[string]string{"thumbnail": spec})
+		assert.NotNil(t, err, spec)
+	}
+}
+
+func TestImage_ResizeImageShouldFitOrCropWithoutEnlarging(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	assert.Equal(t, image.Rect(0, 0, 100, 50), resizeImage(src, 100, 100, false).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 100, 100), resizeImage(src, 100, 100, true).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 400, 200), resizeImage(src, 1000, 1000, false).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 200, 200), resizeImage(src, 1000, 1000, true).Bounds())
+}
+
+func TestImage_ProcessMediaShouldStoreVariants(t *testing.T) {
+	imageService, store := newImageService(t)
+	mediaRepoMock.On("FindByID", uint(3)).Return(readyImage(), nil).Once()
+	mediaRepoMock.On("StoreVariants", mock.MatchedBy(func(variants []model.MediaVariant) bool {
+		return len(variants) == 2 &&
+			variants[0].Name == "thumbnail" && variants[0].MimeType == "image/jpeg" && variants[0].Width == 10 && variants[0].Height == 10 &&
+			variants[1].Name == "webp" && variants[1].MimeType == "image/webp" && variants[1].Width == 16 && variants[1].Height == 8
+	})).Return(nil).Once()
+
+	err := imageService.ProcessMedia(request.ProcessMediaRequest{MediaID: 3})
+
+	assert.Nil(t, err)
+	mediaRepoMock.AssertExpectations(t)
+	body, info, err := store.Get(context.Background(), "variants/3/webp.webp")
+	assert.Nil(t, err)
+	assert.Equal(t, "image/webp", info.ContentType)
+	decoded, err := webp.Decode(body)
+	assert.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 8), decoded.Bounds())
+}
+
+func TestImage_ProcessMediaShouldSkipMediaThatIsNotAReadyImage(t *testing.T) {
+	imageService, _ := newImageService(t)
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+	mediaRepoMock.On("FindByID", uint(4)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	assert.Nil(t, imageService.ProcessMedia(request.ProcessMediaRequest{MediaID: 3}))
+	assert.Nil(t, imageService.ProcessMedia(request.ProcessMediaRequest{MediaID: 4}))
+	mediaRepoMock.AssertNotCalled(t, "StoreVariants", mock.Anything)
+}
+
+func TestImage_ResizeMediaShouldRenderSignedSizeOnce(t *testing.T) {
+	imageService, store := newImageService(t)
+	mediaRepoMock.On("FindByID", uint(3)).Return(readyImage(), nil).Times(3)
+	req := request.ResizeMediaRequest{Width: 20, Height: 20, Fit: "crop", Format: "png"}
+
+	resizeUrl, err := imageService.ResizeUrl(7, 3, req)
+	assert.Nil(t, err)
+	u, _ := url.Parse(resizeUrl.Url)
+	assert.Equal(t, "/api/v1/media/3/resize", u.Path)
+	signed := request.SignedResizeMediaRequest{ResizeMediaRequest: req, Sig: u.Query().Get("sig")}
+
+	for i := 0; i < 2; i++ {
+		body, info, err := imageService.ResizeMedia(3, signed, context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "image/png", info.ContentType)
+		data, _ := io.ReadAll(body)
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		assert.Nil(t, err)
+		assert.Equal(t, 20, config.Width)
+	}
+	mediaRepoMock.AssertNumberOfCalls(t, "FindByID", 3)
+	_, err = store.Stat(context.Background(), "variants/3/20x20-crop.png")
+	assert.Nil(t, err)
+}
+
+func TestImage_ResizeUrlOfPrivateImageShouldExpire(t *testing.T) {
+	imageService, store := newImageService(t)
+	media := readyImage()
+	media.Visibility = consttype.MEDIA_PRIVATE
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil)
+	req := request.ResizeMediaRequest{Width: 20, Height: 20, Fit: "crop", Format: "png"}
+
+	resizeUrl, err := imageService.ResizeUrl(7, 3, req)
+	assert.Nil(t, err)
+	assert.NotNil(t, resizeUrl.ExpiresAt)
+	u, _ := url.Parse(resizeUrl.Url)
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	assert.Equal(t, resizeUrl.ExpiresAt.Unix(), expires)
+
+	signed := request.SignedResizeMediaRequest{ResizeMediaRequest: req, Expires: expires, Sig: u.Query().Get("sig")}
+	_, _, err = imageService.ResizeMedia(3, signed, context.Background())
+	assert.Nil(t, err)
+	_, err = store.Stat(context.Background(), "private/variants/3/20x20-crop.png")
+	assert.Nil(t, err)
+
+	signed.Expires = 0
+	_, _, err = imageService.ResizeMedia(3, signed, context.Background())
+	assert.ErrorIs(t, err, ErrInvalidResizeSignature)
+
+	past := time.Now().Add(-time.Minute).Unix()
+	signed = request.SignedResizeMediaRequest{ResizeMediaRequest: req, Expires: past, Sig: imageService.resizeSignature(3, req, past)}
+	_, _, err = imageService.ResizeMedia(3, signed, context.Background())
+	assert.ErrorIs(t, err, ErrResizeUrlExpired)
+}
+
+func TestImage_ResizeMediaShouldRejectChangedParameters(t *testing.T) {
+	imageService, _ := newImageService(t)
+	mediaRepoMock.On("FindByID", uint(3)).Return(readyImage(), nil).Once()
+	req := request.ResizeMediaRequest{Width: 20, Height: 20, Fit: "crop", Format: "png"}
+	resizeUrl, _ := imageService.ResizeUrl(7, 3, req)
+	u, _ := url.Parse(resizeUrl.Url)
+
+	req.Width = 2000
+	_, _, err := imageService.ResizeMedia(3, request.SignedResizeMediaRequest{ResizeMediaRequest: req, Sig: u.Query().Get("sig")}, context.Background())
+
+	assert.ErrorIs(t, err, ErrInvalidResizeSignature)
+}
+
+func TestImage_ResizeUrlShouldHideMediaOfOtherUsers(t *testing.T) {
+	imageService, _ := newImageService(t)
+	mediaRepoMock.On("FindByID", uint(3)).Return(readyImage(), nil).Once()
+
+	_, err := imageService.ResizeUrl(8, 3, request.ResizeMediaRequest{Width: 20, Height: 20, Fit: "crop", Format: "png"})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
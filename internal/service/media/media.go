@@ -2,56 +2,489 @@ package media
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/felixlambertv/go-cleanplate/config"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
-	"github.com/felixlambertv/go-cleanplate/pkg/utils"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
 	"github.com/getsentry/sentry-go"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// uploadUrlExpiry is how long clients have to start an upload.
+const uploadUrlExpiry = time.Minute * 15
+
+var (
+	ErrUploadIncomplete = errors.New("media upload is not complete")
+	ErrUploadMismatch   = errors.New("uploaded media does not match")
+	ErrMediaInUse       = errors.New("media is in use")
 )
 
 type MediaService struct {
-	cfg *config.Config
+	l         logger.Interface
+	cfg       *config.Config
+	store     storage.BlobStore
+	validator *Validator
+	mediaRepo repository.IMediaRepo
+	ob        service.IOutboxService
 }
 
-func NewMediaService(cfg *config.Config) *MediaService {
-	return &MediaService{cfg: cfg}
+func NewMediaService(l logger.Interface, cfg *config.Config, store storage.BlobStore, validator *Validator, mediaRepo repository.IMediaRepo, ob service.IOutboxService) *MediaService {
+	return &MediaService{l: l, cfg: cfg, store: store, validator: validator, mediaRepo: mediaRepo, ob: ob}
 }
 
-func (ms *MediaService) UploadMedia(req request.MediaUploadRequest, ctx context.Context) (string, error) {
-	extension := "." + req.Filename[strings.LastIndex(req.Filename, ".")+1:]
-	imageDir := utils.GetExtensionType(extension)
-	imageName := uuid.Must(uuid.NewRandom()).String() + extension
-	bucket := ms.cfg.S3.Bucket
-	fullImagePath := imageDir + "/" + imageName
+// UploadMedia streams a file sent through the app to quarantine and queues
+// its scan. The file is stored as the type detected from its content, without
+// metadata, and its checksum is computed as it passes through.
+func (ms *MediaService) UploadMedia(userID uint, req request.MediaUploadRequest, ctx context.Context) (*response.MediaResponse, error) {
+	content, err := ms.validator.Open(req.File, ms.validator.DeclaredType(req.Filename, req.ContentType))
+	if err != nil {
+		return nil, err
+	}
 
-	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String(ms.cfg.AWS.Region)}))
-	client := s3manager.NewUploader(sess)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
 
-	fileReader, err := utils.ReadRequestFile(req.File)
+	visibility := mediaVisibility(req.Visibility)
+	key := mediaKey(visibility, content.ContentType)
+	metadata, err := ms.putContent(ctx, key, content)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, time.Minute*1)
-	defer cancel()
+	media := &model.Media{
+		UserID:      userID,
+		StorageKey:  key,
+		Filename:    req.Filename,
+		MimeType:    content.ContentType,
+		Status:      consttype.MEDIA_PENDING_SCAN,
+		Visibility:  visibility,
+		Collectable: true,
+	}
+	setMetadata(media, metadata)
 
-	res, err := client.UploadWithContext(ctx, &s3manager.UploadInput{
-		Body:   fileReader,
-		Bucket: aws.String(bucket),
-		Key:    aws.String(fullImagePath),
-		ACL:    aws.String("public-read"),
+	media, err = ms.scanMedia(func(repo repository.IMediaRepo) (*model.Media, error) {
+		return repo.Store(media)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ms.mediaResponse(media, ctx)
+}
+
+// putContent streams content to storage at key and reads its metadata on the
+// way, the content is never held in memory as a whole. Content going past its
+// size limit aborts the upload with ErrMediaTooLarge.
+func (ms *MediaService) putContent(ctx context.Context, key string, content *validatedContent) (*mediaMetadata, error) {
+	type result struct {
+		metadata *mediaMetadata
+		err      error
+	}
+
+	pr, pw := io.Pipe()
+	read := make(chan result, 1)
+	go func() {
+		metadata, err := readMetadata(pr, content.ContentType)
+		// unblock the upload should reading stop early
+		_ = pr.CloseWithError(err)
+		read <- result{metadata: metadata, err: err}
+	}()
+
+	_, err := ms.store.Put(ctx, key, io.TeeReader(content.Body, pw), storage.PutOptions{
+		ContentType: content.ContentType,
+	})
+	_ = pw.CloseWithError(err)
+	res := <-read
+
+	if content.TooLarge() {
+		return nil, ErrMediaTooLarge
+	}
 	if err != nil {
 		sentry.CaptureException(err)
-		return "", fmt.Errorf("upload : %w", err)
+		return nil, fmt.Errorf("upload : %w", err)
+	}
+	if res.err != nil {
+		return nil, fmt.Errorf("read : %w", res.err)
+	}
+	return res.metadata, nil
+}
+
+func (ms *MediaService) GetMediaList(userID uint, filter request.MediaFilter, p model.Pagination, ctx context.Context) (*model.Pagination, error) {
+	result, err := ms.mediaRepo.FindAll(userID, filter, p)
+	if err != nil {
+		return nil, err
+	}
+
+	media, _ := result.Data.([]model.Media)
+	responses := make([]response.MediaResponse, 0, len(media))
+	for i := range media {
+		res, err := ms.mediaResponse(&media[i], ctx)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *res)
+	}
+	result.Data = responses
+
+	return result, nil
+}
+
+// GetMedia returns a media of userID, the URLs of a private media are signed
+// again on every call.
+func (ms *MediaService) GetMedia(userID uint, id uint, ctx context.Context) (*response.MediaResponse, error) {
+	media, err := ms.findOwnedMedia(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ms.mediaResponse(media, ctx)
+}
+
+// DownloadMedia returns the content of a ready media of userID, so private
+// media can be downloaded through the app without a presigned URL.
+func (ms *MediaService) DownloadMedia(userID uint, id uint, ctx context.Context) (io.ReadCloser, *response.MediaDownload, error) {
+	media, err := ms.findOwnedMedia(userID, id)
+	if err != nil {
+		return nil, nil, err
 	}
+	switch media.Status {
+	case consttype.MEDIA_PENDING:
+		return nil, nil, ErrUploadIncomplete
+	case consttype.MEDIA_PENDING_SCAN:
+		return nil, nil, ErrMediaNotScanned
+	case consttype.MEDIA_INFECTED:
+		return nil, nil, ErrMediaInfected
+	}
+
+	body, info, err := ms.store.Get(ctx, media.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, &response.MediaDownload{
+		Filename: media.Filename,
+		MimeType: media.MimeType,
+		Size:     info.Size,
+	}, nil
+}
+
+// DeleteMedia removes the record before the blobs, a blob left behind by a
+// failed delete is only unreachable storage. Variants, resized copies and the
+// chunks of an unfinished upload go with the media.
+func (ms *MediaService) DeleteMedia(userID uint, id uint, ctx context.Context) error {
+	media, err := ms.findOwnedMedia(userID, id)
+	if err != nil {
+		return err
+	}
+	if media.RefCount > 0 {
+		return ErrMediaInUse
+	}
+
+	err = ms.mediaRepo.Delete(media)
+	if err != nil {
+		return err
+	}
+
+	return ms.deleteBlobs(media, ctx)
+}
+
+// deleteBlobs deletes the file of a deleted media with its variants and the
+// chunks of its upload.
+func (ms *MediaService) deleteBlobs(media *model.Media, ctx context.Context) error {
+	for _, prefix := range []string{variantPrefix(media), uploadPrefix(media.ID)} {
+		derived, err := ms.store.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		for _, blob := range derived {
+			err = ms.store.Delete(ctx, blob.Key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return ms.store.Delete(ctx, media.StorageKey)
+}
+
+// CreateUpload registers a pending media and returns a URL the client uploads
+// the file to directly, so large files never pass through the app.
+func (ms *MediaService) CreateUpload(userID uint, req request.CreateMediaUploadRequest, ctx context.Context) (*response.MediaUploadResponse, error) {
+	contentType, err := ms.validator.CheckDeclared(req.Filename, req.ContentType, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility := mediaVisibility(req.Visibility)
+	media, err := ms.mediaRepo.Store(&model.Media{
+		UserID:      userID,
+		StorageKey:  mediaKey(visibility, contentType),
+		Filename:    req.Filename,
+		MimeType:    contentType,
+		Size:        req.Size,
+		Status:      consttype.MEDIA_PENDING,
+		Visibility:  visibility,
+		Collectable: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := ms.store.PresignPut(ctx, directUploadKey(media), storage.PresignPutOptions{
+		ContentType: media.MimeType,
+		Size:        media.Size,
+	}, uploadUrlExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MediaUploadResponse{
+		ID:            media.ID,
+		UploadMethod:  upload.Method,
+		UploadUrl:     upload.URL,
+		UploadHeaders: upload.Header,
+		ExpiresAt:     time.Now().Add(uploadUrlExpiry).UTC(),
+	}, nil
+}
+
+// CompleteUpload queues the scan of the media once the uploaded blob matches
+// what was declared and its content passes validation. The validated content,
+// without the metadata of images, is stored at the key of the media, which the
+// upload URL cannot write to, and the uploaded blob is deleted. A blob that
+// does not pass is deleted too. Completing a media again returns it unchanged.
+func (ms *MediaService) CompleteUpload(userID uint, id uint, ctx context.Context) (*response.MediaResponse, error) {
+	media, err := ms.findOwnedMedia(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if media.Status != consttype.MEDIA_PENDING {
+		return ms.mediaResponse(media, ctx)
+	}
+
+	uploadKey := directUploadKey(media)
+	info, err := ms.store.Stat(ctx, uploadKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, ErrUploadIncomplete
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size != media.Size || !strings.EqualFold(info.ContentType, media.MimeType) {
+		err = ms.store.Delete(ctx, uploadKey)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrUploadMismatch
+	}
+
+	metadata, err := ms.storeUpload(media, uploadKey, ctx)
+	if IsRejected(err) {
+		for _, key := range []string{uploadKey, media.StorageKey} {
+			deleteErr := ms.store.Delete(ctx, key)
+			if deleteErr != nil {
+				return nil, deleteErr
+			}
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setMetadata(media, metadata)
+	media.Status = consttype.MEDIA_PENDING_SCAN
+	media, err = ms.scanMedia(func(repo repository.IMediaRepo) (*model.Media, error) {
+		return repo.Update(media)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A blob left behind is only unreachable storage, deleted with the media.
+	err = ms.store.Delete(ctx, uploadKey)
+	if err != nil {
+		sentry.CaptureException(err)
+	}
+
+	return ms.mediaResponse(media, ctx)
+}
+
+// storeUpload validates the uploaded blob and stores its content at the key of
+// media, reading its metadata on the way.
+func (ms *MediaService) storeUpload(media *model.Media, uploadKey string, ctx context.Context) (*mediaMetadata, error) {
+	body, _, err := ms.store.Get(ctx, uploadKey)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	content, err := ms.validator.Open(body, media.MimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	return ms.putContent(ctx, media.StorageKey, content)
+}
+
+// directUploadKey is where clients upload the file of a media through a
+// presigned URL. It is apart from the key of the media, so the URL cannot
+// replace the file once it was validated.
+func directUploadKey(media *model.Media) string {
+	return uploadPrefix(media.ID) + "direct"
+}
+
+// IsRejected reports whether err is the validator rejecting the content of an
+// upload.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrMediaTypeNotAllowed) || errors.Is(err, ErrMediaTooLarge) ||
+		errors.Is(err, ErrMediaTypeMismatch) || errors.Is(err, ErrMediaContentInvalid)
+}
+
+func (ms *MediaService) findOwnedMedia(userID uint, id uint) (*model.Media, error) {
+	return findOwnedMedia(ms.mediaRepo, userID, id)
+}
+
+// scanMedia saves a media in quarantine with save and queues its scan in the
+// same transaction, so a media never waits for a scan that was not queued.
+// It is not served until the scan finds it clean.
+func (ms *MediaService) scanMedia(save func(repo repository.IMediaRepo) (*model.Media, error)) (*model.Media, error) {
+	var media *model.Media
+	err := ms.mediaRepo.Transaction(func(tx *gorm.DB) error {
+		var err error
+		media, err = save(ms.mediaRepo.WithTrx(tx))
+		if err != nil {
+			return err
+		}
+		return ms.ob.WithTrx(tx).Enqueue(request.ScanMediaRequest{MediaID: media.ID}.ToString(), consttype.SCAN_MEDIA)
+	})
+	if err != nil {
+		sentry.CaptureException(err)
+		return nil, err
+	}
+	return media, nil
+}
+
+// findOwnedMedia returns gorm.ErrRecordNotFound for media of other users, so
+// their IDs cannot be probed.
+func findOwnedMedia(mediaRepo repository.IMediaRepo, userID uint, id uint) (*model.Media, error) {
+	media, err := mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if media.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return media, nil
+}
+
+// mediaResponse links to the blobs of media, a private media through
+// presigned URLs that expire. Media are only linked once they are ready, the
+// blobs of the others are in quarantine or gone.
+func (ms *MediaService) mediaResponse(media *model.Media, ctx context.Context) (*response.MediaResponse, error) {
+	var expiresAt *time.Time
+	blobUrl := func(key string) (string, error) { return ms.store.URL(key), nil }
+	switch {
+	case media.Status != consttype.MEDIA_READY:
+		blobUrl = func(key string) (string, error) { return "", nil }
+	case !isPublic(media):
+		expiry := privateUrlExpiry(ms.cfg)
+		at := time.Now().Add(expiry).UTC()
+		expiresAt = &at
+		blobUrl = func(key string) (string, error) { return ms.store.PresignGet(ctx, key, expiry) }
+	}
+
+	variants := make([]response.MediaVariantResponse, 0, len(media.Variants))
+	for _, variant := range media.Variants {
+		url, err := blobUrl(variant.StorageKey)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, response.MediaVariantResponse{
+			Name:     variant.Name,
+			Url:      url,
+			MimeType: variant.MimeType,
+			Width:    variant.Width,
+			Height:   variant.Height,
+			Size:     variant.Size,
+		})
+	}
+
+	url, err := blobUrl(media.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MediaResponse{
+		ID:           media.ID,
+		UploadedUrl:  url,
+		Filename:     media.Filename,
+		MimeType:     media.MimeType,
+		Size:         media.Size,
+		Checksum:     media.Checksum,
+		Width:        media.Width,
+		Height:       media.Height,
+		Duration:     media.Duration,
+		Status:       media.Status,
+		Visibility:   mediaVisibility(string(media.Visibility)),
+		UrlExpiresAt: expiresAt,
+		Variants:     variants,
+		CreatedAt:    media.CreatedAt,
+	}, nil
+}
+
+// mediaVisibility returns the visibility asked for, media are public unless
+// they are private.
+func mediaVisibility(visibility string) consttype.MediaVisibility {
+	if consttype.MediaVisibility(visibility) == consttype.MEDIA_PRIVATE {
+		return consttype.MEDIA_PRIVATE
+	}
+	return consttype.MEDIA_PUBLIC
+}
+
+func isPublic(media *model.Media) bool {
+	return mediaVisibility(string(media.Visibility)) == consttype.MEDIA_PUBLIC
+}
+
+// visibilityPrefix keeps the blobs of private media apart, so the bucket and a
+// CDN in front of it can refuse unsigned requests for them by prefix.
+func visibilityPrefix(visibility consttype.MediaVisibility) string {
+	if visibility == consttype.MEDIA_PRIVATE {
+		return "private/"
+	}
+	return ""
+}
+
+// mediaKey returns a new key in quarantine for the blob of a media, its scan
+// moves it out.
+func mediaKey(visibility consttype.MediaVisibility, contentType string) string {
+	return quarantinePrefix + visibilityPrefix(visibility) + storageKey(contentType, uuid.Must(uuid.NewRandom()).String())
+}
+
+// privateUrlExpiry is how long the URLs of private media last, 15 minutes when
+// it is not configured.
+func privateUrlExpiry(cfg *config.Config) time.Duration {
+	if cfg.Media.PrivateUrlExpiry <= 0 {
+		return time.Minute * 15
+	}
+	return time.Duration(cfg.Media.PrivateUrlExpiry) * time.Second
+}
 
-	return res.Location, nil
+func setMetadata(media *model.Media, metadata *mediaMetadata) {
+	media.Size = metadata.Size
+	media.Checksum = metadata.Checksum
+	media.Width = metadata.Width
+	media.Height = metadata.Height
+	media.Duration = metadata.Duration
 }
@@ -0,0 +1,449 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var cfg = &config.Config{}
+
+var mediaRepoMock = new(mocks.IMediaRepo)
+var outboxServiceMock = new(mocks.IOutboxService)
+
+func newMediaService() (*MediaService, *storage.MemoryStore) {
+	mediaRepoMock.ExpectedCalls = nil
+	mediaRepoMock.Calls = nil
+	outboxServiceMock.ExpectedCalls = nil
+	outboxServiceMock.Calls = nil
+	mediaRepoMock.On("Transaction", mock.Anything).Return(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	}).Maybe()
+	mediaRepoMock.On("WithTrx", mock.Anything).Return(mediaRepoMock).Maybe()
+	outboxServiceMock.On("WithTrx", mock.Anything).Return(outboxServiceMock).Maybe()
+
+	validator, _ := NewValidator(map[string]string{"image/png": "600KB", "image/jpeg": "600KB", "video/mp4": "30MB"})
+	store := storage.NewMemoryStore(storage.NewURLSigner("https://api.test.com/api/v1/storage", "secret"))
+	return NewMediaService(logger.NewLogger("error"), cfg, store, validator, mediaRepoMock, outboxServiceMock), store
+}
+
+func pngBytes(width int, height int) string {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.String()
+}
+
+func mp4Box(boxType string, payload []byte) []byte {
+	box := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(box, uint32(8+len(payload)))
+	copy(box[4:], boxType)
+	return append(box, payload...)
+}
+
+// mp4Bytes builds a file with the moov box after the media data, like files
+// recorded by phones.
+func mp4Bytes(timescale uint32, duration uint32) string {
+	mvhd := make([]byte, 20)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], duration)
+
+	var file []byte
+	file = append(file, mp4Box("ftyp", []byte("isom0000"))...)
+	file = append(file, mp4Box("mdat", make([]byte, 64))...)
+	file = append(file, mp4Box("moov", mp4Box("mvhd", mvhd))...)
+	return string(file)
+}
+
+func TestMedia_UploadMediaShouldStoreFileAndRecord(t *testing.T) {
+	mediaService, store := newMediaService()
+	content := pngBytes(4, 3)
+	mediaRepoMock.On("Store", mock.MatchedBy(func(m *model.Media) bool {
+		return m.UserID == 7 && m.Status == consttype.MEDIA_PENDING_SCAN && m.MimeType == "image/png" &&
+			m.Size == int64(len(content)) && *m.Width == 4 && *m.Height == 3 && len(m.Checksum) == 64 && m.Collectable
+	})).Return(func(m *model.Media) *model.Media {
+		m.ID = 3
+		return m
+	}, nil).Once()
+	outboxServiceMock.On("Enqueue", request.ScanMediaRequest{MediaID: 3}.ToString(), consttype.SCAN_MEDIA).Return(nil).Once()
+
+	media, err := mediaService.UploadMedia(7, request.MediaUploadRequest{
+		Filename:    "photo.png",
+		ContentType: "image/png",
+		File:        strings.NewReader(content),
+	}, context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), media.ID)
+	outboxServiceMock.AssertExpectations(t)
+	blobs, _ := store.List(context.Background(), "quarantine/image/")
+	assert.Len(t, blobs, 1)
+	assert.True(t, strings.HasSuffix(blobs[0].Key, ".png"))
+	assert.Equal(t, "image/png", blobs[0].ContentType)
+
+	body, _, err := store.Get(context.Background(), blobs[0].Key)
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, content, string(data))
+
+	assert.Equal(t, consttype.MEDIA_PENDING_SCAN, media.Status)
+	assert.Empty(t, media.UploadedUrl)
+}
+
+func TestMedia_UploadMediaShouldRejectContentOfAnotherType(t *testing.T) {
+	mediaService, store := newMediaService()
+
+	_, err := mediaService.UploadMedia(7, request.MediaUploadRequest{
+		Filename:    "photo.jpg",
+		ContentType: "image/jpeg",
+		File:        strings.NewReader(pngBytes(4, 3)),
+	}, context.Background())
+
+	assert.ErrorIs(t, err, ErrMediaTypeMismatch)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+	mediaRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestMedia_UploadMediaShouldDetectTypeWithoutExtension(t *testing.T) {
+	mediaService, store := newMediaService()
+	mediaRepoMock.On("Store", mock.MatchedBy(func(m *model.Media) bool {
+		return m.MimeType == "image/png" && strings.HasSuffix(m.StorageKey, ".png")
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SCAN_MEDIA).Return(nil).Once()
+
+	_, err := mediaService.UploadMedia(7, request.MediaUploadRequest{
+		Filename:    "scenarioImage",
+		ContentType: "application/octet-stream",
+		File:        strings.NewReader(pngBytes(4, 3)),
+	}, context.Background())
+
+	assert.Nil(t, err)
+	blobs, _ := store.List(context.Background(), "quarantine/image/")
+	assert.Len(t, blobs, 1)
+	assert.Equal(t, "image/png", blobs[0].ContentType)
+}
+
+func TestMedia_UploadMediaShouldStreamVideoWithMetadata(t *testing.T) {
+	mediaService, store := newMediaService()
+	content := mp4Bytes(1000, 12500)
+	mediaRepoMock.On("Store", mock.MatchedBy(func(m *model.Media) bool {
+		return m.MimeType == "video/mp4" && m.Size == int64(len(content)) && *m.Duration == 12.5 && len(m.Checksum) == 64
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SCAN_MEDIA).Return(nil).Once()
+
+	_, err := mediaService.UploadMedia(7, request.MediaUploadRequest{
+		Filename:    "clip.mp4",
+		ContentType: "video/mp4",
+		File:        strings.NewReader(content),
+	}, context.Background())
+
+	assert.Nil(t, err)
+	mediaRepoMock.AssertExpectations(t)
+	blobs, _ := store.List(context.Background(), "quarantine/video/")
+	assert.Len(t, blobs, 1)
+	assert.Equal(t, int64(len(content)), blobs[0].Size)
+}
+
+func TestMedia_UploadMediaShouldAbortPastTheLimit(t *testing.T) {
+	mediaService, store := newMediaService()
+	mediaService.validator = newValidator(t)
+
+	_, err := mediaService.UploadMedia(7, request.MediaUploadRequest{
+		Filename:    "clip.mp4",
+		ContentType: "video/mp4",
+		File:        strings.NewReader(mp4Bytes(1000, 10) + strings.Repeat("\x00", 1<<10)),
+	}, context.Background())
+
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+	mediaRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestMedia_UploadMediaShouldKeepPrivateMediaBehindExpiringUrls(t *testing.T) {
+	mediaService, store := newMediaService()
+	mediaRepoMock.On("Store", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Visibility == consttype.MEDIA_PRIVATE && strings.HasPrefix(m.StorageKey, "quarantine/private/image/")
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SCAN_MEDIA).Return(nil).Once()
+
+	media, err := mediaService.UploadMedia(7, request.MediaUploadRequest{
+		Filename:    "scan.png",
+		ContentType: "image/png",
+		Visibility:  "private",
+		File:        strings.NewReader(pngBytes(4, 3)),
+	}, context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, consttype.MEDIA_PRIVATE, media.Visibility)
+	blobs, _ := store.List(context.Background(), "quarantine/private/")
+	assert.Len(t, blobs, 1)
+
+	media, err = mediaService.mediaResponse(&model.Media{
+		StorageKey: "private/image/scan.png",
+		Status:     consttype.MEDIA_READY,
+		Visibility: consttype.MEDIA_PRIVATE,
+	}, context.Background())
+
+	assert.Nil(t, err)
+	assert.NotNil(t, media.UrlExpiresAt)
+	u, _ := url.Parse(media.UploadedUrl)
+	assert.Equal(t, strconv.FormatInt(media.UrlExpiresAt.Unix(), 10), u.Query().Get("expires"))
+}
+
+func TestMedia_DownloadMediaShouldStreamReadyMediaOfOwner(t *testing.T) {
+	mediaService, store := newMediaService()
+	_, _ = store.Put(context.Background(), "image/upload.png", strings.NewReader(pngBytes(4, 3)), storage.PutOptions{ContentType: "image/png"})
+	media := pendingMedia()
+	media.Status = consttype.MEDIA_READY
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Twice()
+
+	body, download, err := mediaService.DownloadMedia(7, 3, context.Background())
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, pngBytes(4, 3), string(data))
+	assert.Equal(t, "holiday.png", download.Filename)
+	assert.Equal(t, int64(len(data)), download.Size)
+
+	_, _, err = mediaService.DownloadMedia(8, 3, context.Background())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	mediaRepoMock.On("FindByID", uint(4)).Return(pendingMedia(), nil).Once()
+	_, _, err = mediaService.DownloadMedia(7, 4, context.Background())
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+}
+
+func TestMedia_ReadMetadataShouldReadMp4Duration(t *testing.T) {
+	metadata, err := readMetadata(strings.NewReader(mp4Bytes(1000, 12500)), "video/mp4")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 12.5, *metadata.Duration)
+	assert.Nil(t, metadata.Width)
+}
+
+func TestMedia_ReadMetadataShouldIgnoreUnparsableContent(t *testing.T) {
+	metadata, err := readMetadata(strings.NewReader("not an image"), "image/png")
+
+	assert.Nil(t, err)
+	assert.Nil(t, metadata.Width)
+	assert.Equal(t, int64(12), metadata.Size)
+	assert.Equal(t, "5464533c9647b67eb320c40ccc5959537c09102ae75388f6a7675b433e745c9d", metadata.Checksum)
+}
+
+func pendingMedia() *model.Media {
+	return &model.Media{
+		ID:         3,
+		UserID:     7,
+		StorageKey: "image/upload.png",
+		Filename:   "holiday.png",
+		MimeType:   "image/png",
+		Size:       int64(len(pngBytes(4, 3))),
+		Status:     consttype.MEDIA_PENDING,
+	}
+}
+
+func TestMedia_CreateUploadShouldRejectUndeclaredType(t *testing.T) {
+	mediaService, _ := newMediaService()
+
+	_, err := mediaService.CreateUpload(7, request.CreateMediaUploadRequest{Filename: "holiday.png", ContentType: "text/html", Size: 3}, context.Background())
+	assert.ErrorIs(t, err, ErrMediaTypeNotAllowed)
+
+	_, err = mediaService.CreateUpload(7, request.CreateMediaUploadRequest{Filename: "holiday.exe", ContentType: "application/octet-stream", Size: 3}, context.Background())
+	assert.ErrorIs(t, err, ErrMediaTypeNotAllowed)
+
+	_, err = mediaService.CreateUpload(7, request.CreateMediaUploadRequest{Filename: "holiday.png", ContentType: "image/png", Size: 1 << 30}, context.Background())
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+
+	mediaRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestMedia_CreateUploadShouldReturnPresignedUpload(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("Store", mock.MatchedBy(func(m *model.Media) bool {
+		return m.UserID == 7 && m.Status == consttype.MEDIA_PENDING && m.MimeType == "image/png" &&
+			m.Size == 3 && strings.HasPrefix(m.StorageKey, "quarantine/image/") && strings.HasSuffix(m.StorageKey, ".png") && m.Collectable
+	})).Return(func(m *model.Media) *model.Media {
+		m.ID = 3
+		return m
+	}, nil).Once()
+
+	upload, err := mediaService.CreateUpload(7, request.CreateMediaUploadRequest{Filename: "Holiday.PNG", ContentType: "image/png", Size: 3}, context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), upload.ID)
+	assert.Equal(t, "PUT", upload.UploadMethod)
+	assert.Equal(t, map[string]string{"Content-Type": "image/png", "Content-Length": "3"}, upload.UploadHeaders)
+	assert.True(t, upload.ExpiresAt.After(time.Now()))
+}
+
+func TestMedia_CompleteUploadShouldQueueScan(t *testing.T) {
+	mediaService, store := newMediaService()
+	_, _ = store.Put(context.Background(), "uploads/3/direct", strings.NewReader(pngBytes(4, 3)), storage.PutOptions{ContentType: "image/png"})
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+	mediaRepoMock.On("Update", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Status == consttype.MEDIA_PENDING_SCAN && *m.Width == 4
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	outboxServiceMock.On("Enqueue", request.ScanMediaRequest{MediaID: 3}.ToString(), consttype.SCAN_MEDIA).Return(nil).Once()
+
+	media, err := mediaService.CompleteUpload(7, 3, context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, consttype.MEDIA_PENDING_SCAN, media.Status)
+	assert.Empty(t, media.UploadedUrl)
+	// The validated file is moved out of reach of the upload URL.
+	_, err = store.Stat(context.Background(), "uploads/3/direct")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+	_, err = store.Stat(context.Background(), "image/upload.png")
+	assert.Nil(t, err)
+}
+
+func TestMedia_CompleteUploadShouldKeepUploadWhenScanIsNotQueued(t *testing.T) {
+	mediaService, store := newMediaService()
+	_, _ = store.Put(context.Background(), "uploads/3/direct", strings.NewReader(pngBytes(4, 3)), storage.PutOptions{ContentType: "image/png"})
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+	mediaRepoMock.On("Update", mock.Anything).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SCAN_MEDIA).Return(errors.New("db down")).Once()
+
+	_, err := mediaService.CompleteUpload(7, 3, context.Background())
+
+	// The update is rolled back along with the scan, so the upload can be
+	// completed again.
+	assert.NotNil(t, err)
+	mediaRepoMock.AssertCalled(t, "Transaction", mock.Anything)
+	_, err = store.Stat(context.Background(), "uploads/3/direct")
+	assert.Nil(t, err)
+}
+
+func TestMedia_CompleteUploadShouldDeleteInvalidContent(t *testing.T) {
+	mediaService, store := newMediaService()
+	content := "<html><script>alert(1)</script></html>"
+	_, _ = store.Put(context.Background(), "uploads/3/direct", strings.NewReader(content), storage.PutOptions{ContentType: "image/png"})
+	media := pendingMedia()
+	media.Size = int64(len(content))
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Once()
+
+	_, err := mediaService.CompleteUpload(7, 3, context.Background())
+
+	assert.ErrorIs(t, err, ErrMediaContentInvalid)
+	_, err = store.Stat(context.Background(), "uploads/3/direct")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestMedia_CompleteUploadShouldFailWhenNotUploaded(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+
+	_, err := mediaService.CompleteUpload(7, 3, context.Background())
+
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestMedia_CompleteUploadShouldDeleteMismatchingUpload(t *testing.T) {
+	mediaService, store := newMediaService()
+	_, _ = store.Put(context.Background(), "uploads/3/direct", strings.NewReader("larger"), storage.PutOptions{ContentType: "image/png"})
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+
+	_, err := mediaService.CompleteUpload(7, 3, context.Background())
+
+	assert.ErrorIs(t, err, ErrUploadMismatch)
+	_, err = store.Stat(context.Background(), "uploads/3/direct")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestMedia_CompleteUploadShouldHideMediaOfOtherUsers(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+
+	_, err := mediaService.CompleteUpload(8, 3, context.Background())
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMedia_GetMediaShouldHideMediaOfOtherUsers(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+
+	_, err := mediaService.GetMedia(8, 3, context.Background())
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMedia_GetMediaListShouldReturnResponses(t *testing.T) {
+	mediaService, _ := newMediaService()
+	p := model.Pagination{Limit: 10, Page: 1}
+	ready := pendingMedia()
+	ready.Status = consttype.MEDIA_READY
+	mediaRepoMock.On("FindAll", uint(7), request.MediaFilter{}, p).Return(&model.Pagination{Limit: 10, Page: 1, Data: []model.Media{*ready}}, nil).Once()
+
+	result, err := mediaService.GetMediaList(7, request.MediaFilter{}, p, context.Background())
+
+	assert.Nil(t, err)
+	media := result.Data.([]response.MediaResponse)
+	assert.Len(t, media, 1)
+	assert.Equal(t, uint(3), media[0].ID)
+	assert.NotEmpty(t, media[0].UploadedUrl)
+}
+
+func TestMedia_DeleteMediaShouldRemoveRecordAndBlob(t *testing.T) {
+	mediaService, store := newMediaService()
+	_, _ = store.Put(context.Background(), "image/upload.png", strings.NewReader("png"), storage.PutOptions{})
+	_, _ = store.Put(context.Background(), "variants/3/thumbnail.jpg", strings.NewReader("jpg"), storage.PutOptions{})
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+	mediaRepoMock.On("Delete", mock.Anything).Return(nil).Once()
+
+	err := mediaService.DeleteMedia(7, 3, context.Background())
+
+	assert.Nil(t, err)
+	_, err = store.Stat(context.Background(), "image/upload.png")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+	_, err = store.Stat(context.Background(), "variants/3/thumbnail.jpg")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestMedia_DeleteMediaShouldNotDeleteMediaOfOtherUsers(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(pendingMedia(), nil).Once()
+
+	err := mediaService.DeleteMedia(8, 3, context.Background())
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mediaRepoMock.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestMedia_DeleteMediaShouldKeepMediaInUse(t *testing.T) {
+	mediaService, store := newMediaService()
+	_, _ = store.Put(context.Background(), "image/upload.png", strings.NewReader("png"), storage.PutOptions{})
+	media := pendingMedia()
+	media.RefCount = 1
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Once()
+
+	err := mediaService.DeleteMedia(7, 3, context.Background())
+
+	assert.ErrorIs(t, err, ErrMediaInUse)
+	mediaRepoMock.AssertNotCalled(t, "Delete", mock.Anything)
+	_, err = store.Stat(context.Background(), "image/upload.png")
+	assert.Nil(t, err)
+}
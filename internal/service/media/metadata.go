@@ -0,0 +1,130 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+)
+
+// mediaMetadata is read from the content of a media.
+type mediaMetadata struct {
+	Size     int64
+	Checksum string
+	Width    *int
+	Height   *int
+	Duration *float64
+}
+
+// readMetadata reads r to the end. Dimensions and duration are left nil when
+// the content cannot be parsed as mimeType.
+func readMetadata(r io.Reader, mimeType string) (*mediaMetadata, error) {
+	hash := sha256.New()
+	counter := &countingWriter{}
+	tee := io.TeeReader(r, io.MultiWriter(hash, counter))
+
+	metadata := &mediaMetadata{}
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		if cfg, _, err := image.DecodeConfig(tee); err == nil {
+			metadata.Width = &cfg.Width
+			metadata.Height = &cfg.Height
+		}
+	case mimeType == "video/mp4":
+		if duration, ok := mp4Duration(tee); ok {
+			metadata.Duration = &duration
+		}
+	}
+
+	_, err := io.Copy(io.Discard, tee)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Size = counter.n
+	metadata.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return metadata, nil
+}
+
+// mp4Duration reads the duration in seconds from the movie header box. The
+// moov box may come after the media data, so boxes are walked in order.
+func mp4Duration(r io.Reader) (float64, bool) {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return 0, false
+		}
+
+		size := uint64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:])
+		headerSize := uint64(8)
+		if size == 1 {
+			if _, err := io.ReadFull(r, header); err != nil {
+				return 0, false
+			}
+			size = binary.BigEndian.Uint64(header)
+			headerSize = 16
+		}
+		if size != 0 && size < headerSize {
+			return 0, false
+		}
+
+		switch boxType {
+		case "moov":
+			// Children follow the header, keep walking inside the box.
+			continue
+		case "mvhd":
+			return mvhdDuration(r)
+		}
+
+		if size == 0 {
+			return 0, false
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(size-headerSize)); err != nil {
+			return 0, false
+		}
+	}
+}
+
+func mvhdDuration(r io.Reader) (float64, bool) {
+	versionAndFlags := make([]byte, 4)
+	if _, err := io.ReadFull(r, versionAndFlags); err != nil {
+		return 0, false
+	}
+
+	var timescale uint32
+	var duration uint64
+	if versionAndFlags[0] == 1 {
+		fields := make([]byte, 28)
+		if _, err := io.ReadFull(r, fields); err != nil {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(fields[16:20])
+		duration = binary.BigEndian.Uint64(fields[20:28])
+	} else {
+		fields := make([]byte, 16)
+		if _, err := io.ReadFull(r, fields); err != nil {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(fields[8:12])
+		duration = uint64(binary.BigEndian.Uint32(fields[12:16]))
+	}
+
+	if timescale == 0 {
+		return 0, false
+	}
+	return float64(duration) / float64(timescale), true
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,259 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match")
+	ErrUploadTooLong        = errors.New("upload is longer than declared")
+	ErrUploadNotPending     = errors.New("media upload is already finished")
+)
+
+// CreateResumableUpload registers a pending media that is then sent in chunks
+// with AppendUpload, so an upload cut off by the network resumes where it
+// stopped instead of starting over.
+func (ms *MediaService) CreateResumableUpload(userID uint, req request.CreateMediaUploadRequest) (*response.ResumableUploadResponse, error) {
+	contentType, err := ms.validator.CheckDeclared(req.Filename, req.ContentType, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility := mediaVisibility(req.Visibility)
+	media, err := ms.mediaRepo.Store(&model.Media{
+		UserID:      userID,
+		StorageKey:  mediaKey(visibility, contentType),
+		Filename:    req.Filename,
+		MimeType:    contentType,
+		Size:        req.Size,
+		Status:      consttype.MEDIA_PENDING,
+		Visibility:  visibility,
+		Collectable: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ms.resumableResponse(media), nil
+}
+
+// GetResumableUpload returns how much of an upload has been received.
+func (ms *MediaService) GetResumableUpload(userID uint, id uint) (*response.ResumableUploadResponse, error) {
+	media, err := ms.findOwnedMedia(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ms.resumableResponse(media), nil
+}
+
+// AppendUpload stores chunk as the part of the upload starting at offset,
+// which has to be the offset received so far. Once the whole upload is in,
+// the chunks are validated and stored as the media, which waits for its scan.
+// Sending the last offset again with an empty chunk retries a completion
+// that failed.
+func (ms *MediaService) AppendUpload(userID uint, id uint, offset int64, chunk io.Reader, ctx context.Context) (*response.ResumableUploadResponse, error) {
+	media, err := ms.findOwnedMedia(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if media.Status != consttype.MEDIA_PENDING {
+		return nil, ErrUploadNotPending
+	}
+	if offset != media.UploadOffset {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	if media.UploadOffset < media.Size {
+		size, err := ms.putChunk(media, chunk, ctx)
+		if err != nil {
+			return nil, err
+		}
+		media.UploadOffset += size
+	}
+
+	if media.UploadOffset == media.Size {
+		err = ms.assembleUpload(media, ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ms.resumableResponse(media), nil
+}
+
+// putChunk stores chunk as its own blob and records it. A chunk longer than
+// what is left of the upload is refused, as is one another request already
+// stored the same offset for.
+func (ms *MediaService) putChunk(media *model.Media, chunk io.Reader, ctx context.Context) (int64, error) {
+	key := fmt.Sprintf("%s%020d-%s", uploadPrefix(media.ID), media.UploadOffset, uuid.Must(uuid.NewRandom()).String())
+	body := &sizeLimitReader{r: chunk, remaining: media.Size - media.UploadOffset}
+	info, err := ms.store.Put(ctx, key, body, storage.PutOptions{ContentType: "application/octet-stream"})
+	if body.exceeded {
+		return 0, ErrUploadTooLong
+	}
+	if err != nil {
+		return 0, err
+	}
+	if info.Size == 0 {
+		return 0, ms.store.Delete(ctx, key)
+	}
+
+	appended, err := ms.mediaRepo.AppendUploadChunk(&model.MediaUploadChunk{
+		MediaID:    media.ID,
+		Offset:     media.UploadOffset,
+		Size:       info.Size,
+		StorageKey: key,
+	})
+	if err == nil && !appended {
+		err = ErrUploadOffsetMismatch
+	}
+	if err != nil {
+		deleteErr := ms.store.Delete(ctx, key)
+		if deleteErr != nil {
+			sentry.CaptureException(deleteErr)
+		}
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+// assembleUpload streams the chunks of media in order through the validator
+// to its blob in quarantine and queues its scan. Content the validator
+// rejects starts the upload over.
+func (ms *MediaService) assembleUpload(media *model.Media, ctx context.Context) error {
+	chunks, err := ms.mediaRepo.FindUploadChunks(media.ID)
+	if err != nil {
+		return err
+	}
+	next := int64(0)
+	for _, chunk := range chunks {
+		if chunk.Offset != next {
+			return ErrUploadIncomplete
+		}
+		next += chunk.Size
+	}
+	if next != media.Size {
+		return ErrUploadIncomplete
+	}
+
+	reader := &chunkReader{ctx: ctx, store: ms.store, chunks: chunks}
+	defer reader.Close()
+
+	content, err := ms.validator.Open(reader, media.MimeType)
+	var metadata *mediaMetadata
+	if err == nil {
+		metadata, err = ms.putContent(ctx, media.StorageKey, content)
+	}
+	if IsRejected(err) {
+		ms.deleteUploadChunks(media.ID, chunks, ctx)
+		media.UploadOffset = 0
+		_, updateErr := ms.mediaRepo.Update(media)
+		if updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	setMetadata(media, metadata)
+	media.Status = consttype.MEDIA_PENDING_SCAN
+	media, err = ms.scanMedia(func(repo repository.IMediaRepo) (*model.Media, error) {
+		return repo.Update(media)
+	})
+	if err != nil {
+		return err
+	}
+	ms.deleteUploadChunks(media.ID, chunks, ctx)
+	return nil
+}
+
+// deleteUploadChunks removes chunks that are no longer needed. A chunk left
+// behind is only unreachable storage, so a failure is only reported.
+func (ms *MediaService) deleteUploadChunks(mediaID uint, chunks []model.MediaUploadChunk, ctx context.Context) {
+	err := ms.mediaRepo.DeleteUploadChunks(mediaID)
+	if err != nil {
+		sentry.CaptureException(err)
+		return
+	}
+	for _, chunk := range chunks {
+		err = ms.store.Delete(ctx, chunk.StorageKey)
+		if err != nil {
+			sentry.CaptureException(err)
+		}
+	}
+}
+
+func (ms *MediaService) resumableResponse(media *model.Media) *response.ResumableUploadResponse {
+	offset := media.UploadOffset
+	if media.Status != consttype.MEDIA_PENDING {
+		offset = media.Size
+	}
+	return &response.ResumableUploadResponse{
+		ID:        media.ID,
+		UploadUrl: fmt.Sprintf("%s/api/v1/media/resumable/%d", ms.cfg.App.Url, media.ID),
+		Offset:    offset,
+		Length:    media.Size,
+	}
+}
+
+// uploadPrefix holds the chunks of a resumable upload.
+func uploadPrefix(id uint) string {
+	return fmt.Sprintf("uploads/%d/", id)
+}
+
+// chunkReader reads the chunks of an upload in order, opening one at a time.
+type chunkReader struct {
+	ctx     context.Context
+	store   storage.BlobStore
+	chunks  []model.MediaUploadChunk
+	current io.ReadCloser
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.chunks) == 0 {
+				return 0, io.EOF
+			}
+			body, _, err := c.store.Get(c.ctx, c.chunks[0].StorageKey)
+			if err != nil {
+				return 0, err
+			}
+			c.current = body
+			c.chunks = c.chunks[1:]
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			_ = c.current.Close()
+			c.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	return c.current.Close()
+}
@@ -0,0 +1,150 @@
+package media
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func uploadingMedia(offset int64) *model.Media {
+	media := pendingMedia()
+	media.UploadOffset = offset
+	return media
+}
+
+func TestResumable_CreateResumableUploadShouldReturnUploadUrl(t *testing.T) {
+	mediaService, _ := newMediaService()
+	mediaRepoMock.On("Store", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Status == consttype.MEDIA_PENDING && m.MimeType == "video/mp4" && m.Size == 2048 && m.Collectable
+	})).Return(func(m *model.Media) *model.Media {
+		m.ID = 5
+		return m
+	}, nil).Once()
+
+	upload, err := mediaService.CreateResumableUpload(7, request.CreateMediaUploadRequest{Filename: "clip.mp4", ContentType: "video/mp4", Size: 2048})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/api/v1/media/resumable/5", upload.UploadUrl)
+	assert.Equal(t, int64(0), upload.Offset)
+	assert.Equal(t, int64(2048), upload.Length)
+
+	_, err = mediaService.CreateResumableUpload(7, request.CreateMediaUploadRequest{Filename: "clip.mp4", ContentType: "video/mp4", Size: 1 << 30})
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+}
+
+func TestResumable_AppendUploadShouldAssembleChunksOnceComplete(t *testing.T) {
+	mediaService, store := newMediaService()
+	content := pngBytes(4, 3)
+	half := int64(len(content) / 2)
+
+	var chunks []model.MediaUploadChunk
+	mediaRepoMock.On("AppendUploadChunk", mock.Anything).Return(func(chunk *model.MediaUploadChunk) bool {
+		chunks = append(chunks, *chunk)
+		return true
+	}, nil)
+	mediaRepoMock.On("FindUploadChunks", uint(3)).Return(func(uint) []model.MediaUploadChunk { return chunks }, nil).Once()
+	mediaRepoMock.On("Update", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Status == consttype.MEDIA_PENDING_SCAN && *m.Width == 4 && len(m.Checksum) == 64
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	mediaRepoMock.On("DeleteUploadChunks", uint(3)).Return(nil).Once()
+	outboxServiceMock.On("Enqueue", request.ScanMediaRequest{MediaID: 3}.ToString(), consttype.SCAN_MEDIA).Return(nil).Once()
+
+	mediaRepoMock.On("FindByID", uint(3)).Return(uploadingMedia(0), nil).Once()
+	upload, err := mediaService.AppendUpload(7, 3, 0, strings.NewReader(content[:half]), context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, half, upload.Offset)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+
+	mediaRepoMock.On("FindByID", uint(3)).Return(uploadingMedia(half), nil).Once()
+	upload, err = mediaService.AppendUpload(7, 3, half, strings.NewReader(content[half:]), context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), upload.Offset)
+
+	mediaRepoMock.AssertExpectations(t)
+	assert.Len(t, chunks, 2)
+	body, _, err := store.Get(context.Background(), "image/upload.png")
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, content, string(data))
+	left, _ := store.List(context.Background(), uploadPrefix(3))
+	assert.Len(t, left, 0)
+}
+
+func TestResumable_AppendUploadShouldRejectWrongOffset(t *testing.T) {
+	mediaService, store := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(uploadingMedia(10), nil).Once()
+
+	_, err := mediaService.AppendUpload(7, 3, 0, strings.NewReader("chunk"), context.Background())
+
+	assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+}
+
+func TestResumable_AppendUploadShouldRejectChunkPastTheLength(t *testing.T) {
+	mediaService, store := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(uploadingMedia(0), nil).Once()
+
+	_, err := mediaService.AppendUpload(7, 3, 0, strings.NewReader(pngBytes(4, 3)+"trailing"), context.Background())
+
+	assert.ErrorIs(t, err, ErrUploadTooLong)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+	mediaRepoMock.AssertNotCalled(t, "AppendUploadChunk", mock.Anything)
+}
+
+func TestResumable_AppendUploadShouldDropChunkOfALostRace(t *testing.T) {
+	mediaService, store := newMediaService()
+	mediaRepoMock.On("FindByID", uint(3)).Return(uploadingMedia(0), nil).Once()
+	mediaRepoMock.On("AppendUploadChunk", mock.Anything).Return(false, nil).Once()
+
+	_, err := mediaService.AppendUpload(7, 3, 0, strings.NewReader("chunk"), context.Background())
+
+	assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+}
+
+func TestResumable_AppendUploadShouldStartOverOnInvalidContent(t *testing.T) {
+	mediaService, store := newMediaService()
+	content := pngBytes(4, 3)
+	media := uploadingMedia(0)
+	media.MimeType = "image/jpeg"
+
+	var chunks []model.MediaUploadChunk
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Once()
+	mediaRepoMock.On("AppendUploadChunk", mock.Anything).Return(func(chunk *model.MediaUploadChunk) bool {
+		chunks = append(chunks, *chunk)
+		return true
+	}, nil).Once()
+	mediaRepoMock.On("FindUploadChunks", uint(3)).Return(func(uint) []model.MediaUploadChunk { return chunks }, nil).Once()
+	mediaRepoMock.On("DeleteUploadChunks", uint(3)).Return(nil).Once()
+	mediaRepoMock.On("Update", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Status == consttype.MEDIA_PENDING && m.UploadOffset == 0
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+
+	_, err := mediaService.AppendUpload(7, 3, 0, strings.NewReader(content), context.Background())
+
+	assert.ErrorIs(t, err, ErrMediaTypeMismatch)
+	mediaRepoMock.AssertExpectations(t)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+}
+
+func TestResumable_AppendUploadShouldRefuseFinishedUpload(t *testing.T) {
+	mediaService, _ := newMediaService()
+	media := uploadingMedia(0)
+	media.Status = consttype.MEDIA_READY
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Once()
+
+	_, err := mediaService.AppendUpload(7, 3, 0, strings.NewReader("chunk"), context.Background())
+
+	assert.ErrorIs(t, err, ErrUploadNotPending)
+}
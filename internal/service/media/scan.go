@@ -0,0 +1,189 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/scanner"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/getsentry/sentry-go"
+	"gorm.io/gorm"
+)
+
+// quarantinePrefix holds uploads until they are scanned, its blobs are never
+// public.
+const quarantinePrefix = "quarantine/"
+
+// scanTimeout bounds the scan and promotion of one media.
+const scanTimeout = time.Minute * 5
+
+var (
+	ErrMediaNotScanned = errors.New("media has not been scanned")
+	ErrMediaInfected   = errors.New("media is infected")
+	// ErrChecksumMismatch means the file of a media is not the content that
+	// was validated on upload, it is never made ready.
+	ErrChecksumMismatch = errors.New("media content does not match its checksum")
+)
+
+// ScanService scans uploads in quarantine through the queue. Clean media are
+// promoted to their final key and become ready, infected media are deleted.
+type ScanService struct {
+	l         logger.Interface
+	store     storage.BlobStore
+	mediaRepo repository.IMediaRepo
+	scanner   scanner.Scanner
+	is        service.IImageService
+}
+
+func NewScanService(l logger.Interface, store storage.BlobStore, mediaRepo repository.IMediaRepo, sc scanner.Scanner, is service.IImageService) *ScanService {
+	return &ScanService{l: l, store: store, mediaRepo: mediaRepo, scanner: sc, is: is}
+}
+
+// ScanMedia scans a media waiting for it. Media deleted or scanned since they
+// were queued are skipped, a scan that fails is retried by the queue.
+func (ss *ScanService) ScanMedia(req request.ScanMediaRequest) error {
+	media, err := ss.mediaRepo.FindByID(req.MediaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if media.Status != consttype.MEDIA_PENDING_SCAN {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scanTimeout)
+	defer cancel()
+
+	body, _, err := ss.store.Get(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	tee := io.TeeReader(body, hash)
+	result, err := ss.scanner.Scan(ctx, tee)
+	if err == nil {
+		// the scanner may stop early, the checksum covers the whole file
+		_, err = io.Copy(io.Discard, tee)
+	}
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("scan : %w", err)
+	}
+
+	if result.Infected {
+		return ss.discard(media, result.Signature, ctx)
+	}
+	err = ss.verifyChecksum(media, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+
+	err = ss.promote(media, ctx)
+	if err != nil {
+		return err
+	}
+
+	// A media without variants is still usable, so a failure is only
+	// reported.
+	if isReadyImage(media) {
+		err = ss.is.ProcessMedia(request.ProcessMediaRequest{MediaID: media.ID})
+		if err != nil {
+			sentry.CaptureException(err)
+		}
+	}
+	return nil
+}
+
+// discard deletes the file of an infected media. The record stays, so its
+// owner learns why the upload is gone.
+func (ss *ScanService) discard(media *model.Media, signature string, ctx context.Context) error {
+	err := ss.store.Delete(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+
+	media.Status = consttype.MEDIA_INFECTED
+	_, err = ss.mediaRepo.Update(media)
+	if err != nil {
+		return err
+	}
+
+	ss.l.Warn(fmt.Sprintf("scan - media %d of user %d is infected with %s, its file is deleted", media.ID, media.UserID, signature))
+	sentry.CaptureMessage(fmt.Sprintf("media %d is infected with %s", media.ID, signature))
+	return nil
+}
+
+// verifyChecksum compares the checksum of the scanned file with the one of
+// the content validated on upload, so the file scanned is the one promoted.
+func (ss *ScanService) verifyChecksum(media *model.Media, checksum string) error {
+	if checksum == media.Checksum {
+		return nil
+	}
+
+	err := fmt.Errorf("scan - media %d : %w", media.ID, ErrChecksumMismatch)
+	sentry.CaptureException(err)
+	return err
+}
+
+// promote copies a clean media out of quarantine to its final key and marks
+// it ready. The copy is hashed on the way and deleted when it differs from
+// the file scanned. The quarantine blob is deleted last, a copy left behind is
+// only unreachable storage.
+func (ss *ScanService) promote(media *model.Media, ctx context.Context) error {
+	quarantineKey := media.StorageKey
+	if strings.HasPrefix(quarantineKey, quarantinePrefix) {
+		body, _, err := ss.store.Get(ctx, quarantineKey)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		key := strings.TrimPrefix(quarantineKey, quarantinePrefix)
+		hash := sha256.New()
+		_, err = ss.store.Put(ctx, key, io.TeeReader(body, hash), storage.PutOptions{
+			ContentType: media.MimeType,
+			Public:      isPublic(media),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = ss.verifyChecksum(media, hex.EncodeToString(hash.Sum(nil)))
+		if err != nil {
+			deleteErr := ss.store.Delete(ctx, key)
+			if deleteErr != nil {
+				sentry.CaptureException(deleteErr)
+			}
+			return err
+		}
+		media.StorageKey = key
+	}
+
+	media.Status = consttype.MEDIA_READY
+	_, err := ss.mediaRepo.Update(media)
+	if err != nil {
+		return err
+	}
+
+	if quarantineKey != media.StorageKey {
+		err = ss.store.Delete(ctx, quarantineKey)
+		if err != nil {
+			sentry.CaptureException(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/scanner"
+	"github.com/felixlambertv/go-cleanplate/internal/storage"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var imageServiceMock = new(mocks.IImageService)
+
+type failingScanner struct{}
+
+func (failingScanner) Scan(ctx context.Context, r io.Reader) (*scanner.Result, error) {
+	return nil, errors.New("clamd unavailable")
+}
+
+// swappingStore serves other content from the second read of a blob on, as
+// if it was replaced between the scan and the promotion.
+type swappingStore struct {
+	*storage.MemoryStore
+	reads   int
+	swapped string
+}
+
+func (s *swappingStore) Get(ctx context.Context, key string) (io.ReadCloser, *storage.BlobInfo, error) {
+	s.reads++
+	if s.reads > 1 {
+		return io.NopCloser(strings.NewReader(s.swapped)), &storage.BlobInfo{Key: key}, nil
+	}
+	return s.MemoryStore.Get(ctx, key)
+}
+
+func newScanService(sc scanner.Scanner) (*ScanService, *storage.MemoryStore) {
+	store := storage.NewMemoryStore(storage.NewURLSigner("https://api.test.com/api/v1/storage", "secret"))
+	return newScanServiceWithStore(sc, store), store
+}
+
+func newScanServiceWithStore(sc scanner.Scanner, store storage.BlobStore) *ScanService {
+	mediaRepoMock.ExpectedCalls = nil
+	mediaRepoMock.Calls = nil
+	imageServiceMock.ExpectedCalls = nil
+	imageServiceMock.Calls = nil
+
+	return NewScanService(logger.NewLogger("error"), store, mediaRepoMock, sc, imageServiceMock)
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func quarantinedMedia(store *storage.MemoryStore, content string) *model.Media {
+	media := pendingMedia()
+	media.StorageKey = "quarantine/image/upload.png"
+	media.Status = consttype.MEDIA_PENDING_SCAN
+	media.Checksum = sha256Hex(content)
+	_, _ = store.Put(context.Background(), media.StorageKey, strings.NewReader(content), storage.PutOptions{ContentType: "image/png"})
+	return media
+}
+
+func TestScan_ScanMediaShouldPromoteCleanMedia(t *testing.T) {
+	scanService, store := newScanService(scanner.EICARScanner{})
+	mediaRepoMock.On("FindByID", uint(3)).Return(quarantinedMedia(store, pngBytes(4, 3)), nil).Once()
+	mediaRepoMock.On("Update", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Status == consttype.MEDIA_READY && m.StorageKey == "image/upload.png"
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+	imageServiceMock.On("ProcessMedia", request.ProcessMediaRequest{MediaID: 3}).Return(nil).Once()
+
+	err := scanService.ScanMedia(request.ScanMediaRequest{MediaID: 3})
+
+	assert.Nil(t, err)
+	mediaRepoMock.AssertExpectations(t)
+	imageServiceMock.AssertExpectations(t)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 1)
+	assert.Equal(t, "image/upload.png", blobs[0].Key)
+}
+
+func TestScan_ScanMediaShouldDeleteInfectedMedia(t *testing.T) {
+	scanService, store := newScanService(scanner.EICARScanner{})
+	mediaRepoMock.On("FindByID", uint(3)).Return(quarantinedMedia(store, scanner.EICAR), nil).Once()
+	mediaRepoMock.On("Update", mock.MatchedBy(func(m *model.Media) bool {
+		return m.Status == consttype.MEDIA_INFECTED
+	})).Return(func(m *model.Media) *model.Media { return m }, nil).Once()
+
+	err := scanService.ScanMedia(request.ScanMediaRequest{MediaID: 3})
+
+	assert.Nil(t, err)
+	mediaRepoMock.AssertExpectations(t)
+	imageServiceMock.AssertNotCalled(t, "ProcessMedia", mock.Anything)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 0)
+}
+
+func TestScan_ScanMediaShouldSkipMediaAlreadyScanned(t *testing.T) {
+	scanService, store := newScanService(scanner.EICARScanner{})
+	media := quarantinedMedia(store, pngBytes(4, 3))
+	media.Status = consttype.MEDIA_READY
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Once()
+
+	err := scanService.ScanMedia(request.ScanMediaRequest{MediaID: 3})
+
+	assert.Nil(t, err)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestScan_ScanMediaShouldKeepMediaInQuarantineWhenScanFails(t *testing.T) {
+	scanService, store := newScanService(failingScanner{})
+	mediaRepoMock.On("FindByID", uint(3)).Return(quarantinedMedia(store, pngBytes(4, 3)), nil).Once()
+
+	err := scanService.ScanMedia(request.ScanMediaRequest{MediaID: 3})
+
+	assert.NotNil(t, err)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+	blobs, _ := store.List(context.Background(), "quarantine/")
+	assert.Len(t, blobs, 1)
+}
+
+func TestScan_ScanMediaShouldKeepMediaInQuarantineWhenContentChanged(t *testing.T) {
+	scanService, store := newScanService(scanner.EICARScanner{})
+	media := quarantinedMedia(store, pngBytes(4, 3))
+	media.Checksum = sha256Hex(pngBytes(2, 2))
+	mediaRepoMock.On("FindByID", uint(3)).Return(media, nil).Once()
+
+	err := scanService.ScanMedia(request.ScanMediaRequest{MediaID: 3})
+
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 1)
+	assert.Equal(t, "quarantine/image/upload.png", blobs[0].Key)
+}
+
+func TestScan_ScanMediaShouldNotPromoteContentOtherThanScanned(t *testing.T) {
+	store := &swappingStore{
+		MemoryStore: storage.NewMemoryStore(storage.NewURLSigner("https://api.test.com/api/v1/storage", "secret")),
+		swapped:     scanner.EICAR,
+	}
+	scanService := newScanServiceWithStore(scanner.EICARScanner{}, store)
+	mediaRepoMock.On("FindByID", uint(3)).Return(quarantinedMedia(store.MemoryStore, pngBytes(4, 3)), nil).Once()
+
+	err := scanService.ScanMedia(request.ScanMediaRequest{MediaID: 3})
+
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	mediaRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+	imageServiceMock.AssertNotCalled(t, "ProcessMedia", mock.Anything)
+	blobs, _ := store.List(context.Background(), "")
+	assert.Len(t, blobs, 1)
+	assert.Equal(t, "quarantine/image/upload.png", blobs[0].Key)
+}
@@ -0,0 +1,469 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMediaTypeNotAllowed = errors.New("media type is not allowed")
+	ErrMediaTooLarge       = errors.New("media is too large")
+	ErrMediaTypeMismatch   = errors.New("media content does not match its type")
+	ErrMediaContentInvalid = errors.New("media content is not valid")
+)
+
+// sniffLength is how much of the content is read to detect its type, the
+// same as browsers read before guessing a type of their own.
+const sniffLength = 1445
+
+// mediaFormat is a type the validator can recognise from content. Dir is the
+// directory of its blobs and the first extension is used for their keys.
+type mediaFormat struct {
+	Dir        string
+	Extensions []string
+	Match      func(head []byte) bool
+	// Strip removes metadata from the whole content and rejects trailing
+	// data, nil for types that are only sniffed.
+	Strip func(data []byte) ([]byte, error)
+}
+
+var mediaFormats = map[string]mediaFormat{
+	"image/jpeg": {
+		Dir:        "image",
+		Extensions: []string{".jpg", ".jpeg"},
+		Match:      func(head []byte) bool { return bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}) },
+		Strip:      stripJPEG,
+	},
+	"image/png": {
+		Dir:        "image",
+		Extensions: []string{".png"},
+		Match:      func(head []byte) bool { return bytes.HasPrefix(head, pngSignature) },
+		Strip:      stripPNG,
+	},
+	"video/mp4": {
+		Dir:        "video",
+		Extensions: []string{".mp4"},
+		Match:      func(head []byte) bool { return len(head) >= 12 && string(head[4:8]) == "ftyp" },
+	},
+	"audio/mpeg": {
+		Dir:        "sound",
+		Extensions: []string{".mp3"},
+		Match: func(head []byte) bool {
+			return bytes.HasPrefix(head, []byte("ID3")) || len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0
+		},
+	},
+}
+
+// markupMarkers are looked for at the start of every upload, where browsers
+// sniff for a page when they ignore the declared type.
+var markupMarkers = [][]byte{
+	[]byte("<html"), []byte("<head"), []byte("<body"), []byte("<script"), []byte("<iframe"),
+	[]byte("<svg"), []byte("<!doctype"), []byte("<?php"), []byte("<?xml"),
+}
+
+// scriptMarkers are looked for in the whole of images, which are small
+// enough for markers this long to not show up by chance.
+var scriptMarkers = [][]byte{[]byte("<script"), []byte("<?php")}
+
+// Validator checks uploads by their content instead of their name. Limits
+// holds the maximum size of every accepted type.
+type Validator struct {
+	limits map[string]int64
+}
+
+// validatedContent is an upload that passed validation. Body reads the
+// content to store, Sanitized holds the same content when its metadata was
+// stripped and is nil when it is stored as uploaded.
+type validatedContent struct {
+	ContentType string
+	Body        io.Reader
+	Sanitized   []byte
+	limit       *sizeLimitReader
+}
+
+// TooLarge reports whether reading Body went past the size limit, also when
+// the error was wrapped on the way.
+func (c *validatedContent) TooLarge() bool {
+	return c.limit.exceeded
+}
+
+// NewValidator accepts the types of config.Media, each mapped to a size such
+// as 600KB.
+func NewValidator(types map[string]string) (*Validator, error) {
+	if len(types) == 0 {
+		return nil, errors.New("no media types are configured")
+	}
+
+	limits := make(map[string]int64, len(types))
+	for contentType, size := range types {
+		contentType = normalizeType(contentType)
+		if _, ok := mediaFormats[contentType]; !ok {
+			return nil, fmt.Errorf("media type %s is not supported", contentType)
+		}
+		limit, err := parseSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("media type %s: %w", contentType, err)
+		}
+		limits[contentType] = limit
+	}
+	return &Validator{limits: limits}, nil
+}
+
+// DeclaredType returns the type a client claims for an upload, from the
+// extension of filename or else from contentType. It is empty when neither
+// names an accepted type.
+func (v *Validator) DeclaredType(filename string, contentType string) string {
+	extension := strings.ToLower(path.Ext(filename))
+	for t, format := range mediaFormats {
+		for _, ext := range format.Extensions {
+			if ext == extension {
+				return t
+			}
+		}
+	}
+
+	contentType = normalizeType(contentType)
+	if _, ok := v.limits[contentType]; ok {
+		return contentType
+	}
+	return ""
+}
+
+// CheckDeclared checks an upload before its content is sent, the extension
+// of filename has to match contentType.
+func (v *Validator) CheckDeclared(filename string, contentType string, size int64) (string, error) {
+	contentType = normalizeType(contentType)
+	limit, ok := v.limits[contentType]
+	if !ok || v.DeclaredType(filename, "") != contentType {
+		return "", ErrMediaTypeNotAllowed
+	}
+	if size > limit {
+		return "", ErrMediaTooLarge
+	}
+	return contentType, nil
+}
+
+// Open reads the start of r and checks that its content is of an accepted
+// type, the detected type has to be declaredType when one is given. The body
+// of the returned content fails with ErrMediaTooLarge once it goes past the
+// size limit of its type, so it can be streamed to storage as it is read.
+// Images are read and parsed whole first, their metadata is stripped and
+// trailing data after the image is rejected, so files that are also valid as
+// another format do not pass.
+func (v *Validator) Open(r io.Reader, declaredType string) (*validatedContent, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	if containsAny(head, markupMarkers) {
+		return nil, ErrMediaContentInvalid
+	}
+
+	contentType := sniffType(head)
+	limit, ok := v.limits[contentType]
+	if !ok {
+		return nil, ErrMediaTypeNotAllowed
+	}
+	if declaredType != "" && normalizeType(declaredType) != contentType {
+		return nil, ErrMediaTypeMismatch
+	}
+
+	body := &sizeLimitReader{r: io.MultiReader(bytes.NewReader(head), r), remaining: limit}
+	format := mediaFormats[contentType]
+	if format.Strip == nil {
+		return &validatedContent{ContentType: contentType, Body: body, limit: body}, nil
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	sanitized, err := format.Strip(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := image.DecodeConfig(bytes.NewReader(sanitized)); err != nil {
+		return nil, ErrMediaContentInvalid
+	}
+	if containsAny(sanitized, scriptMarkers) {
+		return nil, ErrMediaContentInvalid
+	}
+
+	return &validatedContent{
+		ContentType: contentType,
+		Body:        bytes.NewReader(sanitized),
+		Sanitized:   sanitized,
+		limit:       body,
+	}, nil
+}
+
+// sizeLimitReader fails with ErrMediaTooLarge once more than remaining bytes
+// are read.
+type sizeLimitReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrMediaTooLarge
+	}
+	// read one byte past the limit to tell content that fills it exactly
+	// from content that goes over
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		l.exceeded = true
+		return n + int(l.remaining), ErrMediaTooLarge
+	}
+	return n, err
+}
+
+func sniffType(head []byte) string {
+	for contentType, format := range mediaFormats {
+		if format.Match(head) {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// storageKey returns a new key for a blob of contentType.
+func storageKey(contentType string, name string) string {
+	format, ok := mediaFormats[contentType]
+	if !ok {
+		return "others/" + name
+	}
+	return format.Dir + "/" + name + format.Extensions[0]
+}
+
+func normalizeType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// parseSize parses sizes such as 600KB, units are powers of 1024.
+func parseSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		Suffix     string
+		Multiplier int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(size, unit.Suffix) {
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.Suffix))
+			multiplier = unit.Multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return n * multiplier, nil
+}
+
+func containsAny(data []byte, markers [][]byte) bool {
+	lower := bytes.ToLower(data)
+	for _, marker := range markers {
+		if bytes.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+// pngStrippedChunks hold metadata such as EXIF, GPS and free text.
+var pngStrippedChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// stripPNG walks the chunks of a PNG up to IEND, dropping metadata chunks.
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMediaContentInvalid
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for {
+		if i+12 > len(data) {
+			return nil, ErrMediaContentInvalid
+		}
+		length := int64(binary.BigEndian.Uint32(data[i:]))
+		chunkType := string(data[i+4 : i+8])
+		end := int64(i) + 12 + length
+		if end > int64(len(data)) {
+			return nil, ErrMediaContentInvalid
+		}
+		if !pngStrippedChunks[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+		i = int(end)
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	if i != len(data) {
+		return nil, ErrMediaContentInvalid
+	}
+	return out, nil
+}
+
+// jpegKeptSegments are the application segments needed to show the image
+// right: JFIF, the ICC colour profile and Adobe colour transform. Other APPn
+// segments, holding EXIF, GPS, XMP and IPTC, and comments are dropped, the
+// EXIF orientation is stored again on its own.
+var jpegKeptSegments = map[byte]bool{0xE0: true, 0xE2: true, 0xEE: true}
+
+// exifHeader starts the APP1 segment of EXIF, before its TIFF structure.
+var exifHeader = []byte("Exif\x00\x00")
+
+// exifOrientationTag is the tag of the orientation in the first IFD.
+const exifOrientationTag = 0x0112
+
+// stripJPEG walks the segments of a JPEG up to EOI, dropping metadata
+// segments. Photos taken sideways keep the orientation they are shown in.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMediaContentInvalid
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	oriented := false
+	i := 2
+	for {
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, ErrMediaContentInvalid
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte before a marker
+			i++
+			continue
+		case marker == 0xD9:
+			out = append(out, 0xFF, 0xD9)
+			i += 2
+			if i != len(data) {
+				return nil, ErrMediaContentInvalid
+			}
+			return out, nil
+		case marker >= 0xD0 && marker <= 0xD7 || marker == 0x01:
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, ErrMediaContentInvalid
+		}
+		end := i + 2 + (int(data[i+2])<<8 | int(data[i+3]))
+		if end < i+4 || end > len(data) {
+			return nil, ErrMediaContentInvalid
+		}
+		stripped := marker == 0xFE || marker >= 0xE0 && marker <= 0xEF && !jpegKeptSegments[marker]
+		if !stripped {
+			out = append(out, data[i:end]...)
+		} else if marker == 0xE1 && !oriented {
+			if orientation := exifOrientation(data[i+4 : end]); orientation > 1 {
+				out = append(out, orientationSegment(orientation)...)
+				oriented = true
+			}
+		}
+		i = end
+
+		if marker == 0xDA {
+			// entropy coded data runs up to the next marker that is not a
+			// stuffed byte or a restart marker
+			j := i
+			for ; j+1 < len(data); j++ {
+				if data[j] == 0xFF && data[j+1] != 0x00 && (data[j+1] < 0xD0 || data[j+1] > 0xD7) {
+					break
+				}
+			}
+			if j+1 >= len(data) {
+				return nil, ErrMediaContentInvalid
+			}
+			out = append(out, data[i:j]...)
+			i = j
+		}
+	}
+}
+
+// exifOrientation returns the orientation of an EXIF segment, from 1 to 8, or
+// 0 when the segment has none.
+func exifOrientation(segment []byte) uint16 {
+	if !bytes.HasPrefix(segment, exifHeader) {
+		return 0
+	}
+	tiff := segment[len(exifHeader):]
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd+2 > int64(len(tiff)) {
+		return 0
+	}
+	count := int64(order.Uint16(tiff[ifd:]))
+	for entry := ifd + 2; entry+12 <= int64(len(tiff)) && entry < ifd+2+count*12; entry += 12 {
+		// the orientation is a SHORT, stored in the value field itself
+		if order.Uint16(tiff[entry:]) == exifOrientationTag && order.Uint16(tiff[entry+2:]) == 3 {
+			orientation := order.Uint16(tiff[entry+8:])
+			if orientation > 8 {
+				return 0
+			}
+			return orientation
+		}
+	}
+	return 0
+}
+
+// orientationSegment is an APP1 segment whose EXIF holds the orientation
+// alone.
+func orientationSegment(orientation uint16) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	// padding of the value field and the offset of the next IFD, none
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+len(exifHeader)+len(tiff)))
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
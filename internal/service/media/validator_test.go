@@ -0,0 +1,178 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidator(t *testing.T) *Validator {
+	validator, err := NewValidator(map[string]string{"image/png": "600KB", "image/jpeg": "600KB", "video/mp4": "1KB"})
+	assert.Nil(t, err)
+	return validator
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// pngWithText inserts a text chunk before the IEND chunk.
+func pngWithText(text string) []byte {
+	data := []byte(pngBytes(4, 3))
+	iend := len(data) - 12
+	withText := append([]byte{}, data[:iend]...)
+	withText = append(withText, pngChunk("tEXt", []byte("Comment\x00"+text))...)
+	return append(withText, data[iend:]...)
+}
+
+// jpegWithExif inserts an APP1 segment after the start of image marker.
+func jpegWithExif(exif string) []byte {
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3)), nil)
+	data := buf.Bytes()
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exif)))
+	segment = append(segment, exif...)
+
+	withExif := append([]byte{}, data[:2]...)
+	withExif = append(withExif, segment...)
+	return append(withExif, data[2:]...)
+}
+
+func TestValidator_NewValidatorShouldRejectUnsupportedTypes(t *testing.T) {
+	_, err := NewValidator(map[string]string{"text/html": "1MB"})
+	assert.NotNil(t, err)
+
+	_, err = NewValidator(map[string]string{"image/png": "big"})
+	assert.NotNil(t, err)
+
+	_, err = NewValidator(map[string]string{})
+	assert.NotNil(t, err)
+}
+
+func TestValidator_ParseSize(t *testing.T) {
+	for size, expected := range map[string]int64{"600KB": 600 << 10, "30mb": 30 << 20, "1 GB": 1 << 30, "512B": 512, "100": 100} {
+		n, err := parseSize(size)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, n, size)
+	}
+}
+
+func TestValidator_CheckDeclaredShouldMatchExtensionAndType(t *testing.T) {
+	validator := newValidator(t)
+
+	contentType, err := validator.CheckDeclared("Holiday.JPEG", "image/jpeg; charset=binary", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+
+	_, err = validator.CheckDeclared("holiday.png", "image/jpeg", 10)
+	assert.ErrorIs(t, err, ErrMediaTypeNotAllowed)
+
+	_, err = validator.CheckDeclared("song.mp3", "audio/mpeg", 10)
+	assert.ErrorIs(t, err, ErrMediaTypeNotAllowed)
+
+	_, err = validator.CheckDeclared("clip.mp4", "video/mp4", 2<<10)
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+}
+
+func TestValidator_OpenShouldStripPngText(t *testing.T) {
+	content, err := newValidator(t).Open(bytes.NewReader(pngWithText("GPS 52.37,4.89")), "image/png")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", content.ContentType)
+	assert.Equal(t, pngBytes(4, 3), string(content.Sanitized))
+}
+
+func TestValidator_OpenShouldStripJpegExif(t *testing.T) {
+	data := jpegWithExif("Exif\x00\x00GPS 52.37,4.89")
+
+	content, err := newValidator(t).Open(bytes.NewReader(data), "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", content.ContentType)
+	assert.NotContains(t, string(content.Sanitized), "GPS")
+	assert.Equal(t, len(data)-len("Exif\x00\x00GPS 52.37,4.89")-4, len(content.Sanitized))
+	_, _, err = image.Decode(bytes.NewReader(content.Sanitized))
+	assert.Nil(t, err)
+}
+
+func TestValidator_OpenShouldKeepJpegOrientation(t *testing.T) {
+	// little endian EXIF with the GPS IFD pointer and orientation 6, rotated
+	// a quarter turn
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 2, 0,
+		0x25, 0x88, 4, 0, 1, 0, 0, 0, 38, 0, 0, 0,
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, 6, 0, 0, 0,
+		0, 0, 0, 0}
+	data := jpegWithExif("Exif\x00\x00" + string(tiff) + "GPS 52.37,4.89")
+
+	content, err := newValidator(t).Open(bytes.NewReader(data), "")
+
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content.Sanitized), "GPS")
+	assert.Equal(t, append([]byte{0xFF, 0xD8}, orientationSegment(6)...), content.Sanitized[:2+len(orientationSegment(6))])
+	assert.Equal(t, uint16(6), exifOrientation(orientationSegment(6)[4:]))
+	_, _, err = image.Decode(bytes.NewReader(content.Sanitized))
+	assert.Nil(t, err)
+}
+
+func TestValidator_OpenShouldRejectPolyglots(t *testing.T) {
+	validator := newValidator(t)
+
+	_, err := validator.Open(strings.NewReader(pngBytes(4, 3)+"<html><script>alert(1)</script>"), "image/png")
+	assert.ErrorIs(t, err, ErrMediaContentInvalid)
+
+	_, err = validator.Open(bytes.NewReader(jpegWithExif("")[:40]), "image/jpeg")
+	assert.ErrorIs(t, err, ErrMediaContentInvalid)
+
+	_, err = validator.Open(strings.NewReader(mp4Bytes(1000, 10)[:16]+"<!DOCTYPE html>"), "video/mp4")
+	assert.ErrorIs(t, err, ErrMediaContentInvalid)
+
+	_, err = validator.Open(strings.NewReader("<svg onload=alert(1)>"), "")
+	assert.ErrorIs(t, err, ErrMediaContentInvalid)
+}
+
+func TestValidator_OpenShouldCheckTypeAndSize(t *testing.T) {
+	validator := newValidator(t)
+
+	_, err := validator.Open(strings.NewReader(pngBytes(4, 3)), "image/jpeg")
+	assert.ErrorIs(t, err, ErrMediaTypeMismatch)
+
+	_, err = validator.Open(strings.NewReader("ID3 not allowed"), "")
+	assert.ErrorIs(t, err, ErrMediaTypeNotAllowed)
+
+	content, err := validator.Open(strings.NewReader(mp4Bytes(1000, 10)), "video/mp4")
+	assert.Nil(t, err)
+	assert.Nil(t, content.Sanitized)
+	data, err := io.ReadAll(content.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, mp4Bytes(1000, 10), string(data))
+}
+
+func TestValidator_OpenShouldFailReadingPastTheLimit(t *testing.T) {
+	validator := newValidator(t)
+
+	content, err := validator.Open(strings.NewReader(mp4Bytes(1000, 10)+strings.Repeat("\x00", 1<<10)), "video/mp4")
+	assert.Nil(t, err)
+	data, err := io.ReadAll(content.Body)
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+	assert.Len(t, data, 1<<10)
+	assert.True(t, content.TooLarge())
+
+	content, err = validator.Open(strings.NewReader(mp4Bytes(1000, 10)+strings.Repeat("\x00", 1<<10-len(mp4Bytes(1000, 10)))), "video/mp4")
+	assert.Nil(t, err)
+	_, err = io.ReadAll(content.Body)
+	assert.Nil(t, err)
+	assert.False(t, content.TooLarge())
+}
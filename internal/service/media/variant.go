@@ -0,0 +1,187 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/internal/webp"
+	"golang.org/x/image/draw"
+)
+
+// maxImagePixels keeps images that decompress to huge sizes from being
+// decoded.
+const maxImagePixels = 40_000_000
+
+// imageFormat is a format variants are encoded in.
+type imageFormat struct {
+	MimeType  string
+	Extension string
+	Encode    func(buf *bytes.Buffer, img image.Image) error
+}
+
+var imageFormats = map[string]imageFormat{
+	"jpeg": {MimeType: "image/jpeg", Extension: ".jpg", Encode: func(buf *bytes.Buffer, img image.Image) error {
+		return jpeg.Encode(buf, flatten(img), &jpeg.Options{Quality: 85})
+	}},
+	"png": {MimeType: "image/png", Extension: ".png", Encode: func(buf *bytes.Buffer, img image.Image) error {
+		return png.Encode(buf, img)
+	}},
+	"webp": {MimeType: "image/webp", Extension: ".webp", Encode: func(buf *bytes.Buffer, img image.Image) error {
+		return webp.Encode(buf, img)
+	}},
+}
+
+// VariantSpec is a size an image is resized to. Crop fills Width and Height
+// and cuts what is outside, otherwise the image fits within them. Images are
+// never enlarged.
+type VariantSpec struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+	Format string
+}
+
+// ParseVariantSpecs parses config.Media.Variants, each mapped to a spec such
+// as "200x200 crop jpeg".
+func ParseVariantSpecs(variants map[string]string) ([]VariantSpec, error) {
+	specs := make([]VariantSpec, 0, len(variants))
+	for name, value := range variants {
+		spec, err := parseVariantSpec(value)
+		if err != nil {
+			return nil, fmt.Errorf("media variant %s: %w", name, err)
+		}
+		spec.Name = strings.TrimSpace(name)
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs, nil
+}
+
+func parseVariantSpec(value string) (VariantSpec, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return VariantSpec{}, fmt.Errorf("invalid spec %q, expected size, fit and format", value)
+	}
+
+	width, height, ok := strings.Cut(fields[0], "x")
+	spec := VariantSpec{Format: fields[2]}
+	var err error
+	spec.Width, err = strconv.Atoi(width)
+	if err != nil || !ok || spec.Width < 1 {
+		return VariantSpec{}, fmt.Errorf("invalid size %q", fields[0])
+	}
+	spec.Height, err = strconv.Atoi(height)
+	if err != nil || spec.Height < 1 {
+		return VariantSpec{}, fmt.Errorf("invalid size %q", fields[0])
+	}
+
+	switch fields[1] {
+	case "crop":
+		spec.Crop = true
+	case "fit":
+	default:
+		return VariantSpec{}, fmt.Errorf("invalid fit %q", fields[1])
+	}
+	if _, ok := imageFormats[spec.Format]; !ok {
+		return VariantSpec{}, fmt.Errorf("invalid format %q", spec.Format)
+	}
+	return spec, nil
+}
+
+// decodeImage decodes an image within maxImagePixels.
+func decodeImage(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d is too large to process", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// renderVariant resizes src to spec and encodes it.
+func renderVariant(src image.Image, spec VariantSpec) ([]byte, image.Rectangle, error) {
+	img := resizeImage(src, spec.Width, spec.Height, spec.Crop)
+
+	var buf bytes.Buffer
+	err := imageFormats[spec.Format].Encode(&buf, img)
+	if err != nil {
+		return nil, image.Rectangle{}, err
+	}
+	return buf.Bytes(), img.Bounds(), nil
+}
+
+func resizeImage(src image.Image, width int, height int, crop bool) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := float64(bounds.Dx()), float64(bounds.Dy())
+
+	source := bounds
+	var scale float64
+	if crop {
+		scale = maxFloat(float64(width)/srcW, float64(height)/srcH)
+		// cut the source to the aspect ratio of the variant, around its
+		// centre
+		cutW := minInt(bounds.Dx(), int(float64(width)/scale+0.5))
+		cutH := minInt(bounds.Dy(), int(float64(height)/scale+0.5))
+		x := bounds.Min.X + (bounds.Dx()-cutW)/2
+		y := bounds.Min.Y + (bounds.Dy()-cutH)/2
+		source = image.Rect(x, y, x+cutW, y+cutH)
+	} else {
+		scale = minFloat(float64(width)/srcW, float64(height)/srcH)
+	}
+	if scale > 1 {
+		scale = 1
+	}
+
+	dstW := maxInt(1, int(float64(source.Dx())*scale+0.5))
+	dstH := maxInt(1, int(float64(source.Dy())*scale+0.5))
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, source, draw.Src, nil)
+	return dst
+}
+
+// flatten draws img over white, JPEG has no transparency.
+func flatten(img image.Image) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minFloat(a float64, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a float64, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,176 @@
+package notification
+
+import (
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"gorm.io/gorm"
+)
+
+type (
+	// Channel delivers notifications over one medium. Send skips notifications
+	// without content for the channel.
+	Channel interface {
+		Name() consttype.NotificationChannel
+		WithTrx(trxHandle *gorm.DB) Channel
+		Send(user *response.UserResponse, req request.NotificationRequest) error
+	}
+
+	// EmailChannel queues the email through the outbox, so it is only sent
+	// once the transaction that caused it commits.
+	EmailChannel struct {
+		ob service.IOutboxService
+	}
+
+	// SmsChannel queues a text message to the phone number of the user, users
+	// without a number are skipped.
+	SmsChannel struct {
+		ob service.IOutboxService
+	}
+
+	// InAppChannel stores the notification in the inbox of the user, streams
+	// pick it up once it is committed.
+	InAppChannel struct {
+		repo repository.IInAppNotificationRepo
+	}
+
+	// PushChannel queues one push per registered device of the user, so a
+	// failing device does not hold back the others.
+	PushChannel struct {
+		deviceRepo repository.IDeviceTokenRepo
+		ob         service.IOutboxService
+	}
+)
+
+func NewEmailChannel(ob service.IOutboxService) *EmailChannel {
+	return &EmailChannel{ob: ob}
+}
+
+func (e *EmailChannel) Name() consttype.NotificationChannel {
+	return consttype.CHANNEL_EMAIL
+}
+
+func (e *EmailChannel) WithTrx(trxHandle *gorm.DB) Channel {
+	return &EmailChannel{ob: e.ob.WithTrx(trxHandle)}
+}
+
+func (e *EmailChannel) Send(user *response.UserResponse, req request.NotificationRequest) error {
+	if req.Email == nil {
+		return nil
+	}
+
+	emailData, err := request.NewSendEmailRequest(req.Email, request.EmailAddress{Name: user.FullName, Email: user.Email})
+	if err != nil {
+		return err
+	}
+	emailData.Locale = req.Locale
+	emailData.UserID = user.ID
+	emailData.UnsubscribeUrl = req.UnsubscribeUrl
+
+	return e.ob.Enqueue(emailData.ToString(), consttype.SEND_EMAIL)
+}
+
+func NewSmsChannel(ob service.IOutboxService) *SmsChannel {
+	return &SmsChannel{ob: ob}
+}
+
+func (s *SmsChannel) Name() consttype.NotificationChannel {
+	return consttype.CHANNEL_SMS
+}
+
+func (s *SmsChannel) WithTrx(trxHandle *gorm.DB) Channel {
+	return &SmsChannel{ob: s.ob.WithTrx(trxHandle)}
+}
+
+func (s *SmsChannel) Send(user *response.UserResponse, req request.NotificationRequest) error {
+	if req.Title == "" || user.Phone == "" {
+		return nil
+	}
+
+	body := req.Title
+	if req.Body != "" {
+		body += "\n" + req.Body
+	}
+	if req.Link != "" {
+		body += "\n" + req.Link
+	}
+
+	smsReq := request.SendSmsRequest{UserID: user.ID, To: user.Phone, Body: body}
+	return s.ob.Enqueue(smsReq.ToString(), consttype.SEND_SMS)
+}
+
+func NewInAppChannel(repo repository.IInAppNotificationRepo) *InAppChannel {
+	return &InAppChannel{repo: repo}
+}
+
+func (i *InAppChannel) Name() consttype.NotificationChannel {
+	return consttype.CHANNEL_IN_APP
+}
+
+func (i *InAppChannel) WithTrx(trxHandle *gorm.DB) Channel {
+	return &InAppChannel{repo: i.repo.WithTrx(trxHandle)}
+}
+
+func (i *InAppChannel) Send(user *response.UserResponse, req request.NotificationRequest) error {
+	if req.Title == "" {
+		return nil
+	}
+
+	_, err := i.repo.Store(&model.InAppNotification{
+		UserID: user.ID,
+		Type:   req.Type,
+		Title:  req.Title,
+		Body:   req.Body,
+		Link:   req.Link,
+	})
+	return err
+}
+
+func NewPushChannel(deviceRepo repository.IDeviceTokenRepo, ob service.IOutboxService) *PushChannel {
+	return &PushChannel{deviceRepo: deviceRepo, ob: ob}
+}
+
+func (p *PushChannel) Name() consttype.NotificationChannel {
+	return consttype.CHANNEL_PUSH
+}
+
+func (p *PushChannel) WithTrx(trxHandle *gorm.DB) Channel {
+	return &PushChannel{deviceRepo: p.deviceRepo.WithTrx(trxHandle), ob: p.ob.WithTrx(trxHandle)}
+}
+
+func (p *PushChannel) Send(user *response.UserResponse, req request.NotificationRequest) error {
+	if req.Title == "" {
+		return nil
+	}
+
+	devices, err := p.deviceRepo.FindByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{"type": req.Type.String()}
+	if req.Link != "" {
+		data["link"] = req.Link
+	}
+
+	for _, device := range devices {
+		pushReq := request.SendPushRequest{
+			UserID:   user.ID,
+			Token:    device.Token,
+			Platform: device.Platform,
+			Title:    req.Title,
+			Body:     req.Body,
+			Data:     data,
+		}
+
+		err = p.ob.Enqueue(pushReq.ToString(), consttype.SEND_PUSH)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package notification
+
+import "github.com/felixlambertv/go-cleanplate/pkg/consttype"
+
+type definition struct {
+	Type consttype.NotificationType
+	// Transactional notifications are needed to use the account, they are
+	// always sent and cannot be turned off.
+	Transactional bool
+	// OptIn notifications are off until the user turns them on.
+	OptIn    bool
+	Channels []consttype.NotificationChannel
+}
+
+// definitions lists every notification type in the order they are shown to
+// the user.
+var definitions = []definition{
+	{
+		Type:          consttype.NOTIFICATION_VERIFY_EMAIL,
+		Transactional: true,
+		Channels:      []consttype.NotificationChannel{consttype.CHANNEL_EMAIL},
+	},
+	{
+		Type:          consttype.NOTIFICATION_RESET_PASSWORD,
+		Transactional: true,
+		Channels:      []consttype.NotificationChannel{consttype.CHANNEL_EMAIL},
+	},
+	{
+		Type:     consttype.NOTIFICATION_PRODUCT_UPDATE,
+		Channels: []consttype.NotificationChannel{consttype.CHANNEL_EMAIL, consttype.CHANNEL_PUSH, consttype.CHANNEL_IN_APP},
+	},
+	{
+		Type:     consttype.NOTIFICATION_PROMOTION,
+		OptIn:    true,
+		Channels: []consttype.NotificationChannel{consttype.CHANNEL_EMAIL, consttype.CHANNEL_SMS, consttype.CHANNEL_PUSH},
+	},
+}
+
+func findDefinition(notificationType consttype.NotificationType) (definition, bool) {
+	for _, def := range definitions {
+		if def.Type == notificationType {
+			return def, true
+		}
+	}
+	return definition{}, false
+}
+
+func (d definition) hasChannel(channel consttype.NotificationChannel) bool {
+	for _, c := range d.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+)
+
+const (
+	// subscriberBuffer is how many notifications wait for a slow stream before
+	// new ones are dropped, the client catches up when it reconnects.
+	subscriberBuffer = 16
+	listenRetryDelay = time.Second * 5
+	// maxReplay caps the notifications sent to a reconnecting stream.
+	maxReplay = 100
+)
+
+// InboxService serves the in-app notification inbox and pushes new
+// notifications to the streams of their user. Notifications are announced
+// through Postgres, so a stream receives them whichever replica stored them.
+type InboxService struct {
+	l    logger.Interface
+	repo repository.IInAppNotificationRepo
+
+	mu          sync.Mutex
+	subscribers map[uint]map[chan model.InAppNotification]struct{}
+}
+
+func NewInboxService(l logger.Interface, repo repository.IInAppNotificationRepo) *InboxService {
+	return &InboxService{l: l, repo: repo, subscribers: map[uint]map[chan model.InAppNotification]struct{}{}}
+}
+
+func (s *InboxService) GetNotifications(userID uint, filter request.InAppNotificationFilter, p model.Pagination) (*model.Pagination, error) {
+	return s.repo.FindAll(userID, filter, p)
+}
+
+// GetNotificationsAfter returns what a stream missed since afterID.
+func (s *InboxService) GetNotificationsAfter(userID uint, afterID uint) ([]model.InAppNotification, error) {
+	return s.repo.FindAfter(userID, afterID, maxReplay)
+}
+
+func (s *InboxService) MarkRead(userID uint, id uint) error {
+	return s.repo.MarkRead(userID, id)
+}
+
+func (s *InboxService) MarkAllRead(userID uint) error {
+	_, err := s.repo.MarkAllRead(userID)
+	return err
+}
+
+// Subscribe returns the new notifications of the user until the returned
+// function is called.
+func (s *InboxService) Subscribe(userID uint) (<-chan model.InAppNotification, func()) {
+	ch := make(chan model.InAppNotification, subscriberBuffer)
+
+	s.mu.Lock()
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = map[chan model.InAppNotification]struct{}{}
+	}
+	s.subscribers[userID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			delete(s.subscribers[userID], ch)
+			if len(s.subscribers[userID]) == 0 {
+				delete(s.subscribers, userID)
+			}
+		})
+	}
+}
+
+// Listen receives the announcements of stored notifications until ctx is
+// done, it reconnects when the connection fails.
+func (s *InboxService) Listen(ctx context.Context) {
+	for {
+		err := s.repo.Listen(ctx, s.publish)
+		if ctx.Err() != nil {
+			return
+		}
+		s.l.Error(fmt.Errorf("inbox - Listen: %w", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// publish loads an announced notification, only when its user has a stream
+// on this replica.
+func (s *InboxService) publish(id uint, userID uint) {
+	if !s.hasSubscribers(userID) {
+		return
+	}
+
+	notification, err := s.repo.FindByID(id)
+	if err != nil {
+		s.l.Error(fmt.Errorf("inbox - publish %d: %w", id, err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers[userID] {
+		select {
+		case ch <- *notification:
+		default:
+			s.l.Warn(fmt.Sprintf("inbox - stream of user %d is full, dropped notification %d", userID, id))
+		}
+	}
+}
+
+func (s *InboxService) hasSubscribers(userID uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.subscribers[userID]) > 0
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var inAppRepoMock = new(mocks.IInAppNotificationRepo)
+
+func newInboxService() *InboxService {
+	inAppRepoMock.ExpectedCalls = nil
+	inAppRepoMock.Calls = nil
+	return NewInboxService(logger.NewLogger("error"), inAppRepoMock)
+}
+
+func TestInbox_PublishShouldPushToSubscribersOfUser(t *testing.T) {
+	inboxService := newInboxService()
+	notification := &model.InAppNotification{ID: 3, UserID: user.ID, Title: "New feature"}
+	inAppRepoMock.On("FindByID", uint(3)).Return(notification, nil).Once()
+
+	first, unsubscribeFirst := inboxService.Subscribe(user.ID)
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := inboxService.Subscribe(user.ID)
+	unsubscribeSecond()
+	other, unsubscribeOther := inboxService.Subscribe(8)
+	defer unsubscribeOther()
+
+	inboxService.publish(3, user.ID)
+
+	assert.Equal(t, *notification, <-first)
+	assert.Empty(t, second)
+	assert.Empty(t, other)
+}
+
+func TestInbox_PublishShouldIgnoreUsersWithoutStream(t *testing.T) {
+	inboxService := newInboxService()
+
+	inboxService.publish(3, user.ID)
+
+	inAppRepoMock.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestInbox_PublishShouldDropWhenStreamIsFull(t *testing.T) {
+	inboxService := newInboxService()
+	inAppRepoMock.On("FindByID", mock.Anything).Return(&model.InAppNotification{UserID: user.ID}, nil)
+
+	notifications, unsubscribe := inboxService.Subscribe(user.ID)
+	defer unsubscribe()
+	for i := 0; i < subscriberBuffer+1; i++ {
+		inboxService.publish(uint(i), user.ID)
+	}
+
+	assert.Len(t, notifications, subscriberBuffer)
+}
+
+func TestInbox_ListenShouldStopWhenContextIsDone(t *testing.T) {
+	inboxService := newInboxService()
+	ctx, cancel := context.WithCancel(context.Background())
+	inAppRepoMock.On("Listen", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		handle := args.Get(1).(func(uint, uint))
+		handle(3, user.ID)
+		cancel()
+	}).Return(errors.New("conn closed")).Once()
+
+	done := make(chan struct{})
+	go func() {
+		inboxService.Listen(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Listen did not return")
+	}
+	inAppRepoMock.AssertNumberOfCalls(t, "Listen", 1)
+}
+
+func TestNotification_NotifyShouldStoreInAppNotification(t *testing.T) {
+	newInboxService()
+	preferenceRepoMock.ExpectedCalls = nil
+	notificationService := NewNotificationService(cfg, preferenceRepoMock, NewInAppChannel(inAppRepoMock))
+	preferenceRepoMock.On("FindByUserID", user.ID).Return(nil, nil).Once()
+	inAppRepoMock.On("Store", &model.InAppNotification{
+		UserID: user.ID,
+		Type:   consttype.NOTIFICATION_PRODUCT_UPDATE,
+		Title:  "New feature",
+		Body:   "Try it out.",
+		Link:   "https://example.com/features/new",
+	}).Return(&model.InAppNotification{}, nil).Once()
+
+	req := productUpdate()
+	req.Link = "https://example.com/features/new"
+	err := notificationService.Notify(user, req)
+
+	assert.Nil(t, err)
+	inAppRepoMock.AssertExpectations(t)
+}
+
+func TestNotification_NotifyShouldSkipInAppWithoutTitle(t *testing.T) {
+	newInboxService()
+	preferenceRepoMock.ExpectedCalls = nil
+	notificationService := NewNotificationService(cfg, preferenceRepoMock, NewInAppChannel(inAppRepoMock))
+	preferenceRepoMock.On("FindByUserID", user.ID).Return(nil, nil).Once()
+
+	err := notificationService.Notify(user, request.NotificationRequest{Type: consttype.NOTIFICATION_PRODUCT_UPDATE})
+
+	assert.Nil(t, err)
+	inAppRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPreference = errors.New("notification preference is not valid")
+
+type NotificationService struct {
+	cfg            *config.Config
+	preferenceRepo repository.INotificationPreferenceRepo
+	channels       map[consttype.NotificationChannel]Channel
+}
+
+// NewNotificationService sends notifications over the given channels, channels
+// of a notification type that are not given here are skipped.
+func NewNotificationService(cfg *config.Config, preferenceRepo repository.INotificationPreferenceRepo, channels ...Channel) *NotificationService {
+	byName := make(map[consttype.NotificationChannel]Channel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+	return &NotificationService{cfg: cfg, preferenceRepo: preferenceRepo, channels: byName}
+}
+
+func (n *NotificationService) WithTrx(trxHandle *gorm.DB) service.INotificationService {
+	channels := make(map[consttype.NotificationChannel]Channel, len(n.channels))
+	for name, channel := range n.channels {
+		channels[name] = channel.WithTrx(trxHandle)
+	}
+	return &NotificationService{
+		cfg:            n.cfg,
+		preferenceRepo: n.preferenceRepo.WithTrx(trxHandle),
+		channels:       channels,
+	}
+}
+
+// Notify sends req to user on every channel the user receives its type on.
+// Types that can be opted out of carry an unsubscribe link for the channel.
+func (n *NotificationService) Notify(user *response.UserResponse, req request.NotificationRequest) error {
+	def, ok := findDefinition(req.Type)
+	if !ok {
+		return fmt.Errorf("notification type %s is not known", req.Type)
+	}
+
+	var err error
+	var preferences []model.NotificationPreference
+	if !def.Transactional {
+		preferences, err = n.preferenceRepo.FindByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+	}
+	enabled := enabledChannels(def, preferences)
+
+	for _, name := range def.Channels {
+		channel, ok := n.channels[name]
+		if !ok || !enabled[name] {
+			continue
+		}
+
+		channelReq := req
+		if !def.Transactional {
+			channelReq.UnsubscribeUrl = n.unsubscribeURL(user.ID, def.Type, name)
+		}
+
+		err = channel.Send(user, channelReq)
+		if err != nil {
+			return fmt.Errorf("send %s notification by %s: %w", def.Type, name, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *NotificationService) GetPreferences(userID uint) ([]response.NotificationPreferenceResponse, error) {
+	preferences, err := n.preferenceRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.NotificationPreferenceResponse, 0, len(definitions))
+	for _, def := range definitions {
+		result = append(result, response.NotificationPreferenceResponse{
+			Type:          def.Type,
+			Transactional: def.Transactional,
+			Channels:      enabledChannels(def, preferences),
+		})
+	}
+
+	return result, nil
+}
+
+// UpdatePreferences turns channels of notification types on or off. Turning a
+// channel of a transactional type on is accepted, turning it off is not.
+func (n *NotificationService) UpdatePreferences(userID uint, req request.UpdateNotificationPreferencesRequest) ([]response.NotificationPreferenceResponse, error) {
+	preferences := make([]model.NotificationPreference, 0, len(req.Preferences))
+	for _, pref := range req.Preferences {
+		notificationType := consttype.NotificationType(pref.Type)
+		channel := consttype.NotificationChannel(pref.Channel)
+
+		def, ok := findDefinition(notificationType)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown type %s", ErrInvalidPreference, pref.Type)
+		}
+		if !def.hasChannel(channel) {
+			return nil, fmt.Errorf("%w: %s notifications are not sent by %s", ErrInvalidPreference, pref.Type, pref.Channel)
+		}
+		if def.Transactional {
+			if !*pref.Enabled {
+				return nil, fmt.Errorf("%w: %s notifications cannot be turned off", ErrInvalidPreference, pref.Type)
+			}
+			continue
+		}
+
+		preferences = append(preferences, model.NotificationPreference{
+			UserID:  userID,
+			Type:    notificationType,
+			Channel: channel,
+			Enabled: *pref.Enabled,
+		})
+	}
+
+	err := n.preferenceRepo.Store(preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.GetPreferences(userID)
+}
+
+// CheckUnsubscribe returns the type and channel a link in a notification was
+// signed for, without turning them off.
+func (n *NotificationService) CheckUnsubscribe(token string) (consttype.NotificationType, consttype.NotificationChannel, error) {
+	_, notificationType, channel, err := n.parseUnsubscribe(token)
+	return notificationType, channel, err
+}
+
+// Unsubscribe turns off the channel and type a link in a notification was
+// signed for.
+func (n *NotificationService) Unsubscribe(token string) error {
+	userID, notificationType, channel, err := n.parseUnsubscribe(token)
+	if err != nil {
+		return err
+	}
+
+	return n.preferenceRepo.Store([]model.NotificationPreference{{
+		UserID:  userID,
+		Type:    notificationType,
+		Channel: channel,
+		Enabled: false,
+	}})
+}
+
+func (n *NotificationService) parseUnsubscribe(token string) (uint, consttype.NotificationType, consttype.NotificationChannel, error) {
+	userID, notificationType, channel, err := parseUnsubscribeToken(n.cfg.App.Secret, token)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	def, ok := findDefinition(notificationType)
+	if !ok || def.Transactional || !def.hasChannel(channel) {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+	return userID, notificationType, channel, nil
+}
+
+// enabledChannels applies the preferences of a user to the defaults of def.
+func enabledChannels(def definition, preferences []model.NotificationPreference) map[consttype.NotificationChannel]bool {
+	enabled := make(map[consttype.NotificationChannel]bool, len(def.Channels))
+	for _, channel := range def.Channels {
+		enabled[channel] = def.Transactional || !def.OptIn
+	}
+	if def.Transactional {
+		return enabled
+	}
+
+	for _, pref := range preferences {
+		if _, ok := enabled[pref.Channel]; ok && pref.Type == def.Type {
+			enabled[pref.Channel] = pref.Enabled
+		}
+	}
+
+	return enabled
+}
+
+func (n *NotificationService) unsubscribeURL(userID uint, notificationType consttype.NotificationType, channel consttype.NotificationChannel) string {
+	token := signUnsubscribeToken(n.cfg.App.Secret, userID, notificationType, channel)
+	return fmt.Sprintf("%s/api/v1/notifications/unsubscribe?token=%s", n.cfg.App.Url, url.QueryEscape(token))
+}
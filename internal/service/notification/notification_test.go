@@ -0,0 +1,296 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var cfg = &config.Config{
+	App: config.App{
+		Url:    "https://api.test.com",
+		Secret: "randomblabla",
+	},
+}
+
+var user = &response.UserResponse{ID: 7, FullName: "Test User", Email: "user@test.com"}
+
+var preferenceRepoMock = new(mocks.INotificationPreferenceRepo)
+var outboxServiceMock = new(mocks.IOutboxService)
+
+// pushChannel records what it was asked to send.
+type pushChannel struct {
+	sent []request.NotificationRequest
+}
+
+func (p *pushChannel) Name() consttype.NotificationChannel { return consttype.CHANNEL_PUSH }
+
+func (p *pushChannel) WithTrx(trxHandle *gorm.DB) Channel { return p }
+
+func (p *pushChannel) Send(user *response.UserResponse, req request.NotificationRequest) error {
+	p.sent = append(p.sent, req)
+	return nil
+}
+
+func newNotificationService() (*NotificationService, *pushChannel) {
+	preferenceRepoMock.ExpectedCalls = nil
+	preferenceRepoMock.Calls = nil
+	outboxServiceMock.ExpectedCalls = nil
+	outboxServiceMock.Calls = nil
+
+	push := &pushChannel{}
+	return NewNotificationService(cfg, preferenceRepoMock, NewEmailChannel(outboxServiceMock), push), push
+}
+
+func productUpdate() request.NotificationRequest {
+	return request.NotificationRequest{
+		Type:  consttype.NOTIFICATION_PRODUCT_UPDATE,
+		Title: "New feature",
+		Body:  "Try it out.",
+		Email: request.ProductUpdateData{Name: user.FullName, Title: "New feature", Body: "Try it out."},
+	}
+}
+
+func TestNotification_NotifyShouldAlwaysSendTransactional(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SEND_EMAIL).Return(nil).Once()
+
+	err := notificationService.Notify(user, request.NotificationRequest{
+		Type:   consttype.NOTIFICATION_VERIFY_EMAIL,
+		Locale: "id",
+		Email:  request.VerifyEmailData{Name: user.FullName, Token: 1234},
+	})
+
+	assert.Nil(t, err)
+	preferenceRepoMock.AssertNotCalled(t, "FindByUserID", mock.Anything)
+	var queued request.SendEmailRequest
+	assert.Nil(t, json.Unmarshal([]byte(outboxServiceMock.Calls[0].Arguments.String(0)), &queued))
+	assert.Equal(t, "verify_email.html", queued.Template)
+	assert.Equal(t, "id", queued.Locale)
+	assert.Equal(t, user.ID, queued.UserID)
+	assert.Equal(t, []request.EmailAddress{{Name: user.FullName, Email: user.Email}}, queued.To)
+	assert.Empty(t, queued.UnsubscribeUrl)
+}
+
+func TestNotification_NotifyShouldSkipDisabledChannels(t *testing.T) {
+	notificationService, push := newNotificationService()
+	preferenceRepoMock.On("FindByUserID", user.ID).Return([]model.NotificationPreference{
+		{UserID: user.ID, Type: consttype.NOTIFICATION_PRODUCT_UPDATE, Channel: consttype.CHANNEL_EMAIL, Enabled: false},
+		{UserID: user.ID, Type: consttype.NOTIFICATION_PROMOTION, Channel: consttype.CHANNEL_PUSH, Enabled: false},
+	}, nil).Once()
+
+	err := notificationService.Notify(user, productUpdate())
+
+	assert.Nil(t, err)
+	outboxServiceMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+	assert.Len(t, push.sent, 1)
+	assert.Contains(t, push.sent[0].UnsubscribeUrl, "https://api.test.com/api/v1/notifications/unsubscribe?token=")
+}
+
+func TestNotification_NotifyShouldNotSendOptInTypeByDefault(t *testing.T) {
+	notificationService, push := newNotificationService()
+	preferenceRepoMock.On("FindByUserID", user.ID).Return(nil, nil).Once()
+
+	err := notificationService.Notify(user, request.NotificationRequest{Type: consttype.NOTIFICATION_PROMOTION, Title: "Sale"})
+
+	assert.Nil(t, err)
+	outboxServiceMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+	assert.Empty(t, push.sent)
+}
+
+func TestNotification_NotifyShouldAddUnsubscribeLinkToEmail(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	preferenceRepoMock.On("FindByUserID", user.ID).Return(nil, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SEND_EMAIL).Return(nil).Once()
+
+	err := notificationService.Notify(user, productUpdate())
+	assert.Nil(t, err)
+
+	var queued request.SendEmailRequest
+	assert.Nil(t, json.Unmarshal([]byte(outboxServiceMock.Calls[0].Arguments.String(0)), &queued))
+	link, err := url.Parse(queued.UnsubscribeUrl)
+	assert.Nil(t, err)
+
+	preferenceRepoMock.On("Store", []model.NotificationPreference{{
+		UserID:  user.ID,
+		Type:    consttype.NOTIFICATION_PRODUCT_UPDATE,
+		Channel: consttype.CHANNEL_EMAIL,
+		Enabled: false,
+	}}).Return(nil).Once()
+
+	err = notificationService.Unsubscribe(link.Query().Get("token"))
+
+	assert.Nil(t, err)
+	preferenceRepoMock.AssertExpectations(t)
+}
+
+func TestNotification_CheckUnsubscribeShouldNotTurnOffNotifications(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	preferenceRepoMock.Calls = nil
+	token := signUnsubscribeToken(cfg.App.Secret, user.ID, consttype.NOTIFICATION_PRODUCT_UPDATE, consttype.CHANNEL_EMAIL)
+
+	notificationType, channel, err := notificationService.CheckUnsubscribe(token)
+
+	assert.Nil(t, err)
+	assert.Equal(t, consttype.NOTIFICATION_PRODUCT_UPDATE, notificationType)
+	assert.Equal(t, consttype.CHANNEL_EMAIL, channel)
+	preferenceRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+
+	_, _, err = notificationService.CheckUnsubscribe(signUnsubscribeToken(cfg.App.Secret, user.ID, consttype.NOTIFICATION_VERIFY_EMAIL, consttype.CHANNEL_EMAIL))
+
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+}
+
+func TestNotification_UnsubscribeShouldRejectTamperedToken(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	token := signUnsubscribeToken(cfg.App.Secret, user.ID, consttype.NOTIFICATION_PRODUCT_UPDATE, consttype.CHANNEL_EMAIL)
+	other := signUnsubscribeToken(cfg.App.Secret, 8, consttype.NOTIFICATION_PRODUCT_UPDATE, consttype.CHANNEL_EMAIL)
+
+	for _, tampered := range []string{
+		token[:len(token)-2],
+		other[:len(other)/2] + token[len(token)/2:],
+		signUnsubscribeToken("other secret", user.ID, consttype.NOTIFICATION_PRODUCT_UPDATE, consttype.CHANNEL_EMAIL),
+		signUnsubscribeToken(cfg.App.Secret, user.ID, consttype.NOTIFICATION_VERIFY_EMAIL, consttype.CHANNEL_EMAIL),
+		"",
+	} {
+		err := notificationService.Unsubscribe(tampered)
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+	}
+	preferenceRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestNotification_GetPreferencesShouldApplyDefaults(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	preferenceRepoMock.On("FindByUserID", user.ID).Return([]model.NotificationPreference{
+		{UserID: user.ID, Type: consttype.NOTIFICATION_PROMOTION, Channel: consttype.CHANNEL_SMS, Enabled: true},
+	}, nil).Once()
+
+	preferences, err := notificationService.GetPreferences(user.ID)
+
+	assert.Nil(t, err)
+	assert.Len(t, preferences, len(definitions))
+	assert.Equal(t, response.NotificationPreferenceResponse{
+		Type:          consttype.NOTIFICATION_VERIFY_EMAIL,
+		Transactional: true,
+		Channels:      map[consttype.NotificationChannel]bool{consttype.CHANNEL_EMAIL: true},
+	}, preferences[0])
+	assert.Equal(t, map[consttype.NotificationChannel]bool{
+		consttype.CHANNEL_EMAIL: false,
+		consttype.CHANNEL_SMS:   true,
+		consttype.CHANNEL_PUSH:  false,
+	}, preferences[3].Channels)
+}
+
+func TestNotification_UpdatePreferencesShouldStoreMarketingPreferences(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	enabled, disabled := true, false
+	preferenceRepoMock.On("Store", []model.NotificationPreference{
+		{UserID: user.ID, Type: consttype.NOTIFICATION_PRODUCT_UPDATE, Channel: consttype.CHANNEL_IN_APP, Enabled: false},
+	}).Return(nil).Once()
+	preferenceRepoMock.On("FindByUserID", user.ID).Return(nil, nil).Once()
+
+	_, err := notificationService.UpdatePreferences(user.ID, request.UpdateNotificationPreferencesRequest{
+		Preferences: []request.NotificationPreferenceRequest{
+			{Type: "reset_password", Channel: "email", Enabled: &enabled},
+			{Type: "product_update", Channel: "in_app", Enabled: &disabled},
+		},
+	})
+
+	assert.Nil(t, err)
+	preferenceRepoMock.AssertExpectations(t)
+}
+
+func TestNotification_UpdatePreferencesShouldRejectInvalidPreferences(t *testing.T) {
+	notificationService, _ := newNotificationService()
+	disabled := false
+
+	for _, pref := range []request.NotificationPreferenceRequest{
+		{Type: "reset_password", Channel: "email", Enabled: &disabled},
+		{Type: "newsletter", Channel: "email", Enabled: &disabled},
+		{Type: "product_update", Channel: "sms", Enabled: &disabled},
+	} {
+		_, err := notificationService.UpdatePreferences(user.ID, request.UpdateNotificationPreferencesRequest{
+			Preferences: []request.NotificationPreferenceRequest{pref},
+		})
+		assert.ErrorIs(t, err, ErrInvalidPreference)
+	}
+	preferenceRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestNotification_NotifyShouldQueuePushPerDevice(t *testing.T) {
+	newNotificationService()
+	deviceRepoMock := new(mocks.IDeviceTokenRepo)
+	notificationService := NewNotificationService(cfg, preferenceRepoMock, NewPushChannel(deviceRepoMock, outboxServiceMock))
+	preferenceRepoMock.On("FindByUserID", user.ID).Return(nil, nil).Once()
+	deviceRepoMock.On("FindByUserID", user.ID).Return([]model.DeviceToken{
+		{UserID: user.ID, Token: "android-token", Platform: consttype.PLATFORM_ANDROID},
+		{UserID: user.ID, Token: "ios-token", Platform: consttype.PLATFORM_IOS},
+	}, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SEND_PUSH).Return(nil).Twice()
+
+	req := productUpdate()
+	req.Link = "https://example.com/features/new"
+	err := notificationService.Notify(user, req)
+
+	assert.Nil(t, err)
+	outboxServiceMock.AssertNumberOfCalls(t, "Enqueue", 2)
+	var queued request.SendPushRequest
+	assert.Nil(t, json.Unmarshal([]byte(outboxServiceMock.Calls[1].Arguments.String(0)), &queued))
+	assert.Equal(t, "ios-token", queued.Token)
+	assert.Equal(t, consttype.PLATFORM_IOS, queued.Platform)
+	assert.Equal(t, "New feature", queued.Title)
+	assert.Equal(t, map[string]string{"type": "product_update", "link": "https://example.com/features/new"}, queued.Data)
+}
+
+func TestNotification_NotifyShouldQueueSmsToPhone(t *testing.T) {
+	newNotificationService()
+	notificationService := NewNotificationService(cfg, preferenceRepoMock, NewSmsChannel(outboxServiceMock))
+	preferenceRepoMock.On("FindByUserID", user.ID).Return([]model.NotificationPreference{
+		{UserID: user.ID, Type: consttype.NOTIFICATION_PROMOTION, Channel: consttype.CHANNEL_SMS, Enabled: true},
+	}, nil).Once()
+	outboxServiceMock.On("Enqueue", mock.Anything, consttype.SEND_SMS).Return(nil).Once()
+
+	withPhone := *user
+	withPhone.Phone = "+6281234567890"
+	err := notificationService.Notify(&withPhone, request.NotificationRequest{
+		Type:  consttype.NOTIFICATION_PROMOTION,
+		Title: "Half price",
+		Body:  "This weekend only.",
+		Link:  "https://example.com/sale",
+	})
+
+	assert.Nil(t, err)
+	var queued request.SendSmsRequest
+	assert.Nil(t, json.Unmarshal([]byte(outboxServiceMock.Calls[0].Arguments.String(0)), &queued))
+	assert.Equal(t, request.SendSmsRequest{
+		UserID: user.ID,
+		To:     "+6281234567890",
+		Body:   "Half price\nThis weekend only.\nhttps://example.com/sale",
+	}, queued)
+}
+
+func TestNotification_NotifyShouldSkipSmsWithoutPhone(t *testing.T) {
+	newNotificationService()
+	notificationService := NewNotificationService(cfg, preferenceRepoMock, NewSmsChannel(outboxServiceMock))
+	preferenceRepoMock.On("FindByUserID", user.ID).Return([]model.NotificationPreference{
+		{UserID: user.ID, Type: consttype.NOTIFICATION_PROMOTION, Channel: consttype.CHANNEL_SMS, Enabled: true},
+	}, nil).Once()
+
+	err := notificationService.Notify(user, request.NotificationRequest{
+		Type:  consttype.NOTIFICATION_PROMOTION,
+		Title: "Half price",
+	})
+
+	assert.Nil(t, err)
+	outboxServiceMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+}
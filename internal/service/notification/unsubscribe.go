@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("unsubscribe token is not valid")
+
+// Unsubscribe tokens turn off one channel of one notification type for a user.
+// They do not expire, links in old emails keep working. The signing key is
+// derived from the app secret so a token is never valid as anything else.
+func signUnsubscribeToken(secret string, userID uint, notificationType consttype.NotificationType, channel consttype.NotificationChannel) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s:%s", userID, notificationType, channel)))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(unsubscribeMAC(secret, payload))
+}
+
+func parseUnsubscribeToken(secret string, token string) (uint, consttype.NotificationType, consttype.NotificationChannel, error) {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, unsubscribeMAC(secret, payload)) {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	parts := strings.Split(string(decoded), ":")
+	if len(parts) != 3 {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	return uint(userID), consttype.NotificationType(parts[1]), consttype.NotificationChannel(parts[2]), nil
+}
+
+func unsubscribeMAC(secret string, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte("unsubscribe:"+secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
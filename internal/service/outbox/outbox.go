@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/internal/service"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	relayBatchSize   = 10
+	relayMaxAttempts = 10
+)
+
+type OutboxService struct {
+	outboxRepo repository.IOutboxRepo
+	qs         service.IQueueService
+}
+
+func NewOutboxService(outboxRepo repository.IOutboxRepo, qs service.IQueueService) *OutboxService {
+	return &OutboxService{outboxRepo: outboxRepo, qs: qs}
+}
+
+func (o *OutboxService) WithTrx(trxHandle *gorm.DB) service.IOutboxService {
+	return &OutboxService{outboxRepo: o.outboxRepo.WithTrx(trxHandle), qs: o.qs}
+}
+
+// Enqueue records the message in the outbox table. When the service is bound
+// to a transaction with WithTrx, the message is only published if that
+// transaction commits.
+func (o *OutboxService) Enqueue(messageBody string, messageType consttype.QueueType) error {
+	return o.store(messageBody, messageType, nil)
+}
+
+// EnqueueAt is Enqueue for one-off jobs that should not run before deliverAt,
+// e.g. a reminder email sent a day after registration.
+func (o *OutboxService) EnqueueAt(messageBody string, messageType consttype.QueueType, deliverAt time.Time) error {
+	return o.store(messageBody, messageType, &deliverAt)
+}
+
+func (o *OutboxService) store(messageBody string, messageType consttype.QueueType, deliverAt *time.Time) error {
+	message := &model.OutboxMessage{
+		QueueType:       messageType,
+		Body:            messageBody,
+		DeduplicationId: uuid.Must(uuid.NewRandom()).String(),
+		DeliverAt:       deliverAt,
+	}
+
+	_, err := o.outboxRepo.Store(message)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Relay publishes pending outbox messages to the queue. A message can be
+// published more than once if marking it fails, so consumers rely on the
+// deduplication ID to discard repeats.
+func (o *OutboxService) Relay() error {
+	var publishErr error
+
+	err := o.outboxRepo.Transaction(func(repo repository.IOutboxRepo) error {
+		messages, err := repo.FindUnpublished(relayBatchSize, relayMaxAttempts)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			if message.DeliverAt != nil {
+				err = o.qs.SendDelayedMessage(message.Body, message.QueueType, message.DeduplicationId, *message.DeliverAt)
+			} else {
+				err = o.qs.SendMessageWithDeduplication(message.Body, message.QueueType, message.DeduplicationId)
+			}
+			if err != nil {
+				publishErr = fmt.Errorf("publish outbox message %d: %w", message.ID, err)
+				return repo.MarkFailed(message.ID, err.Error())
+			}
+
+			err = repo.MarkPublished(message.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return publishErr
+}
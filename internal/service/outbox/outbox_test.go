@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var outboxRepoMock = new(mocks.IOutboxRepo)
+var queueServiceMock = new(mocks.IQueueService)
+var outboxService = NewOutboxService(outboxRepoMock, queueServiceMock)
+
+var pendingMessages = []model.OutboxMessage{
+	{ID: 1, QueueType: consttype.SEND_EMAIL, Body: "first", DeduplicationId: "dedup-1"},
+	{ID: 2, QueueType: consttype.SEND_EMAIL, Body: "second", DeduplicationId: "dedup-2"},
+}
+
+func BeforeEachOutboxTest() {
+	outboxRepoMock.ExpectedCalls = nil
+	outboxRepoMock.Calls = nil
+	queueServiceMock.ExpectedCalls = nil
+	queueServiceMock.Calls = nil
+
+	outboxRepoMock.On("Transaction", mock.Anything).Return(func(fn func(repository.IOutboxRepo) error) error {
+		return fn(outboxRepoMock)
+	})
+}
+
+func TestOutbox_EnqueueShouldStoreMessageWithDeduplicationId(t *testing.T) {
+	BeforeEachOutboxTest()
+	outboxRepoMock.On("Store", mock.Anything).Return(&model.OutboxMessage{}, nil).Once()
+
+	err := outboxService.Enqueue("body", consttype.SEND_EMAIL)
+
+	assert.Nil(t, err)
+	stored := outboxRepoMock.Calls[0].Arguments[0].(*model.OutboxMessage)
+	assert.Equal(t, "body", stored.Body)
+	assert.Equal(t, consttype.SEND_EMAIL, stored.QueueType)
+	assert.NotEmpty(t, stored.DeduplicationId)
+}
+
+func TestOutbox_EnqueueShouldReturnStoreError(t *testing.T) {
+	BeforeEachOutboxTest()
+	outboxRepoMock.On("Store", mock.Anything).Return(nil, errors.New("store went wrong")).Once()
+
+	err := outboxService.Enqueue("body", consttype.SEND_EMAIL)
+
+	assert.Equal(t, errors.New("store went wrong"), err)
+}
+
+func TestOutbox_RelayShouldPublishAndMarkMessages(t *testing.T) {
+	BeforeEachOutboxTest()
+	outboxRepoMock.On("FindUnpublished", relayBatchSize, relayMaxAttempts).Return(pendingMessages, nil).Once()
+	queueServiceMock.On("SendMessageWithDeduplication", "first", consttype.SEND_EMAIL, "dedup-1").Return(nil).Once()
+	queueServiceMock.On("SendMessageWithDeduplication", "second", consttype.SEND_EMAIL, "dedup-2").Return(nil).Once()
+	outboxRepoMock.On("MarkPublished", uint(1)).Return(nil).Once()
+	outboxRepoMock.On("MarkPublished", uint(2)).Return(nil).Once()
+
+	err := outboxService.Relay()
+
+	assert.Nil(t, err)
+	queueServiceMock.AssertNumberOfCalls(t, "SendMessageWithDeduplication", 2)
+	outboxRepoMock.AssertNumberOfCalls(t, "MarkPublished", 2)
+}
+
+func TestOutbox_RelayShouldMarkFailedAndStopWhenPublishFails(t *testing.T) {
+	BeforeEachOutboxTest()
+	outboxRepoMock.On("FindUnpublished", relayBatchSize, relayMaxAttempts).Return(pendingMessages, nil).Once()
+	queueServiceMock.On("SendMessageWithDeduplication", "first", consttype.SEND_EMAIL, "dedup-1").Return(errors.New("queue down")).Once()
+	outboxRepoMock.On("MarkFailed", uint(1), "queue down").Return(nil).Once()
+
+	err := outboxService.Relay()
+
+	assert.EqualError(t, err, "publish outbox message 1: queue down")
+	queueServiceMock.AssertNumberOfCalls(t, "SendMessageWithDeduplication", 1)
+	outboxRepoMock.AssertNotCalled(t, "MarkPublished", mock.Anything)
+}
+
+func TestOutbox_RelayShouldSendDelayedMessageWhenDeliverAtIsSet(t *testing.T) {
+	BeforeEachOutboxTest()
+	deliverAt := time.Now().Add(time.Hour * 24)
+	delayed := []model.OutboxMessage{
+		{ID: 3, QueueType: consttype.SEND_EMAIL, Body: "later", DeduplicationId: "dedup-3", DeliverAt: &deliverAt},
+	}
+	outboxRepoMock.On("FindUnpublished", relayBatchSize, relayMaxAttempts).Return(delayed, nil).Once()
+	queueServiceMock.On("SendDelayedMessage", "later", consttype.SEND_EMAIL, "dedup-3", deliverAt).Return(nil).Once()
+	outboxRepoMock.On("MarkPublished", uint(3)).Return(nil).Once()
+
+	err := outboxService.Relay()
+
+	assert.Nil(t, err)
+	queueServiceMock.AssertNotCalled(t, "SendMessageWithDeduplication", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,160 @@
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	apnsEndpoint        = "https://api.push.apple.com"
+	apnsSandboxEndpoint = "https://api.sandbox.push.apple.com"
+	// Provider tokens are valid for an hour and must not be renewed more often
+	// than every 20 minutes.
+	apnsTokenLifetime = time.Minute * 50
+)
+
+// apnsInvalidReasons are the APNs errors meaning the token must not be used
+// again.
+var apnsInvalidReasons = map[string]bool{
+	"BadDeviceToken":         true,
+	"DeviceTokenNotForTopic": true,
+	"Unregistered":           true,
+}
+
+// APNsSender sends through the APNs HTTP/2 API with token based provider
+// authentication.
+type APNsSender struct {
+	client   *http.Client
+	endpoint string
+	key      *ecdsa.PrivateKey
+	keyID    string
+	teamID   string
+	topic    string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender signs provider tokens with the .p8 key, topic is the bundle
+// ID of the app.
+func NewAPNsSender(client *http.Client, key []byte, keyID string, teamID string, topic string, sandbox bool) (*APNsSender, error) {
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, errors.New("apns requires PUSH_APNS_KEY_ID, PUSH_APNS_TEAM_ID and PUSH_APNS_TOPIC")
+	}
+
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(key)
+	if err != nil {
+		return nil, fmt.Errorf("parse apns key: %w", err)
+	}
+
+	endpoint := apnsEndpoint
+	if sandbox {
+		endpoint = apnsSandboxEndpoint
+	}
+
+	return &APNsSender{client: client, endpoint: endpoint, key: privateKey, keyID: keyID, teamID: teamID, topic: topic}, nil
+}
+
+func (a *APNsSender) Send(token string, message Message) (string, error) {
+	providerToken, err := a.providerToken()
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.endpoint+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", a.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		return res.Header.Get("apns-id"), nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+	_ = json.Unmarshal(resBody, &apnsErr)
+
+	if apnsInvalidReasons[apnsErr.Reason] {
+		return "", fmt.Errorf("%w: apns %s", ErrInvalidToken, apnsErr.Reason)
+	}
+	if apnsErr.Reason == "ExpiredProviderToken" {
+		a.resetProviderToken()
+	}
+
+	return "", fmt.Errorf("apns send: status %d: %s", res.StatusCode, apnsErr.Reason)
+}
+
+func (a *APNsSender) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Since(a.issuedAt) < apnsTokenLifetime {
+		return a.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = a.keyID
+
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = signed
+	a.issuedAt = now
+	return a.token, nil
+}
+
+func (a *APNsSender) resetProviderToken() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+}
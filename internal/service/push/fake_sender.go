@@ -0,0 +1,57 @@
+package push
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// FakeSender prints pushes instead of sending them. Tokens passed to
+	// Invalidate are rejected like a provider rejects uninstalled apps.
+	FakeSender struct {
+		mu      sync.Mutex
+		sent    []SentMessage
+		invalid map[string]bool
+	}
+
+	SentMessage struct {
+		ID      string
+		Token   string
+		Message Message
+	}
+)
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{invalid: map[string]bool{}}
+}
+
+func (f *FakeSender) Send(token string, message Message) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.invalid[token] {
+		return "", ErrInvalidToken
+	}
+
+	id := uuid.Must(uuid.NewRandom()).String()
+	f.sent = append(f.sent, SentMessage{ID: id, Token: token, Message: message})
+	fmt.Printf("Push sent to token %s: %s - %s\n", token, message.Title, message.Body)
+
+	return id, nil
+}
+
+func (f *FakeSender) Sent() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]SentMessage(nil), f.sent...)
+}
+
+func (f *FakeSender) Invalidate(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.invalid[token] = true
+}
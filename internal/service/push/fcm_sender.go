@@ -0,0 +1,200 @@
+package push
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	fcmEndpoint = "https://fcm.googleapis.com"
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	// Access tokens are renewed a minute before they expire.
+	fcmTokenLeeway = time.Minute
+)
+
+type (
+	// FCMSender sends through the FCM HTTP v1 API. It authenticates as the
+	// service account of the credentials file, trading a signed JWT for an
+	// OAuth2 access token.
+	FCMSender struct {
+		client    *http.Client
+		projectID string
+		endpoint  string
+		account   fcmServiceAccount
+		key       *rsa.PrivateKey
+
+		mu          sync.Mutex
+		accessToken string
+		expiresAt   time.Time
+	}
+
+	fcmServiceAccount struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+
+	fcmError struct {
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+)
+
+func NewFCMSender(client *http.Client, projectID string, credentials []byte) (*FCMSender, error) {
+	var account fcmServiceAccount
+	err := json.Unmarshal(credentials, &account)
+	if err != nil {
+		return nil, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, errors.New("fcm credentials are not a service account key")
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse fcm private key: %w", err)
+	}
+
+	return &FCMSender{client: client, projectID: projectID, endpoint: fcmEndpoint, account: account, key: key}, nil
+}
+
+func (f *FCMSender) Send(token string, message Message) (string, error) {
+	accessToken, err := f.token()
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token": token,
+			"notification": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"data": message.Data,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/projects/%s/messages:send", f.endpoint, f.projectID), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", fcmSendError(res.StatusCode, resBody)
+	}
+
+	var sent struct {
+		Name string `json:"name"`
+	}
+	err = json.Unmarshal(resBody, &sent)
+	if err != nil {
+		return "", err
+	}
+
+	return sent.Name, nil
+}
+
+// fcmSendError maps the errors FCM documents for stale registration tokens
+// to ErrInvalidToken.
+func fcmSendError(statusCode int, body []byte) error {
+	var res fcmError
+	_ = json.Unmarshal(body, &res)
+
+	errorCode := res.Error.Status
+	for _, detail := range res.Error.Details {
+		if detail.ErrorCode != "" {
+			errorCode = detail.ErrorCode
+		}
+	}
+
+	switch {
+	case errorCode == "UNREGISTERED", errorCode == "SENDER_ID_MISMATCH":
+		return fmt.Errorf("%w: fcm %s", ErrInvalidToken, errorCode)
+	case errorCode == "INVALID_ARGUMENT" && strings.Contains(res.Error.Message, "registration token"):
+		return fmt.Errorf("%w: fcm %s", ErrInvalidToken, res.Error.Message)
+	default:
+		return fmt.Errorf("fcm send: status %d %s: %s", statusCode, errorCode, res.Error.Message)
+	}
+}
+
+func (f *FCMSender) token() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.accessToken != "" && time.Now().Add(fcmTokenLeeway).Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   f.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   f.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(f.key)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := f.client.PostForm(f.account.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return "", fmt.Errorf("fcm access token: status %d: %s", res.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&token)
+	if err != nil {
+		return "", err
+	}
+
+	f.accessToken = token.AccessToken
+	f.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return f.accessToken, nil
+}
@@ -0,0 +1,72 @@
+package push
+
+import (
+	"errors"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+)
+
+var ErrPlatformUnavailable = errors.New("push is not available for the platform")
+
+// PushService keeps the device tokens of users and delivers the pushes queued
+// by the push notification channel.
+type PushService struct {
+	l          logger.Interface
+	deviceRepo repository.IDeviceTokenRepo
+	senders    map[consttype.DevicePlatform]Sender
+}
+
+func NewPushService(l logger.Interface, deviceRepo repository.IDeviceTokenRepo, senders map[consttype.DevicePlatform]Sender) *PushService {
+	return &PushService{l: l, deviceRepo: deviceRepo, senders: senders}
+}
+
+func (s *PushService) GetDevices(userID uint) ([]model.DeviceToken, error) {
+	return s.deviceRepo.FindByUserID(userID)
+}
+
+func (s *PushService) RegisterDevice(userID uint, req request.RegisterDeviceRequest) (*model.DeviceToken, error) {
+	platform := consttype.DevicePlatform(req.Platform)
+	if _, ok := s.senders[platform]; !ok {
+		return nil, ErrPlatformUnavailable
+	}
+
+	return s.deviceRepo.Store(&model.DeviceToken{
+		UserID:   userID,
+		Token:    req.Token,
+		Platform: platform,
+	})
+}
+
+func (s *PushService) UnregisterDevice(userID uint, token string) error {
+	return s.deviceRepo.Delete(userID, token)
+}
+
+// SendPush delivers one queued push. Tokens the provider rejects are removed
+// instead of failing the message, retrying would be rejected again.
+func (s *PushService) SendPush(req request.SendPushRequest) error {
+	sender, ok := s.senders[req.Platform]
+	if !ok {
+		s.l.Warn("push - no %s sender configured, dropped message for user %d", req.Platform, req.UserID)
+		return nil
+	}
+
+	id, err := sender.Send(req.Token, Message{Title: req.Title, Body: req.Body, Data: req.Data})
+	if errors.Is(err, ErrInvalidToken) {
+		_, err = s.deviceRepo.DeleteByToken(req.Token)
+		if err != nil {
+			return err
+		}
+		s.l.Info("push - removed invalid %s token of user %d", req.Platform, req.UserID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.l.Info("push - sent %s to user %d", id, req.UserID)
+	return nil
+}
@@ -0,0 +1,207 @@
+package push
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var deviceRepoMock = new(mocks.IDeviceTokenRepo)
+
+func newPushService(sender Sender) *PushService {
+	deviceRepoMock.ExpectedCalls = nil
+	deviceRepoMock.Calls = nil
+	return NewPushService(logger.NewLogger("error"), deviceRepoMock, map[consttype.DevicePlatform]Sender{
+		consttype.PLATFORM_ANDROID: sender,
+	})
+}
+
+func pushRequest() request.SendPushRequest {
+	return request.SendPushRequest{
+		UserID:   7,
+		Token:    "device-token",
+		Platform: consttype.PLATFORM_ANDROID,
+		Title:    "New feature",
+		Body:     "Try it out.",
+		Data:     map[string]string{"type": "product_update"},
+	}
+}
+
+func TestPush_SendPushShouldSendToDevice(t *testing.T) {
+	fake := NewFakeSender()
+	pushService := newPushService(fake)
+
+	err := pushService.SendPush(pushRequest())
+
+	assert.Nil(t, err)
+	assert.Len(t, fake.Sent(), 1)
+	assert.Equal(t, "device-token", fake.Sent()[0].Token)
+	assert.Equal(t, "product_update", fake.Sent()[0].Message.Data["type"])
+	deviceRepoMock.AssertNotCalled(t, "DeleteByToken", mock.Anything)
+}
+
+func TestPush_SendPushShouldRemoveInvalidToken(t *testing.T) {
+	fake := NewFakeSender()
+	fake.Invalidate("device-token")
+	pushService := newPushService(fake)
+	deviceRepoMock.On("DeleteByToken", "device-token").Return(int64(1), nil).Once()
+
+	err := pushService.SendPush(pushRequest())
+
+	assert.Nil(t, err)
+	deviceRepoMock.AssertExpectations(t)
+}
+
+func TestPush_RegisterDeviceShouldRejectPlatformWithoutSender(t *testing.T) {
+	pushService := newPushService(NewFakeSender())
+
+	_, err := pushService.RegisterDevice(7, request.RegisterDeviceRequest{Token: "ios-token", Platform: "ios"})
+
+	assert.ErrorIs(t, err, ErrPlatformUnavailable)
+	deviceRepoMock.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+func TestPush_RegisterDeviceShouldStoreToken(t *testing.T) {
+	pushService := newPushService(NewFakeSender())
+	device := &model.DeviceToken{UserID: 7, Token: "android-token", Platform: consttype.PLATFORM_ANDROID}
+	deviceRepoMock.On("Store", device).Return(device, nil).Once()
+
+	stored, err := pushService.RegisterDevice(7, request.RegisterDeviceRequest{Token: "android-token", Platform: "android"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, device, stored)
+}
+
+func newFCMSender(t *testing.T, handler http.HandlerFunc) *FCMSender {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Nil(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+			_, err := jwt.Parse(r.Form.Get("assertion"), func(token *jwt.Token) (interface{}, error) {
+				return &key.PublicKey, nil
+			})
+			assert.Nil(t, err)
+			_, _ = w.Write([]byte(`{"access_token":"access-token","expires_in":3600}`))
+			return
+		}
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	credentials, _ := json.Marshal(map[string]string{
+		"client_email": "push@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":    server.URL + "/token",
+	})
+	sender, err := NewFCMSender(server.Client(), "project", credentials)
+	assert.Nil(t, err)
+	sender.endpoint = server.URL
+	return sender
+}
+
+func TestFCMSender_SendShouldReturnMessageName(t *testing.T) {
+	sender := newFCMSender(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/projects/project/messages:send", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"message":{"token":"device-token","notification":{"title":"Hi","body":"There"},"data":{"type":"product_update"}}}`, string(body))
+		_, _ = w.Write([]byte(`{"name":"projects/project/messages/1"}`))
+	})
+
+	id, err := sender.Send("device-token", Message{Title: "Hi", Body: "There", Data: map[string]string{"type": "product_update"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "projects/project/messages/1", id)
+}
+
+func TestFCMSender_SendShouldReturnInvalidTokenWhenUnregistered(t *testing.T) {
+	sender := newFCMSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`))
+	})
+
+	_, err := sender.Send("device-token", Message{Title: "Hi"})
+
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestFCMSender_SendShouldReturnErrorWhenUnavailable(t *testing.T) {
+	sender := newFCMSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE"}}`))
+	})
+
+	_, err := sender.Send("device-token", Message{Title: "Hi"})
+
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidToken))
+}
+
+func newAPNsSender(t *testing.T, handler http.HandlerFunc) *APNsSender {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := jwt.Parse(r.Header.Get("Authorization")[len("bearer "):], func(token *jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "KEYID", token.Header["kid"])
+		assert.Equal(t, "com.example.app", r.Header.Get("apns-topic"))
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	sender, err := NewAPNsSender(server.Client(), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), "KEYID", "TEAMID", "com.example.app", true)
+	assert.Nil(t, err)
+	sender.endpoint = server.URL
+	return sender
+}
+
+func TestAPNsSender_SendShouldReturnApnsID(t *testing.T) {
+	sender := newAPNsSender(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/3/device/device-token", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"aps":{"alert":{"title":"Hi","body":"There"},"sound":"default"},"type":"product_update"}`, string(body))
+		w.Header().Set("apns-id", "apns-id-1")
+	})
+
+	id, err := sender.Send("device-token", Message{Title: "Hi", Body: "There", Data: map[string]string{"type": "product_update"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "apns-id-1", id)
+}
+
+func TestAPNsSender_SendShouldReturnInvalidTokenWhenUnregistered(t *testing.T) {
+	sender := newAPNsSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+		_, _ = w.Write([]byte(`{"reason":"Unregistered","timestamp":1700000000000}`))
+	})
+
+	_, err := sender.Send("device-token", Message{Title: "Hi"})
+
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,80 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+)
+
+// ErrInvalidToken is returned by senders when the provider reports the device
+// token as unknown or expired, the token should not be used again.
+var ErrInvalidToken = errors.New("device token is not valid")
+
+type (
+	// Sender delivers a message to one device token and returns the ID the
+	// provider assigned to it.
+	Sender interface {
+		Send(token string, message Message) (string, error)
+	}
+
+	// Message is what the device shows, Data is passed to the app as is.
+	Message struct {
+		Title string
+		Body  string
+		Data  map[string]string
+	}
+)
+
+// NewSenders returns the sender of each platform selected by PUSH_DRIVER. The
+// live driver only sends to the platforms whose provider is configured.
+func NewSenders(cfg *config.Config, client *http.Client) (map[consttype.DevicePlatform]Sender, error) {
+	if client == nil {
+		client = &http.Client{Timeout: time.Second * 10}
+	}
+
+	switch strings.ToLower(cfg.Push.Driver) {
+	case "", "fake":
+		fake := NewFakeSender()
+		return map[consttype.DevicePlatform]Sender{
+			consttype.PLATFORM_ANDROID: fake,
+			consttype.PLATFORM_IOS:     fake,
+		}, nil
+	case "live":
+		senders := map[consttype.DevicePlatform]Sender{}
+
+		if cfg.Push.FCMProjectID != "" {
+			credentials, err := os.ReadFile(cfg.Push.FCMCredentialsFile)
+			if err != nil {
+				return nil, fmt.Errorf("read fcm credentials: %w", err)
+			}
+			senders[consttype.PLATFORM_ANDROID], err = NewFCMSender(client, cfg.Push.FCMProjectID, credentials)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if cfg.Push.APNsKeyFile != "" {
+			key, err := os.ReadFile(cfg.Push.APNsKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("read apns key: %w", err)
+			}
+			senders[consttype.PLATFORM_IOS], err = NewAPNsSender(client, key, cfg.Push.APNsKeyID, cfg.Push.APNsTeamID, cfg.Push.APNsTopic, cfg.Push.APNsSandbox)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(senders) == 0 {
+			return nil, errors.New("push driver live requires PUSH_FCM_PROJECT_ID or PUSH_APNS_KEY_FILE")
+		}
+		return senders, nil
+	default:
+		return nil, fmt.Errorf("unknown push driver: %s", cfg.Push.Driver)
+	}
+}
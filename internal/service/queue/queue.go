@@ -4,24 +4,49 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/felixlambertv/go-cleanplate/config"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
 	"github.com/felixlambertv/go-cleanplate/internal/service"
 	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	// SQS caps DelaySeconds at 15 minutes. Longer delays carry their due time in
+	// the DeliverAt attribute and are re-enqueued by the worker until due.
+	maxQueueDelay = time.Minute * 15
+	// FIFO queues do not support per-message delays, so delayed messages are
+	// hidden with ChangeMessageVisibility instead, which is capped at 12 hours.
+	maxVisibilityTimeout = time.Hour * 12
+	// Processed keys only need to outlive the longest SQS retention period.
+	processedMessageRetention = time.Hour * 24 * 14
 )
 
 type QueueService struct {
-	sqs sqsiface.SQSAPI
-	cfg *config.Config
-	ms  service.IMailService
+	sqs           sqsiface.SQSAPI
+	cfg           *config.Config
+	ms            service.IMailService
+	ps            service.IPushService
+	sms           service.ISmsService
+	is            service.IImageService
+	ss            service.IScanService
+	processedRepo repository.IProcessedMessageRepo
+	validate      *validator.Validate
 }
 
-func NewQueueService(cfg *config.Config, ms service.IMailService, sqs sqsiface.SQSAPI) *QueueService {
-	return &QueueService{sqs: sqs, cfg: cfg, ms: ms}
+// NewQueueService validates message bodies with validate, which must know the
+// custom tags used by the queued requests, such as mail_template.
+func NewQueueService(cfg *config.Config, ms service.IMailService, ps service.IPushService, sms service.ISmsService, is service.IImageService, ss service.IScanService, sqs sqsiface.SQSAPI, processedRepo repository.IProcessedMessageRepo, validate *validator.Validate) *QueueService {
+	return &QueueService{sqs: sqs, cfg: cfg, ms: ms, ps: ps, sms: sms, is: is, ss: ss, processedRepo: processedRepo, validate: validate}
 }
 
 func (q *QueueService) ReceiveMessage() error {
@@ -29,7 +54,7 @@ func (q *QueueService) ReceiveMessage() error {
 		QueueUrl:              aws.String(q.cfg.Queue.Host),
 		MaxNumberOfMessages:   aws.Int64(10),
 		WaitTimeSeconds:       aws.Int64(20),
-		MessageAttributeNames: []*string{aws.String("Type")},
+		MessageAttributeNames: []*string{aws.String("Type"), aws.String("DeduplicationId"), aws.String("DeliverAt")},
 	})
 
 	if err != nil {
@@ -38,36 +63,16 @@ func (q *QueueService) ReceiveMessage() error {
 	}
 
 	for _, message := range receiveInput.Messages {
-		if message.MessageAttributes != nil {
-			if typeAttr, ok := message.MessageAttributes["Type"]; ok {
-				messageType := *typeAttr.StringValue
-				switch messageType {
-				case consttype.SEND_EMAIL.String():
-					var req request.SendEmailRequest
-					err = json.Unmarshal([]byte(*message.Body), &req)
-					if err != nil {
-						fmt.Println("error unmarshall request")
-						return err
-					}
-
-					validate := validator.New()
-					err := validate.Struct(req)
-					if err != nil {
-						return errors.New("email request not valid")
-					}
-
-					err = q.ms.SendEmail(req)
-					if err != nil {
-						fmt.Println("fail to send email", err)
-						return err
-					}
-					fmt.Println("success send user register email")
-				}
-			}
+		done, err := q.processMessage(message)
+		if err != nil {
+			return err
+		}
 
+		if !done {
+			continue
 		}
 
-		_, err := q.sqs.DeleteMessage(&sqs.DeleteMessageInput{
+		_, err = q.sqs.DeleteMessage(&sqs.DeleteMessageInput{
 			QueueUrl:      aws.String(q.cfg.Queue.Host),
 			ReceiptHandle: message.ReceiptHandle,
 		})
@@ -82,17 +87,234 @@ func (q *QueueService) ReceiveMessage() error {
 	return nil
 }
 
+// processMessage runs the handler for the message type at most once per
+// idempotency key. It reports whether the message can be deleted from the
+// queue.
+func (q *QueueService) processMessage(message *sqs.Message) (bool, error) {
+	messageType := consttype.QueueType(stringAttribute(message, "Type"))
+
+	if deliverAt, ok := messageDeliverAt(message); ok && time.Now().Before(deliverAt) {
+		return q.postponeMessage(message, messageType, deliverAt)
+	}
+
+	if messageType == "" {
+		return true, nil
+	}
+
+	key := idempotencyKey(message)
+	processed, err := q.processedRepo.Exists(key)
+	if err != nil {
+		return false, err
+	}
+
+	if processed {
+		fmt.Println("skip already processed message with key : ", key)
+		return true, nil
+	}
+
+	err = q.handleMessage(messageType, *message.Body)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = q.processedRepo.Store(&model.ProcessedMessage{
+		IdempotencyKey: key,
+		QueueType:      messageType,
+		ProcessedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		// The handler already ran, keeping the message would only run it again.
+		fmt.Println("error store processed message:", err)
+	}
+
+	return true, nil
+}
+
+func (q *QueueService) handleMessage(messageType consttype.QueueType, messageBody string) error {
+	switch messageType {
+	case consttype.SEND_EMAIL:
+		var req request.SendEmailRequest
+		err := json.Unmarshal([]byte(messageBody), &req)
+		if err != nil {
+			fmt.Println("error unmarshall request")
+			return err
+		}
+
+		err = q.validate.Struct(req)
+		if err != nil {
+			return errors.New("email request not valid")
+		}
+
+		err = q.ms.SendEmail(req)
+		if err != nil {
+			fmt.Println("fail to send email", err)
+			return err
+		}
+		fmt.Println("success send user register email")
+	case consttype.SEND_PUSH:
+		var req request.SendPushRequest
+		err := json.Unmarshal([]byte(messageBody), &req)
+		if err != nil {
+			fmt.Println("error unmarshall request")
+			return err
+		}
+
+		err = q.validate.Struct(req)
+		if err != nil {
+			return errors.New("push request not valid")
+		}
+
+		err = q.ps.SendPush(req)
+		if err != nil {
+			fmt.Println("fail to send push", err)
+			return err
+		}
+	case consttype.SEND_SMS:
+		var req request.SendSmsRequest
+		err := json.Unmarshal([]byte(messageBody), &req)
+		if err != nil {
+			fmt.Println("error unmarshall request")
+			return err
+		}
+
+		err = q.validate.Struct(req)
+		if err != nil {
+			return errors.New("sms request not valid")
+		}
+
+		err = q.sms.SendSms(req)
+		if err != nil {
+			fmt.Println("fail to send sms", err)
+			return err
+		}
+	case consttype.PROCESS_MEDIA:
+		var req request.ProcessMediaRequest
+		err := json.Unmarshal([]byte(messageBody), &req)
+		if err != nil {
+			fmt.Println("error unmarshall request")
+			return err
+		}
+
+		err = q.validate.Struct(req)
+		if err != nil {
+			return errors.New("process media request not valid")
+		}
+
+		err = q.is.ProcessMedia(req)
+		if err != nil {
+			fmt.Println("fail to process media", err)
+			return err
+		}
+	case consttype.SCAN_MEDIA:
+		var req request.ScanMediaRequest
+		err := json.Unmarshal([]byte(messageBody), &req)
+		if err != nil {
+			fmt.Println("error unmarshall request")
+			return err
+		}
+
+		err = q.validate.Struct(req)
+		if err != nil {
+			return errors.New("scan media request not valid")
+		}
+
+		err = q.ss.ScanMedia(req)
+		if err != nil {
+			fmt.Println("fail to scan media", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *QueueService) postponeMessage(message *sqs.Message, messageType consttype.QueueType, deliverAt time.Time) (bool, error) {
+	if q.isFifo() {
+		timeout := time.Until(deliverAt)
+		if timeout > maxVisibilityTimeout {
+			timeout = maxVisibilityTimeout
+		}
+
+		_, err := q.sqs.ChangeMessageVisibility(&sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(q.cfg.Queue.Host),
+			ReceiptHandle:     message.ReceiptHandle,
+			VisibilityTimeout: aws.Int64(int64(timeout.Seconds())),
+		})
+		if err != nil {
+			fmt.Println("error postpone delayed message:", err)
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	err := q.SendDelayedMessage(*message.Body, messageType, idempotencyKey(message), deliverAt)
+	if err != nil {
+		fmt.Println("error requeue delayed message:", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (q *QueueService) SendMessage(messageBody string, messageType consttype.QueueType) error {
-	result, err := q.sqs.SendMessage(&sqs.SendMessageInput{
-		MessageBody: aws.String(messageBody),
-		QueueUrl:    aws.String(q.cfg.Queue.Host),
-		MessageAttributes: map[string]*sqs.MessageAttributeValue{
-			"Type": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(messageType.String()),
-			},
+	return q.SendMessageWithDeduplication(messageBody, messageType, "")
+}
+
+// SendMessageWithDeduplication sends a message carrying the given idempotency
+// key. An empty key is replaced by a random one, so every message has a key.
+func (q *QueueService) SendMessageWithDeduplication(messageBody string, messageType consttype.QueueType, deduplicationId string) error {
+	return q.SendDelayedMessage(messageBody, messageType, deduplicationId, time.Time{})
+}
+
+// SendDelayedMessage sends a message that will not be handled before
+// deliverAt. A zero deliverAt sends the message immediately.
+func (q *QueueService) SendDelayedMessage(messageBody string, messageType consttype.QueueType, deduplicationId string, deliverAt time.Time) error {
+	if deduplicationId == "" {
+		deduplicationId = uuid.Must(uuid.NewRandom()).String()
+	}
+
+	messageAttributes := map[string]*sqs.MessageAttributeValue{
+		"Type": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(messageType.String()),
 		},
-	})
+		"DeduplicationId": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(deduplicationId),
+		},
+	}
+
+	input := &sqs.SendMessageInput{
+		MessageBody:       aws.String(messageBody),
+		QueueUrl:          aws.String(q.cfg.Queue.Host),
+		MessageAttributes: messageAttributes,
+	}
+
+	delayed := !deliverAt.IsZero() && time.Now().Before(deliverAt)
+	if delayed {
+		delay := time.Until(deliverAt)
+		if delay > maxQueueDelay || q.isFifo() {
+			messageAttributes["DeliverAt"] = &sqs.MessageAttributeValue{
+				DataType:    aws.String("String"),
+				StringValue: aws.String(deliverAt.UTC().Format(time.RFC3339)),
+			}
+		}
+
+		if !q.isFifo() {
+			if delay > maxQueueDelay {
+				delay = maxQueueDelay
+			}
+			input.DelaySeconds = aws.Int64(int64(delay.Seconds()))
+		}
+	}
+
+	if q.isFifo() {
+		input.MessageGroupId = aws.String(messageGroupId(messageType, deduplicationId, delayed))
+		input.MessageDeduplicationId = aws.String(deduplicationId)
+	}
+
+	result, err := q.sqs.SendMessage(input)
 
 	if err != nil {
 		fmt.Println("error sending massage to queue:", err)
@@ -102,3 +324,57 @@ func (q *QueueService) SendMessage(messageBody string, messageType consttype.Que
 	fmt.Println("message sent to queue with ID : ", *result.MessageId)
 	return nil
 }
+
+func (q *QueueService) PurgeProcessedMessages() error {
+	_, err := q.processedRepo.DeleteBefore(time.Now().UTC().Add(-processedMessageRetention))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (q *QueueService) isFifo() bool {
+	return strings.HasSuffix(q.cfg.Queue.Host, ".fifo")
+}
+
+// messageGroupId keeps messages of the same type in order on FIFO queues.
+// Delayed messages get a group of their own, otherwise hiding them until they
+// are due would hold back every other message of their type.
+func messageGroupId(messageType consttype.QueueType, deduplicationId string, delayed bool) string {
+	if delayed {
+		return messageType.String() + "-" + deduplicationId
+	}
+	return messageType.String()
+}
+
+// idempotencyKey falls back to the SQS message ID for messages sent without a
+// key, which still protects against redelivery of the same message.
+func idempotencyKey(message *sqs.Message) string {
+	if key := stringAttribute(message, "DeduplicationId"); key != "" {
+		return key
+	}
+	return aws.StringValue(message.MessageId)
+}
+
+func stringAttribute(message *sqs.Message, name string) string {
+	attr, ok := message.MessageAttributes[name]
+	if !ok || attr.StringValue == nil {
+		return ""
+	}
+	return *attr.StringValue
+}
+
+func messageDeliverAt(message *sqs.Message) (time.Time, bool) {
+	value := stringAttribute(message, "DeliverAt")
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	deliverAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return deliverAt, true
+}
@@ -1,15 +1,21 @@
 package queue
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/felixlambertv/go-cleanplate/config"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/service/mail"
 	"github.com/felixlambertv/go-cleanplate/mocks"
 	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,18 +25,56 @@ var cfg = &config.Config{
 		Region: "ap-southeast-2",
 	},
 	Queue: config.Queue{
-		"https://sqs.ap-southeast-2.amazonaws.com/xx/obrien-test-email-queue",
+		Host: "https://sqs.ap-southeast-2.amazonaws.com/xx/obrien-test-email-queue",
 	},
 }
 
 var mailServiceMock = new(mocks.IMailService)
 var sqsMock = new(mocks.SQSAPI)
-var queueService = NewQueueService(cfg, mailServiceMock, sqsMock)
+var pushServiceMock = new(mocks.IPushService)
+var smsServiceMock = new(mocks.ISmsService)
+var imageServiceMock = new(mocks.IImageService)
+var scanServiceMock = new(mocks.IScanService)
+var processedRepoMock = new(mocks.IProcessedMessageRepo)
+var validate = newValidator()
+var queueService = NewQueueService(cfg, mailServiceMock, pushServiceMock, smsServiceMock, imageServiceMock, scanServiceMock, sqsMock, processedRepoMock, validate)
+
+var fifoCfg = &config.Config{
+	Queue: config.Queue{
+		Host: "https://sqs.ap-southeast-2.amazonaws.com/xx/obrien-test-email-queue.fifo",
+	},
+}
+var fifoQueueService = NewQueueService(fifoCfg, mailServiceMock, pushServiceMock, smsServiceMock, imageServiceMock, scanServiceMock, sqsMock, processedRepoMock, validate)
 
 var messageOutput = &sqs.SendMessageOutput{
 	MessageId: aws.String("messageId"),
 }
 
+func newValidator() *validator.Validate {
+	templates, err := mail.NewTemplateRegistry(logger.NewLogger("error"), "../../../templates", false)
+	if err != nil {
+		panic(err)
+	}
+
+	v := validator.New()
+	if err := templates.RegisterValidation(v); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func resetPasswordEmail() request.SendEmailRequest {
+	req, err := request.NewSendEmailRequest(request.ResetPasswordData{
+		Name:    "Name Test",
+		LinkUrl: "https://example.com/app/reset-password/token",
+	}, request.EmailAddress{Name: "Name Test", Email: "test@test.com"})
+	if err != nil {
+		panic(err)
+	}
+	req.Subject = "Subject Test"
+	return req
+}
+
 func TestMain(m *testing.M) {
 	m.Run()
 }
@@ -50,6 +94,70 @@ func TestQueueService_SendMessage_ShouldSuccess(t *testing.T) {
 	assert.Equal(t, messageString, *args.MessageBody)
 	assert.Equal(t, cfg.Queue.Host, *args.QueueUrl)
 	assert.Equal(t, consttype.SEND_EMAIL.String(), *args.MessageAttributes["Type"].StringValue)
+	assert.NotEmpty(t, *args.MessageAttributes["DeduplicationId"].StringValue)
+	assert.Nil(t, args.MessageGroupId)
+}
+
+func TestQueueService_SendMessage_ShouldSetGroupAndDeduplicationIdOnFifoQueue(t *testing.T) {
+	sqsMock.Calls = nil
+	sqsMock.On("SendMessage", mock.Anything).Return(messageOutput, nil).Once()
+
+	err := fifoQueueService.SendMessageWithDeduplication("test", consttype.SEND_EMAIL, "dedup-id")
+	assert.Equal(t, err, nil)
+
+	args := sqsMock.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, consttype.SEND_EMAIL.String(), *args.MessageGroupId)
+	assert.Equal(t, "dedup-id", *args.MessageDeduplicationId)
+}
+
+func TestQueueService_SendDelayedMessage_ShouldUseOwnGroupWithoutDelayOnFifoQueue(t *testing.T) {
+	sqsMock.Calls = nil
+	sqsMock.On("SendMessage", mock.Anything).Return(messageOutput, nil).Once()
+
+	err := fifoQueueService.SendDelayedMessage("test", consttype.SEND_EMAIL, "dedup-id", time.Now().Add(time.Minute*2))
+	assert.Equal(t, err, nil)
+
+	args := sqsMock.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Nil(t, args.DelaySeconds)
+	assert.Equal(t, "send_email-dedup-id", *args.MessageGroupId)
+	assert.NotNil(t, args.MessageAttributes["DeliverAt"])
+}
+
+func TestQueueService_SendMessageWithDeduplication_ShouldSetDeduplicationAttribute(t *testing.T) {
+	sqsMock.Calls = nil
+	sqsMock.On("SendMessage", mock.Anything).Return(messageOutput, nil).Once()
+
+	err := queueService.SendMessageWithDeduplication("test", consttype.SEND_EMAIL, "dedup-id")
+	assert.Equal(t, err, nil)
+
+	args := sqsMock.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, "dedup-id", *args.MessageAttributes["DeduplicationId"].StringValue)
+	assert.Equal(t, consttype.SEND_EMAIL.String(), *args.MessageAttributes["Type"].StringValue)
+}
+
+func TestQueueService_SendDelayedMessage_ShouldCapDelayAndCarryDeliverAt(t *testing.T) {
+	sqsMock.Calls = nil
+	sqsMock.On("SendMessage", mock.Anything).Return(messageOutput, nil).Once()
+
+	deliverAt := time.Now().Add(time.Hour * 24)
+	err := queueService.SendDelayedMessage("test", consttype.SEND_EMAIL, "dedup-id", deliverAt)
+	assert.Equal(t, err, nil)
+
+	args := sqsMock.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, int64(maxQueueDelay.Seconds()), *args.DelaySeconds)
+	assert.Equal(t, deliverAt.UTC().Format(time.RFC3339), *args.MessageAttributes["DeliverAt"].StringValue)
+}
+
+func TestQueueService_SendDelayedMessage_ShouldOnlyDelayWhenShortDelay(t *testing.T) {
+	sqsMock.Calls = nil
+	sqsMock.On("SendMessage", mock.Anything).Return(messageOutput, nil).Once()
+
+	err := queueService.SendDelayedMessage("test", consttype.SEND_EMAIL, "", time.Now().Add(time.Minute*2))
+	assert.Equal(t, err, nil)
+
+	args := sqsMock.Calls[0].Arguments[0].(*sqs.SendMessageInput)
+	assert.InDelta(t, 120, *args.DelaySeconds, 1)
+	assert.Nil(t, args.MessageAttributes["DeliverAt"])
 }
 
 func TestQueueService_SendMessage_ShouldReturnErrorWhenFailSendMessage(t *testing.T) {
@@ -64,12 +172,7 @@ func TestQueueService_SendMessage_ShouldReturnErrorWhenFailSendMessage(t *testin
 
 func TestQueueService_ReceiveMessage_ShouldSuccessReceiveMessage(t *testing.T) {
 	sqsMock.Calls = nil
-	sendEmailReq := request.SendEmailRequest{
-		Template: "reset_password.html",
-		Subject:  "Subject Test",
-		Name:     "Name Test",
-		Email:    "test@test.com",
-	}
+	sendEmailReq := resetPasswordEmail()
 
 	messageBody := sendEmailReq.ToString()
 	messageAttribute := make(map[string]*sqs.MessageAttributeValue)
@@ -89,13 +192,200 @@ func TestQueueService_ReceiveMessage_ShouldSuccessReceiveMessage(t *testing.T) {
 	}
 	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
 	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "test-message-id-1").Return(false, nil).Once()
+	processedRepoMock.On("Store", mock.Anything).Return(&model.ProcessedMessage{}, nil).Once()
 	mailServiceMock.On("SendEmail", mock.Anything).Return(nil).Once()
 	err := queueService.ReceiveMessage()
 
 	sqsMock.AssertNumberOfCalls(t, "ReceiveMessage", 1)
 	sqsMock.AssertNumberOfCalls(t, "DeleteMessage", 1)
 	mailServiceMock.AssertNumberOfCalls(t, "SendEmail", 1)
+	processedRepoMock.AssertCalled(t, "Store", mock.MatchedBy(func(m *model.ProcessedMessage) bool {
+		return m.IdempotencyKey == "test-message-id-1" && m.QueueType == consttype.SEND_EMAIL
+	}))
+	assert.Equal(t, nil, err)
+}
+
+func TestQueueService_ReceiveMessage_ShouldSendPush(t *testing.T) {
+	sqsMock.Calls = nil
+	pushReq := request.SendPushRequest{
+		UserID:   1,
+		Token:    "device-token",
+		Platform: consttype.PLATFORM_ANDROID,
+		Title:    "Title Test",
+		Body:     "Body Test",
+	}
+	messageAttribute := map[string]*sqs.MessageAttributeValue{
+		"Type": {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_PUSH.String())},
+	}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body:              aws.String(pushReq.ToString()),
+				MessageAttributes: messageAttribute,
+				ReceiptHandle:     aws.String("test-receipt-handle-1"),
+				MessageId:         aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "test-message-id-1").Return(false, nil).Once()
+	processedRepoMock.On("Store", mock.Anything).Return(&model.ProcessedMessage{}, nil).Once()
+	pushServiceMock.On("SendPush", pushReq).Return(nil).Once()
+
+	err := queueService.ReceiveMessage()
+
 	assert.Equal(t, nil, err)
+	pushServiceMock.AssertExpectations(t)
+	sqsMock.AssertNumberOfCalls(t, "DeleteMessage", 1)
+}
+
+func TestQueueService_ReceiveMessage_ShouldSendSms(t *testing.T) {
+	sqsMock.Calls = nil
+	smsReq := request.SendSmsRequest{
+		UserID: 1,
+		To:     "+6281234567890",
+		Body:   "Title Test",
+	}
+	messageAttribute := map[string]*sqs.MessageAttributeValue{
+		"Type": {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_SMS.String())},
+	}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body:              aws.String(smsReq.ToString()),
+				MessageAttributes: messageAttribute,
+				ReceiptHandle:     aws.String("test-receipt-handle-1"),
+				MessageId:         aws.String("test-message-id-sms"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "test-message-id-sms").Return(false, nil).Once()
+	processedRepoMock.On("Store", mock.Anything).Return(&model.ProcessedMessage{}, nil).Once()
+	smsServiceMock.On("SendSms", smsReq).Return(nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	smsServiceMock.AssertExpectations(t)
+	sqsMock.AssertNumberOfCalls(t, "DeleteMessage", 1)
+}
+
+func TestQueueService_ReceiveMessage_ShouldProcessMedia(t *testing.T) {
+	sqsMock.Calls = nil
+	processReq := request.ProcessMediaRequest{MediaID: 3}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body: aws.String(processReq.ToString()),
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{
+					"Type": {DataType: aws.String("String"), StringValue: aws.String(consttype.PROCESS_MEDIA.String())},
+				},
+				ReceiptHandle: aws.String("test-receipt-handle-1"),
+				MessageId:     aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "test-message-id-1").Return(false, nil).Once()
+	processedRepoMock.On("Store", mock.Anything).Return(&model.ProcessedMessage{}, nil).Once()
+	imageServiceMock.On("ProcessMedia", processReq).Return(nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	imageServiceMock.AssertExpectations(t)
+	sqsMock.AssertNumberOfCalls(t, "DeleteMessage", 1)
+}
+
+func TestQueueService_ReceiveMessage_ShouldScanMedia(t *testing.T) {
+	sqsMock.Calls = nil
+	scanReq := request.ScanMediaRequest{MediaID: 3}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body: aws.String(scanReq.ToString()),
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{
+					"Type": {DataType: aws.String("String"), StringValue: aws.String(consttype.SCAN_MEDIA.String())},
+				},
+				ReceiptHandle: aws.String("test-receipt-handle-1"),
+				MessageId:     aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "test-message-id-1").Return(false, nil).Once()
+	processedRepoMock.On("Store", mock.Anything).Return(&model.ProcessedMessage{}, nil).Once()
+	scanServiceMock.On("ScanMedia", scanReq).Return(nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	scanServiceMock.AssertExpectations(t)
+	sqsMock.AssertNumberOfCalls(t, "DeleteMessage", 1)
+}
+
+func TestQueueService_ReceiveMessage_ShouldSkipHandlerWhenAlreadyProcessed(t *testing.T) {
+	sqsMock.Calls = nil
+	mailServiceMock.Calls = nil
+	messageAttribute := map[string]*sqs.MessageAttributeValue{
+		"Type":            {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_EMAIL.String())},
+		"DeduplicationId": {DataType: aws.String("String"), StringValue: aws.String("already-processed")},
+	}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body:              aws.String("{}"),
+				MessageAttributes: messageAttribute,
+				ReceiptHandle:     aws.String("test-receipt-handle-1"),
+				MessageId:         aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "already-processed").Return(true, nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	mailServiceMock.AssertNotCalled(t, "SendEmail", mock.Anything)
+	sqsMock.AssertNumberOfCalls(t, "DeleteMessage", 1)
+}
+
+func TestQueueService_ReceiveMessage_ShouldKeepMessageWhenHandlerFails(t *testing.T) {
+	sqsMock.Calls = nil
+	sendEmailReq := resetPasswordEmail()
+	messageAttribute := map[string]*sqs.MessageAttributeValue{
+		"Type":            {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_EMAIL.String())},
+		"DeduplicationId": {DataType: aws.String("String"), StringValue: aws.String("failing-key")},
+	}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body:              aws.String(sendEmailReq.ToString()),
+				MessageAttributes: messageAttribute,
+				ReceiptHandle:     aws.String("test-receipt-handle-1"),
+				MessageId:         aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	processedRepoMock.On("Exists", "failing-key").Return(false, nil).Once()
+	mailServiceMock.On("SendEmail", mock.Anything).Return(errors.New("smtp down")).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, errors.New("smtp down"), err)
+	sqsMock.AssertNotCalled(t, "DeleteMessage", mock.Anything)
+	processedRepoMock.AssertNotCalled(t, "Store", mock.MatchedBy(func(m *model.ProcessedMessage) bool {
+		return m.IdempotencyKey == "failing-key"
+	}))
 }
 
 func TestQueueService_ReceiveMessage_ShouldDoNothingWhenNoMessage(t *testing.T) {
@@ -110,12 +400,7 @@ func TestQueueService_ReceiveMessage_ShouldDoNothingWhenNoMessage(t *testing.T)
 
 func TestQueueService_ReceiveMessage_ShouldDeleteMessageWhenMessageAttributeNotDefined(t *testing.T) {
 	sqsMock.Calls = nil
-	sendEmailReq := request.SendEmailRequest{
-		Template: "reset_password.html",
-		Subject:  "Subject Test",
-		Name:     "Name Test",
-		Email:    "test@test.com",
-	}
+	sendEmailReq := resetPasswordEmail()
 
 	messageBody := sendEmailReq.ToString()
 	messageAttribute := make(map[string]*sqs.MessageAttributeValue)
@@ -154,3 +439,120 @@ func TestQueueService_ReceiveMessage_ShouldReturnErrorWhenErrorReceiveMessage(t
 	sqsMock.AssertNumberOfCalls(t, "ReceiveMessage", 1)
 	assert.Equal(t, sqsError, err)
 }
+
+func TestQueueService_ReceiveMessage_ShouldRequeueMessageWhenNotYetDue(t *testing.T) {
+	sqsMock.Calls = nil
+	mailServiceMock.Calls = nil
+	deliverAt := time.Now().Add(time.Hour * 2).UTC().Format(time.RFC3339)
+	messageAttribute := map[string]*sqs.MessageAttributeValue{
+		"Type":      {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_EMAIL.String())},
+		"DeliverAt": {DataType: aws.String("String"), StringValue: aws.String(deliverAt)},
+	}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body:              aws.String("{}"),
+				MessageAttributes: messageAttribute,
+				ReceiptHandle:     aws.String("test-receipt-handle-1"),
+				MessageId:         aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("SendMessage", mock.Anything).Return(messageOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	mailServiceMock.AssertNotCalled(t, "SendEmail", mock.Anything)
+	args := sqsMock.Calls[1].Arguments[0].(*sqs.SendMessageInput)
+	assert.Equal(t, deliverAt, *args.MessageAttributes["DeliverAt"].StringValue)
+	assert.Equal(t, consttype.SEND_EMAIL.String(), *args.MessageAttributes["Type"].StringValue)
+}
+
+func TestQueueService_ReceiveMessage_ShouldHideDelayedMessageOnFifoQueue(t *testing.T) {
+	sqsMock.Calls = nil
+	deliverAt := time.Now().Add(time.Hour * 2).UTC().Format(time.RFC3339)
+	messageAttribute := map[string]*sqs.MessageAttributeValue{
+		"Type":      {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_EMAIL.String())},
+		"DeliverAt": {DataType: aws.String("String"), StringValue: aws.String(deliverAt)},
+	}
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body:              aws.String("{}"),
+				MessageAttributes: messageAttribute,
+				ReceiptHandle:     aws.String("test-receipt-handle-1"),
+				MessageId:         aws.String("test-message-id-1"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("ChangeMessageVisibility", mock.Anything).Return(nil, nil).Once()
+
+	err := fifoQueueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	sqsMock.AssertNotCalled(t, "DeleteMessage", mock.Anything)
+	args := sqsMock.Calls[1].Arguments[0].(*sqs.ChangeMessageVisibilityInput)
+	assert.InDelta(t, 7200, *args.VisibilityTimeout, 2)
+}
+
+func TestQueueService_ReceiveMessage_ShouldAcceptLegacyEmailPayload(t *testing.T) {
+	sqsMock.Calls = nil
+	mailServiceMock.Calls = nil
+	legacyBody := `{"Template":"verify_email.html","Subject":"Verification Code","Name":"Name Test","Email":"test@test.com","Token":8128,"LinkUrl":""}`
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body: aws.String(legacyBody),
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{
+					"Type": {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_EMAIL.String())},
+				},
+				ReceiptHandle: aws.String("test-receipt-handle-1"),
+				MessageId:     aws.String("legacy-message-id"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	sqsMock.On("DeleteMessage", mock.Anything).Return(nil, nil).Once()
+	processedRepoMock.On("Exists", "legacy-message-id").Return(false, nil).Once()
+	processedRepoMock.On("Store", mock.Anything).Return(&model.ProcessedMessage{}, nil).Once()
+	mailServiceMock.On("SendEmail", mock.Anything).Return(nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, nil, err)
+	mailServiceMock.AssertCalled(t, "SendEmail", mock.MatchedBy(func(req request.SendEmailRequest) bool {
+		data, _ := req.TemplateData()
+		return len(req.To) == 1 && req.To[0].Email == "test@test.com" && data["Token"] == json.Number("8128")
+	}))
+}
+
+func TestQueueService_ReceiveMessage_ShouldRejectInvalidTemplateData(t *testing.T) {
+	sqsMock.Calls = nil
+	mailServiceMock.Calls = nil
+	sendEmailReq := resetPasswordEmail()
+	sendEmailReq.Data = []byte(`{"Name":"Name Test","LinkUrl":"not a url"}`)
+	receiveOutput := &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{
+				Body: aws.String(sendEmailReq.ToString()),
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{
+					"Type": {DataType: aws.String("String"), StringValue: aws.String(consttype.SEND_EMAIL.String())},
+				},
+				ReceiptHandle: aws.String("test-receipt-handle-1"),
+				MessageId:     aws.String("invalid-data-message-id"),
+			},
+		},
+	}
+	sqsMock.On("ReceiveMessage", mock.Anything).Return(receiveOutput, nil).Once()
+	processedRepoMock.On("Exists", "invalid-data-message-id").Return(false, nil).Once()
+
+	err := queueService.ReceiveMessage()
+
+	assert.Equal(t, "email request not valid", err.Error())
+	mailServiceMock.AssertNotCalled(t, "SendEmail", mock.Anything)
+	sqsMock.AssertNotCalled(t, "DeleteMessage", mock.Anything)
+}
@@ -0,0 +1,183 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/internal/repository"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// Job is a unit of periodic work registered in code. Spec uses the standard
+// five field cron syntax or descriptors such as "@hourly" and "@every 15m".
+type Job struct {
+	Name string
+	Spec string
+	Run  func() error
+}
+
+type registeredJob struct {
+	job     Job
+	entryID cron.EntryID
+}
+
+type SchedulerService struct {
+	l       logger.Interface
+	jobRepo repository.IJobRepo
+	cron    *cron.Cron
+
+	mu   sync.RWMutex
+	jobs []registeredJob
+}
+
+func NewSchedulerService(jobRepo repository.IJobRepo, l logger.Interface) *SchedulerService {
+	return &SchedulerService{
+		l:       l,
+		jobRepo: jobRepo,
+		cron:    cron.New(cron.WithLocation(time.UTC)),
+	}
+}
+
+func (s *SchedulerService) Register(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, registered := range s.jobs {
+		if registered.job.Name == job.Name {
+			return fmt.Errorf("job %s is already registered", job.Name)
+		}
+	}
+
+	entryID, err := s.cron.AddFunc(job.Spec, func() {
+		s.run(job, consttype.JOB_TRIGGER_SCHEDULE)
+	})
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.jobs = append(s.jobs, registeredJob{job: job, entryID: entryID})
+	return nil
+}
+
+func (s *SchedulerService) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish.
+func (s *SchedulerService) Stop() {
+	<-s.cron.Stop().Done()
+}
+
+func (s *SchedulerService) GetJobs() ([]response.JobResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]response.JobResponse, 0, len(s.jobs))
+	for _, registered := range s.jobs {
+		lastRun, err := s.jobRepo.FindLastRun(registered.job.Name)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		jobs = append(jobs, response.JobResponse{
+			Name:      registered.job.Name,
+			Spec:      registered.job.Spec,
+			NextRunAt: s.cron.Entry(registered.entryID).Next,
+			LastRun:   lastRun,
+		})
+	}
+
+	return jobs, nil
+}
+
+func (s *SchedulerService) GetJobRuns(name string, p model.Pagination) (*model.Pagination, error) {
+	if _, ok := s.findJob(name); !ok {
+		return nil, errors.New("job not found")
+	}
+
+	return s.jobRepo.FindRuns(name, p)
+}
+
+// TriggerJob runs the job immediately in the background. The run still goes
+// through the advisory lock, so it is skipped if another replica is running
+// the same job.
+func (s *SchedulerService) TriggerJob(name string) error {
+	job, ok := s.findJob(name)
+	if !ok {
+		return errors.New("job not found")
+	}
+
+	go s.run(job, consttype.JOB_TRIGGER_MANUAL)
+	return nil
+}
+
+func (s *SchedulerService) findJob(name string) (Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, registered := range s.jobs {
+		if registered.job.Name == name {
+			return registered.job, true
+		}
+	}
+
+	return Job{}, false
+}
+
+func (s *SchedulerService) run(job Job, trigger consttype.JobTrigger) {
+	unlock, acquired, err := s.jobRepo.TryLock(job.Name)
+	if err != nil {
+		s.l.Error(fmt.Errorf("scheduler - lock job %s: %w", job.Name, err))
+		return
+	}
+
+	if !acquired {
+		s.l.Info("scheduler - job %s is running on another instance, skipping", job.Name)
+		return
+	}
+	defer unlock()
+
+	jobRun, err := s.jobRepo.StoreRun(&model.JobRun{
+		JobName:   job.Name,
+		Trigger:   trigger,
+		Status:    consttype.JOB_RUNNING,
+		StartedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.l.Error(fmt.Errorf("scheduler - store run %s: %w", job.Name, err))
+		return
+	}
+
+	err = runSafely(job)
+
+	finishedAt := time.Now().UTC()
+	jobRun.FinishedAt = &finishedAt
+	jobRun.Status = consttype.JOB_SUCCEEDED
+	if err != nil {
+		jobRun.Status = consttype.JOB_FAILED
+		jobRun.Error = err.Error()
+		s.l.Error(fmt.Errorf("scheduler - run %s: %w", job.Name, err))
+	}
+
+	err = s.jobRepo.UpdateRun(*jobRun)
+	if err != nil {
+		s.l.Error(fmt.Errorf("scheduler - update run %s: %w", job.Name, err))
+	}
+}
+
+func runSafely(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Run()
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var jobRepoMock = new(mocks.IJobRepo)
+var loggerMock = new(mocks.Interface)
+
+func BeforeEachSchedulerTest() *SchedulerService {
+	jobRepoMock.ExpectedCalls = nil
+	jobRepoMock.Calls = nil
+	loggerMock.ExpectedCalls = nil
+	loggerMock.On("Error", mock.Anything).Return()
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	return NewSchedulerService(jobRepoMock, loggerMock)
+}
+
+func TestScheduler_RegisterShouldRejectInvalidSpec(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+
+	err := s.Register(Job{Name: "invalid", Spec: "not a cron spec", Run: func() error { return nil }})
+
+	assert.Error(t, err)
+}
+
+func TestScheduler_RegisterShouldRejectDuplicateName(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+	job := Job{Name: "duplicate", Spec: "@hourly", Run: func() error { return nil }}
+
+	assert.Nil(t, s.Register(job))
+	assert.Equal(t, errors.New("job duplicate is already registered"), s.Register(job))
+}
+
+func TestScheduler_RunShouldRecordSucceededRun(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+	unlocked := false
+	jobRepoMock.On("TryLock", "job").Return(func() { unlocked = true }, true, nil).Once()
+	jobRepoMock.On("StoreRun", mock.Anything).Return(&model.JobRun{ID: 1}, nil).Once()
+	jobRepoMock.On("UpdateRun", mock.Anything).Return(nil).Once()
+
+	s.run(Job{Name: "job", Spec: "@hourly", Run: func() error { return nil }}, consttype.JOB_TRIGGER_MANUAL)
+
+	stored := jobRepoMock.Calls[1].Arguments[0].(*model.JobRun)
+	updated := jobRepoMock.Calls[2].Arguments[0].(model.JobRun)
+	assert.Equal(t, consttype.JOB_TRIGGER_MANUAL, stored.Trigger)
+	assert.Equal(t, consttype.JOB_SUCCEEDED, updated.Status)
+	assert.NotNil(t, updated.FinishedAt)
+	assert.True(t, unlocked)
+}
+
+func TestScheduler_RunShouldRecordFailedRunWhenJobPanics(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+	jobRepoMock.On("TryLock", "job").Return(func() {}, true, nil).Once()
+	jobRepoMock.On("StoreRun", mock.Anything).Return(&model.JobRun{ID: 1}, nil).Once()
+	jobRepoMock.On("UpdateRun", mock.Anything).Return(nil).Once()
+
+	s.run(Job{Name: "job", Spec: "@hourly", Run: func() error { panic("boom") }}, consttype.JOB_TRIGGER_SCHEDULE)
+
+	updated := jobRepoMock.Calls[2].Arguments[0].(model.JobRun)
+	assert.Equal(t, consttype.JOB_FAILED, updated.Status)
+	assert.Equal(t, "panic: boom", updated.Error)
+}
+
+func TestScheduler_RunShouldSkipWhenLockIsHeldElsewhere(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+	ran := false
+	jobRepoMock.On("TryLock", "job").Return(nil, false, nil).Once()
+
+	s.run(Job{Name: "job", Spec: "@hourly", Run: func() error { ran = true; return nil }}, consttype.JOB_TRIGGER_SCHEDULE)
+
+	assert.False(t, ran)
+	jobRepoMock.AssertNotCalled(t, "StoreRun", mock.Anything)
+}
+
+func TestScheduler_GetJobsShouldIncludeLastRun(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+	lastRun := &model.JobRun{ID: 3, JobName: "with-run", Status: consttype.JOB_SUCCEEDED}
+	assert.Nil(t, s.Register(Job{Name: "with-run", Spec: "@hourly", Run: func() error { return nil }}))
+	assert.Nil(t, s.Register(Job{Name: "never-run", Spec: "@daily", Run: func() error { return nil }}))
+	jobRepoMock.On("FindLastRun", "with-run").Return(lastRun, nil).Once()
+	jobRepoMock.On("FindLastRun", "never-run").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	jobs, err := s.GetJobs()
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, lastRun, jobs[0].LastRun)
+	assert.Nil(t, jobs[1].LastRun)
+	assert.Equal(t, "@daily", jobs[1].Spec)
+}
+
+func TestScheduler_TriggerJobShouldReturnErrorWhenJobNotFound(t *testing.T) {
+	s := BeforeEachSchedulerTest()
+
+	err := s.TriggerJob("missing")
+
+	assert.Equal(t, errors.New("job not found"), err)
+}
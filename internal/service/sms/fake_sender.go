@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// FakeSender prints messages instead of sending them.
+	FakeSender struct {
+		mu   sync.Mutex
+		sent []SentMessage
+	}
+
+	SentMessage struct {
+		ID   string
+		To   string
+		Body string
+	}
+)
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{}
+}
+
+func (f *FakeSender) Send(to string, body string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := uuid.Must(uuid.NewRandom()).String()
+	f.sent = append(f.sent, SentMessage{ID: id, To: to, Body: body})
+	fmt.Printf("SMS sent to %s: %s\n", to, body)
+
+	return id, nil
+}
+
+func (f *FakeSender) Sent() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]SentMessage(nil), f.sent...)
+}
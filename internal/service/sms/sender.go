@@ -0,0 +1,24 @@
+package sms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+)
+
+// Sender delivers a text message to one phone number and returns the ID the
+// provider assigned to it.
+type Sender interface {
+	Send(to string, body string) (string, error)
+}
+
+// NewSender returns the sender selected by SMS_DRIVER.
+func NewSender(cfg *config.Config) (Sender, error) {
+	switch strings.ToLower(cfg.Sms.Driver) {
+	case "", "fake":
+		return NewFakeSender(), nil
+	default:
+		return nil, fmt.Errorf("unknown sms driver: %s", cfg.Sms.Driver)
+	}
+}
@@ -0,0 +1,26 @@
+package sms
+
+import (
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+)
+
+// SmsService delivers the messages queued by the SMS notification channel.
+type SmsService struct {
+	l      logger.Interface
+	sender Sender
+}
+
+func NewSmsService(l logger.Interface, sender Sender) *SmsService {
+	return &SmsService{l: l, sender: sender}
+}
+
+func (s *SmsService) SendSms(req request.SendSmsRequest) error {
+	id, err := s.sender.Send(req.To, req.Body)
+	if err != nil {
+		return err
+	}
+
+	s.l.Info("sms - sent %s to user %d", id, req.UserID)
+	return nil
+}
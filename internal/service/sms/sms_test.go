@@ -0,0 +1,32 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	"github.com/felixlambertv/go-cleanplate/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSms_SendSmsShouldSendToNumber(t *testing.T) {
+	fake := NewFakeSender()
+	smsService := NewSmsService(logger.NewLogger("error"), fake)
+
+	err := smsService.SendSms(request.SendSmsRequest{UserID: 7, To: "+6281234567890", Body: "New feature"})
+
+	assert.Nil(t, err)
+	assert.Len(t, fake.Sent(), 1)
+	assert.Equal(t, "+6281234567890", fake.Sent()[0].To)
+	assert.Equal(t, "New feature", fake.Sent()[0].Body)
+}
+
+func TestSms_NewSenderShouldRejectUnknownDriver(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Sms.Driver = "carrier-pigeon"
+
+	sender, err := NewSender(cfg)
+
+	assert.Nil(t, sender)
+	assert.EqualError(t, err, "unknown sms driver: carrier-pigeon")
+}
@@ -2,7 +2,9 @@ package user
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
@@ -13,12 +15,15 @@ import (
 	"gorm.io/gorm"
 )
 
+var ErrAvatarNotImage = errors.New("avatar must be an image")
+
 type UserService struct {
-	userRepo repository.IUserRepo
+	userRepo  repository.IUserRepo
+	mediaRepo repository.IMediaRepo
 }
 
-func NewUserService(userRepo repository.IUserRepo) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo repository.IUserRepo, mediaRepo repository.IMediaRepo) *UserService {
+	return &UserService{userRepo: userRepo, mediaRepo: mediaRepo}
 }
 
 func (u *UserService) CreateUser(req request.CreateUserRequest) (*response.UserResponse, error) {
@@ -34,7 +39,7 @@ func (u *UserService) CreateUser(req request.CreateUserRequest) (*response.UserR
 		Email:    req.Email,
 		Password: string(hashedPassword),
 	}
-	user, err = u.userRepo.Store(user)
+	user, err = u.userRepo.Create(user)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +79,15 @@ func (u *UserService) UpdateUserCountry(req request.UpdateUserCountryRequest, us
 	return userResponse, err
 }
 
+func (u *UserService) UpdateUserPhone(req request.UpdateUserPhoneRequest, userID uint) (*response.UserResponse, error) {
+	_, err := u.userRepo.Update(model.User{Phone: req.Phone}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.userRepo.FindById(userID)
+}
+
 func (u *UserService) GetUser(id uint) (*response.UserResponse, error) {
 	user, err := u.userRepo.FindById(id)
 	if err != nil {
@@ -83,7 +97,7 @@ func (u *UserService) GetUser(id uint) (*response.UserResponse, error) {
 	return user, err
 }
 
-func (u *UserService) GetUsers(paginationReq model.Pagination) (*model.Pagination, error) {
+func (u *UserService) GetUsers(paginationReq model.Pagination) (*model.Page[response.UserResponse], error) {
 	users, err := u.userRepo.FindAll(paginationReq)
 	if err != nil {
 		return nil, err
@@ -91,19 +105,88 @@ func (u *UserService) GetUsers(paginationReq model.Pagination) (*model.Paginatio
 	return users, nil
 }
 
+// SetAvatar makes an image media of the user its avatar, nil removes the
+// avatar. The new media is attached and the previous one detached, so the
+// media gc keeps the avatars in use only. Run it on a transaction.
+func (u *UserService) SetAvatar(userID uint, mediaID *uint) (*response.UserResponse, error) {
+	user, err := u.userRepo.FindById(userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := user.AvatarMediaID
+	if previous == nil && mediaID == nil || previous != nil && mediaID != nil && *previous == *mediaID {
+		return user, nil
+	}
+
+	if mediaID != nil {
+		err = u.attachAvatar(userID, *mediaID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.userRepo.SetAvatar(userID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil {
+		err = u.mediaRepo.Detach(*previous)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	user.AvatarMediaID = mediaID
+	return user, nil
+}
+
+// attachAvatar returns gorm.ErrRecordNotFound for media of other users, so
+// their IDs cannot be probed.
+func (u *UserService) attachAvatar(userID uint, mediaID uint) error {
+	media, err := u.mediaRepo.FindByID(mediaID)
+	if err != nil {
+		return err
+	}
+	if media.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	if !strings.HasPrefix(media.MimeType, "image/") {
+		return ErrAvatarNotImage
+	}
+
+	attached, err := u.mediaRepo.Attach(mediaID, userID)
+	if err != nil {
+		return err
+	}
+	if !attached {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUser detaches the avatar of the user along with deleting it, run it on
+// a transaction.
 func (u *UserService) DeleteUser(id uint) error {
+	user, err := u.userRepo.FindById(id)
+	if err != nil {
+		return err
+	}
+
 	userModel := model.User{
 		ID: id,
 	}
-	err := u.userRepo.DeleteUser(userModel)
+	err = u.userRepo.DeleteUser(userModel)
 	if err != nil {
 		return err
 	}
 
-	return err
+	if user.AvatarMediaID != nil {
+		return u.mediaRepo.Detach(*user.AvatarMediaID)
+	}
+	return nil
 }
 
 func (u *UserService) WithTrx(trxHandle *gorm.DB) service.IUserService {
-	u.userRepo = u.userRepo.WithTrx(trxHandle)
-	return u
+	return &UserService{userRepo: u.userRepo.WithTrx(trxHandle), mediaRepo: u.mediaRepo.WithTrx(trxHandle)}
 }
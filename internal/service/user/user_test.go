@@ -4,37 +4,39 @@ import (
 	"errors"
 	"fmt"
 	"testing"
-	"time"
 
 	"github.com/felixlambertv/go-cleanplate/internal/controller/request"
 	"github.com/felixlambertv/go-cleanplate/internal/controller/response"
 	"github.com/felixlambertv/go-cleanplate/internal/model"
 	"github.com/felixlambertv/go-cleanplate/mocks"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/felixlambertv/go-cleanplate/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 var userRepoMock = new(mocks.IUserRepo)
-var userService = NewUserService(userRepoMock)
+var mediaRepoMock = new(mocks.IMediaRepo)
+var userService = NewUserService(userRepoMock, mediaRepoMock)
 
 var paginationRequest = &model.Pagination{
 	Limit: 10,
 	Page:  1,
 	Sort:  "id asc",
-	Filter: model.Filter{
-		CreatedFrom: time.Date(int(2023), time.March, int(27), int(0), int(0), int(0), int(0), &time.Location{}),
-		CreatedTo:   time.Date(int(2023), time.March, int(31), int(0), int(0), int(0), int(0), &time.Location{}),
+	Filters: []model.FilterCondition{
+		{Column: "users.created_at", Type: consttype.FIELD_DATE, Operator: consttype.FILTER_GTE, Value: "2023-03-27"},
+		{Column: "users.created_at", Type: consttype.FIELD_DATE, Operator: consttype.FILTER_LTE, Value: "2023-03-31"},
 	},
 }
 
-var paginationDummy = &model.Pagination{
+var paginationDummy = &model.Page[response.UserResponse]{
 	Limit:      10,
 	Page:       1,
 	Sort:       "id asc",
 	TotalDatas: 0,
 	TotalPages: 1,
-	Data:       []model.User{},
+	Items:      []response.UserResponse{},
 }
 
 var createUserRequest = request.CreateUserRequest{
@@ -122,7 +124,7 @@ func TestUser_GetUser(t *testing.T) {
 
 func TestUser_CreateUserSuccessful(t *testing.T) {
 	userRepoMock.ExpectedCalls = nil
-	userRepoMock.On("Store", mock.Anything).Return(userDummy, nil)
+	userRepoMock.On("Create", mock.Anything).Return(userDummy, nil)
 
 	user, err := userService.CreateUser(createUserRequest)
 	if err != nil {
@@ -135,7 +137,7 @@ func TestUser_CreateUserSuccessful(t *testing.T) {
 
 func TestUser_CreateUserShouldReturnError(t *testing.T) {
 	userRepoMock.ExpectedCalls = nil
-	userRepoMock.On("Store", mock.Anything).Return(nil, errors.New("something went wrong"))
+	userRepoMock.On("Create", mock.Anything).Return(nil, errors.New("something went wrong"))
 
 	user, err := userService.CreateUser(createUserRequest)
 	if err != nil {
@@ -161,7 +163,33 @@ func TestScenario_UpdateUserCountrySuccessful(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestUser_UpdateUserPhoneSuccessful(t *testing.T) {
+	resetMocks()
+	phoneUser := &response.UserResponse{ID: 1, FullName: "test", Email: "test@example.com", Phone: "+6281234567890"}
+
+	userRepoMock.On("Update", model.User{Phone: "+6281234567890"}, uint(1)).Return(updatedUserDummy, nil).Once()
+	userRepoMock.On("FindById", uint(1)).Return(phoneUser, nil).Once()
+
+	user, err := userService.UpdateUserPhone(request.UpdateUserPhoneRequest{Phone: "+6281234567890"}, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, phoneUser, user)
+}
+
+func resetMocks() {
+	userRepoMock.ExpectedCalls = nil
+	userRepoMock.Calls = nil
+	mediaRepoMock.ExpectedCalls = nil
+	mediaRepoMock.Calls = nil
+}
+
+func avatarID(id uint) *uint {
+	return &id
+}
+
 func TestUser_DeleteUserSuccessful(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", userDummy.ID).Return(userResponseDummy, nil).Once()
 	userRepoMock.On("DeleteUser", model.User{ID: userDummy.ID}).Return(nil)
 
 	err := userService.DeleteUser(userDummy.ID)
@@ -173,6 +201,8 @@ func TestUser_DeleteUserSuccessful(t *testing.T) {
 }
 
 func TestUser_DeleteUserError(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", uint(2)).Return(&response.UserResponse{ID: 2}, nil).Once()
 	userRepoMock.On("DeleteUser", model.User{ID: uint(2)}).Return(errors.New("something went wrong"))
 
 	err := userService.DeleteUser(uint(2))
@@ -182,3 +212,73 @@ func TestUser_DeleteUserError(t *testing.T) {
 
 	assert.Equal(t, errors.New("something went wrong"), err)
 }
+
+func TestUser_DeleteUserShouldDetachAvatar(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", uint(2)).Return(&response.UserResponse{ID: 2, AvatarMediaID: avatarID(5)}, nil).Once()
+	userRepoMock.On("DeleteUser", model.User{ID: uint(2)}).Return(nil).Once()
+	mediaRepoMock.On("Detach", uint(5)).Return(nil).Once()
+
+	err := userService.DeleteUser(uint(2))
+
+	assert.Nil(t, err)
+	mediaRepoMock.AssertExpectations(t)
+}
+
+func TestUser_SetAvatarShouldAttachNewAndDetachPrevious(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", uint(2)).Return(&response.UserResponse{ID: 2, AvatarMediaID: avatarID(5)}, nil).Once()
+	mediaRepoMock.On("FindByID", uint(6)).Return(&model.Media{ID: 6, UserID: 2, MimeType: "image/png"}, nil).Once()
+	mediaRepoMock.On("Attach", uint(6), uint(2)).Return(true, nil).Once()
+	userRepoMock.On("SetAvatar", uint(2), avatarID(6)).Return(nil).Once()
+	mediaRepoMock.On("Detach", uint(5)).Return(nil).Once()
+
+	user, err := userService.SetAvatar(2, avatarID(6))
+
+	assert.Nil(t, err)
+	assert.Equal(t, avatarID(6), user.AvatarMediaID)
+	userRepoMock.AssertExpectations(t)
+	mediaRepoMock.AssertExpectations(t)
+}
+
+func TestUser_SetAvatarShouldRemoveAvatar(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", uint(2)).Return(&response.UserResponse{ID: 2, AvatarMediaID: avatarID(5)}, nil).Once()
+	userRepoMock.On("SetAvatar", uint(2), (*uint)(nil)).Return(nil).Once()
+	mediaRepoMock.On("Detach", uint(5)).Return(nil).Once()
+
+	user, err := userService.SetAvatar(2, nil)
+
+	assert.Nil(t, err)
+	assert.Nil(t, user.AvatarMediaID)
+	mediaRepoMock.AssertNotCalled(t, "Attach", mock.Anything, mock.Anything)
+	mediaRepoMock.AssertExpectations(t)
+}
+
+func TestUser_SetAvatarShouldKeepTheSameAvatar(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", uint(2)).Return(&response.UserResponse{ID: 2, AvatarMediaID: avatarID(5)}, nil).Once()
+
+	_, err := userService.SetAvatar(2, avatarID(5))
+
+	assert.Nil(t, err)
+	userRepoMock.AssertNotCalled(t, "SetAvatar", mock.Anything, mock.Anything)
+	mediaRepoMock.AssertNotCalled(t, "Attach", mock.Anything, mock.Anything)
+	mediaRepoMock.AssertNotCalled(t, "Detach", mock.Anything)
+}
+
+func TestUser_SetAvatarShouldRejectMediaOfOthersAndNonImages(t *testing.T) {
+	resetMocks()
+	userRepoMock.On("FindById", uint(2)).Return(&response.UserResponse{ID: 2}, nil).Twice()
+	mediaRepoMock.On("FindByID", uint(6)).Return(&model.Media{ID: 6, UserID: 3, MimeType: "image/png"}, nil).Once()
+	mediaRepoMock.On("FindByID", uint(7)).Return(&model.Media{ID: 7, UserID: 2, MimeType: "video/mp4"}, nil).Once()
+
+	_, err := userService.SetAvatar(2, avatarID(6))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = userService.SetAvatar(2, avatarID(7))
+	assert.ErrorIs(t, err, ErrAvatarNotImage)
+
+	mediaRepoMock.AssertNotCalled(t, "Attach", mock.Anything, mock.Anything)
+	userRepoMock.AssertNotCalled(t, "SetAvatar", mock.Anything, mock.Anything)
+}
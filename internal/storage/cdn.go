@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"crypto/rsa"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudfront/sign"
+)
+
+// CDNStore serves the blobs of another store through a CDN in front of it.
+// Public blobs get plain CDN URLs. Presigned downloads are CloudFront signed
+// URLs when a signer is set, otherwise they come from the store itself.
+type CDNStore struct {
+	BlobStore
+	baseURL string
+	signer  *sign.URLSigner
+}
+
+// NewCDNStore serves store from baseURL, signing downloads with the CloudFront
+// key pair of keyID when key is not nil.
+func NewCDNStore(store BlobStore, baseURL string, keyID string, key *rsa.PrivateKey) *CDNStore {
+	c := &CDNStore{BlobStore: store, baseURL: strings.TrimSuffix(baseURL, "/")}
+	if key != nil {
+		c.signer = sign.NewURLSigner(keyID, key)
+	}
+	return c
+}
+
+func (c *CDNStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	if c.signer == nil {
+		return c.BlobStore.PresignGet(ctx, key, expires)
+	}
+	return c.signer.Sign(c.URL(key), time.Now().Add(expires))
+}
+
+func (c *CDNStore) URL(key string) string {
+	return c.baseURL + "/" + escapeKey(key)
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tempPrefix marks files still being written, they are skipped by List.
+const tempPrefix = ".upload-"
+
+// LocalStore keeps blobs as files under a directory. The content type of a
+// blob is derived from the extension of its key.
+type LocalStore struct {
+	dir    string
+	signer *URLSigner
+}
+
+func NewLocalStore(dir string, signer *URLSigner) (*LocalStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir, signer: signer}, nil
+}
+
+// Put writes to a temporary file first, readers never see a partial blob.
+func (l *LocalStore) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) (*BlobInfo, error) {
+	filename, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), tempPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Rename(tmp.Name(), filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Stat(ctx, key)
+}
+
+func (l *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	filename, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, l.info(key, stat), nil
+}
+
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *LocalStore) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	filename, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && stat.IsDir()) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return l.info(key, stat), nil
+}
+
+func (l *LocalStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	blobs := []BlobInfo{}
+
+	err := filepath.WalkDir(l.dir, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), tempPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.dir, filename)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, *l.info(key, stat))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blobs, nil
+}
+
+func (l *LocalStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return l.signer.Sign(http.MethodGet, key, time.Now().Add(expires), "", 0), nil
+}
+
+func (l *LocalStore) PresignPut(ctx context.Context, key string, opts PresignPutOptions, expires time.Duration) (*PresignedRequest, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	return presignedPut(l.signer, key, opts, expires), nil
+}
+
+func (l *LocalStore) URL(key string) string {
+	return l.signer.Sign(http.MethodGet, key, time.Time{}, "", 0)
+}
+
+func (l *LocalStore) VerifyRequest(key string, r *http.Request) error {
+	return l.signer.Verify(key, r)
+}
+
+func (l *LocalStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(path.Base(key), tempPrefix) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+func (l *LocalStore) info(key string, stat fs.FileInfo) *BlobInfo {
+	return &BlobInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  contentType(key, ""),
+		LastModified: stat.ModTime().UTC(),
+	}
+}
+
+// contentType prefers the content type given on upload and falls back to the
+// extension of the key.
+func contentType(key string, given string) string {
+	if given != "" {
+		return given
+	}
+	if byExt := mime.TypeByExtension(path.Ext(key)); byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// MemoryStore keeps blobs in memory, for tests and throwaway environments.
+	MemoryStore struct {
+		signer *URLSigner
+
+		mu    sync.RWMutex
+		blobs map[string]memoryBlob
+	}
+
+	memoryBlob struct {
+		data []byte
+		info BlobInfo
+	}
+)
+
+func NewMemoryStore(signer *URLSigner) *MemoryStore {
+	return &MemoryStore{signer: signer, blobs: map[string]memoryBlob{}}
+}
+
+func (m *MemoryStore) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) (*BlobInfo, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	info := BlobInfo{
+		Key:          key,
+		Size:         int64(len(data)),
+		ContentType:  contentType(key, opts.ContentType),
+		LastModified: time.Now().UTC(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[key] = memoryBlob{data: data, info: info}
+
+	return &info, nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	blob, ok := m.blobs[key]
+	if !ok {
+		return nil, nil, ErrBlobNotFound
+	}
+
+	info := blob.info
+	return io.NopCloser(bytes.NewReader(blob.data)), &info, nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.blobs, key)
+	return nil
+}
+
+func (m *MemoryStore) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	blob, ok := m.blobs[key]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+
+	info := blob.info
+	return &info, nil
+}
+
+func (m *MemoryStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	blobs := []BlobInfo{}
+	for key, blob := range m.blobs {
+		if strings.HasPrefix(key, prefix) {
+			blobs = append(blobs, blob.info)
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key < blobs[j].Key })
+
+	return blobs, nil
+}
+
+func (m *MemoryStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return m.signer.Sign(http.MethodGet, key, time.Now().Add(expires), "", 0), nil
+}
+
+func (m *MemoryStore) PresignPut(ctx context.Context, key string, opts PresignPutOptions, expires time.Duration) (*PresignedRequest, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	return presignedPut(m.signer, key, opts, expires), nil
+}
+
+func (m *MemoryStore) URL(key string) string {
+	return m.signer.Sign(http.MethodGet, key, time.Time{}, "", 0)
+}
+
+func (m *MemoryStore) VerifyRequest(key string, r *http.Request) error {
+	return m.signer.Verify(key, r)
+}
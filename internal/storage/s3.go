@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Store keeps blobs in one bucket. Uploads stream through the multipart
+// uploader, so bodies of unknown size are never buffered whole.
+type S3Store struct {
+	client   s3iface.S3API
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+func NewS3Store(client s3iface.S3API, bucket string) *S3Store {
+	return &S3Store{client: client, uploader: s3manager.NewUploaderWithClient(client), bucket: bucket}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) (*BlobInfo, error) {
+	counter := &countingReader{r: body}
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   counter,
+	}
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+	if opts.Public {
+		input.ACL = aws.String(s3.ObjectCannedACLPublicRead)
+	}
+
+	res, err := s.uploader.UploadWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlobInfo{
+		Key:          key,
+		Size:         counter.n,
+		ContentType:  opts.ContentType,
+		ETag:         aws.StringValue(res.ETag),
+		LastModified: time.Now().UTC(),
+	}, nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	object, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, nil, s3Error(err)
+	}
+
+	return object.Body, &BlobInfo{
+		Key:          key,
+		Size:         aws.Int64Value(object.ContentLength),
+		ContentType:  aws.StringValue(object.ContentType),
+		ETag:         aws.StringValue(object.ETag),
+		LastModified: aws.TimeValue(object.LastModified),
+	}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	head, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	return &BlobInfo{
+		Key:          key,
+		Size:         aws.Int64Value(head.ContentLength),
+		ContentType:  aws.StringValue(head.ContentType),
+		ETag:         aws.StringValue(head.ETag),
+		LastModified: aws.TimeValue(head.LastModified),
+	}, nil
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	blobs := []BlobInfo{}
+
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			blobs = append(blobs, BlobInfo{
+				Key:          aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				ETag:         aws.StringValue(object.ETag),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blobs, nil
+}
+
+func (s *S3Store) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	req.SetContext(ctx)
+	return req.Presign(expires)
+}
+
+// PresignPut signs the content type, length and ACL headers, S3 rejects
+// uploads that send other values.
+func (s *S3Store) PresignPut(ctx context.Context, key string, opts PresignPutOptions, expires time.Duration) (*PresignedRequest, error) {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+	if opts.Size > 0 {
+		input.ContentLength = aws.Int64(opts.Size)
+	}
+	if opts.Public {
+		input.ACL = aws.String(s3.ObjectCannedACLPublicRead)
+	}
+
+	req, _ := s.client.PutObjectRequest(input)
+	req.SetContext(ctx)
+	presigned, signedHeader, err := req.PresignRequest(expires)
+	if err != nil {
+		return nil, err
+	}
+
+	header := map[string]string{}
+	for name, values := range signedHeader {
+		header[http.CanonicalHeaderKey(name)] = strings.Join(values, ",")
+	}
+
+	return &PresignedRequest{Method: http.MethodPut, URL: presigned, Header: header}, nil
+}
+
+// URL builds the object URL the way the client addresses the bucket, so it
+// follows the endpoint and path style settings.
+func (s *S3Store) URL(key string) string {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err := req.Build(); err != nil {
+		return ""
+	}
+	return req.HTTPRequest.URL.String()
+}
+
+func s3Error(err error) error {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		switch aerr.Code() {
+		case s3.ErrCodeNoSuchKey, "NotFound":
+			return ErrBlobNotFound
+		}
+	}
+	return err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
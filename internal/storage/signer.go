@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("blob url signature is not valid")
+
+type (
+	// URLVerifier is implemented by stores whose signed URLs are served by the
+	// app instead of the storage service.
+	URLVerifier interface {
+		VerifyRequest(key string, r *http.Request) error
+	}
+
+	// URLSigner signs blob URLs served by the app. URLs without expiry are the
+	// permanent URLs of public blobs and only allow GET.
+	URLSigner struct {
+		baseURL string
+		secret  []byte
+	}
+)
+
+func NewURLSigner(baseURL string, secret string) *URLSigner {
+	return &URLSigner{baseURL: baseURL, secret: []byte("storage:" + secret)}
+}
+
+// Sign returns a URL allowing method on key until expiresAt. A content type
+// or size other than zero must be matched by the request body.
+func (s *URLSigner) Sign(method string, key string, expiresAt time.Time, contentType string, size int64) string {
+	query := url.Values{}
+	if !expiresAt.IsZero() {
+		query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	}
+	if contentType != "" {
+		query.Set("type", contentType)
+	}
+	if size > 0 {
+		query.Set("size", strconv.FormatInt(size, 10))
+	}
+	query.Set("signature", s.signature(method, key, query))
+
+	return s.baseURL + "/" + escapeKey(key) + "?" + query.Encode()
+}
+
+func (s *URLSigner) Verify(key string, r *http.Request) error {
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	query := r.URL.Query()
+	expires := query.Get("expires")
+	if expires == "" && method != http.MethodGet {
+		return ErrInvalidSignature
+	}
+
+	if expires != "" {
+		expiresAt, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > expiresAt {
+			return ErrInvalidSignature
+		}
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(query.Get("signature"))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected, _ := base64.RawURLEncoding.DecodeString(s.signature(method, key, query))
+	if !hmac.Equal(signature, expected) {
+		return ErrInvalidSignature
+	}
+
+	if contentType := query.Get("type"); contentType != "" && r.Header.Get("Content-Type") != contentType {
+		return ErrInvalidSignature
+	}
+	if size := query.Get("size"); size != "" && strconv.FormatInt(r.ContentLength, 10) != size {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (s *URLSigner) signature(method string, key string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{method, key, query.Get("expires"), query.Get("type"), query.Get("size")}, "\n")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func presignedPut(signer *URLSigner, key string, opts PresignPutOptions, expires time.Duration) *PresignedRequest {
+	header := map[string]string{}
+	if opts.ContentType != "" {
+		header["Content-Type"] = opts.ContentType
+	}
+	if opts.Size > 0 {
+		header["Content-Length"] = strconv.FormatInt(opts.Size, 10)
+	}
+
+	return &PresignedRequest{
+		Method: http.MethodPut,
+		URL:    signer.Sign(http.MethodPut, key, time.Now().Add(expires), opts.ContentType, opts.Size),
+		Header: header,
+	}
+}
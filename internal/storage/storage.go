@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudfront/sign"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/felixlambertv/go-cleanplate/config"
+)
+
+// SignedURLPath is where the app serves the blobs of the local and memory
+// stores, relative to APP_URL.
+const SignedURLPath = "/api/v1/storage"
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("blob key is not valid")
+)
+
+type (
+	// BlobStore keeps blobs by key. Keys are slash separated paths such as
+	// image/4f1c.png. Deleting a blob that does not exist is not an error.
+	BlobStore interface {
+		Put(ctx context.Context, key string, body io.Reader, opts PutOptions) (*BlobInfo, error)
+		Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+		Delete(ctx context.Context, key string) error
+		Stat(ctx context.Context, key string) (*BlobInfo, error)
+		List(ctx context.Context, prefix string) ([]BlobInfo, error)
+		// PresignGet returns a URL that downloads the blob without credentials
+		// until it expires.
+		PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+		// PresignPut returns a PUT request that uploads the blob without
+		// credentials until it expires. The upload must send the returned headers
+		// and exactly opts.Size bytes when it is set.
+		PresignPut(ctx context.Context, key string, opts PresignPutOptions, expires time.Duration) (*PresignedRequest, error)
+		// URL is the permanent address of a blob stored as Public.
+		URL(key string) string
+	}
+
+	PutOptions struct {
+		ContentType string
+		// Public blobs can be downloaded by anyone knowing their URL.
+		Public bool
+	}
+
+	PresignPutOptions struct {
+		ContentType string
+		Size        int64
+		Public      bool
+	}
+
+	PresignedRequest struct {
+		Method string            `json:"method"`
+		URL    string            `json:"url"`
+		Header map[string]string `json:"header,omitempty"`
+	}
+
+	BlobInfo struct {
+		Key          string    `json:"key"`
+		Size         int64     `json:"size"`
+		ContentType  string    `json:"contentType,omitempty"`
+		ETag         string    `json:"etag,omitempty"`
+		LastModified time.Time `json:"lastModified,omitempty"`
+	}
+)
+
+// NewBlobStore returns the store selected by STORAGE_DRIVER. S3 uses the
+// region and credentials of sess, S3_ENDPOINT and S3_PATH_STYLE point it at
+// S3 compatible services such as MinIO and S3_CDN_URL serves it through a CDN.
+func NewBlobStore(cfg *config.Config, sess *session.Session) (BlobStore, error) {
+	signer := NewURLSigner(strings.TrimSuffix(cfg.App.Url, "/")+SignedURLPath, cfg.App.Secret)
+
+	switch strings.ToLower(cfg.Storage.Driver) {
+	case "", "s3":
+		s3Cfg := aws.NewConfig().WithS3ForcePathStyle(cfg.S3.PathStyle)
+		if cfg.S3.Endpoint != "" {
+			s3Cfg = s3Cfg.WithEndpoint(cfg.S3.Endpoint)
+		}
+		store := NewS3Store(s3.New(sess, s3Cfg), cfg.S3.Bucket)
+		if cfg.S3.CDNUrl == "" {
+			return store, nil
+		}
+		var key *rsa.PrivateKey
+		if cfg.S3.CDNPrivateKeyFile != "" {
+			var err error
+			key, err = sign.LoadPEMPrivKeyFile(cfg.S3.CDNPrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("cdn private key : %w", err)
+			}
+		}
+		return NewCDNStore(store, cfg.S3.CDNUrl, cfg.S3.CDNKeyID, key), nil
+	case "local":
+		return NewLocalStore(cfg.Storage.LocalDir, signer)
+	case "memory":
+		return NewMemoryStore(signer), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Storage.Driver)
+	}
+}
+
+// validateKey rejects keys that are not clean relative paths, they could
+// escape the directory of the local store.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/felixlambertv/go-cleanplate/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var signer = NewURLSigner("https://api.test.com/api/v1/storage", "randomblabla")
+
+func testBlobStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+
+	info, err := store.Put(ctx, "image/a.png", strings.NewReader("png"), PutOptions{ContentType: "image/png"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), info.Size)
+	_, err = store.Put(ctx, "image/b.png", strings.NewReader("second"), PutOptions{})
+	assert.Nil(t, err)
+	_, err = store.Put(ctx, "video/c.mp4", strings.NewReader("mp4"), PutOptions{})
+	assert.Nil(t, err)
+
+	body, info, err := store.Get(ctx, "image/a.png")
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "png", string(data))
+	assert.Equal(t, "image/png", info.ContentType)
+
+	info, err = store.Stat(ctx, "image/b.png")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), info.Size)
+
+	blobs, err := store.List(ctx, "image/")
+	assert.Nil(t, err)
+	assert.Len(t, blobs, 2)
+	assert.Equal(t, "image/a.png", blobs[0].Key)
+
+	assert.Nil(t, store.Delete(ctx, "image/a.png"))
+	assert.Nil(t, store.Delete(ctx, "image/a.png"))
+	_, err = store.Stat(ctx, "image/a.png")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	_, _, err = store.Get(ctx, "image/a.png")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testBlobStore(t, NewMemoryStore(signer))
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), signer)
+	assert.Nil(t, err)
+
+	testBlobStore(t, store)
+}
+
+func TestLocalStore_ShouldRejectKeysOutsideDirectory(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), signer)
+	assert.Nil(t, err)
+
+	for _, key := range []string{"../secret", "/etc/passwd", "image/../../secret", "image//a.png", ""} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("x"), PutOptions{})
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func signedRequest(method string, rawURL string, contentType string, body string) *http.Request {
+	r := httptest.NewRequest(method, rawURL, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestURLSigner_ShouldVerifySignedUpload(t *testing.T) {
+	store := NewMemoryStore(signer)
+
+	presigned, err := store.PresignPut(context.Background(), "image/a b.png", PresignPutOptions{ContentType: "image/png", Size: 3}, time.Minute)
+	assert.Nil(t, err)
+
+	u, _ := url.Parse(presigned.URL)
+	assert.Equal(t, "/api/v1/storage/image/a%20b.png", u.EscapedPath())
+	assert.Equal(t, map[string]string{"Content-Type": "image/png", "Content-Length": "3"}, presigned.Header)
+	assert.Nil(t, store.VerifyRequest("image/a b.png", signedRequest("PUT", presigned.URL, "image/png", "png")))
+	assert.ErrorIs(t, store.VerifyRequest("image/a b.png", signedRequest("GET", presigned.URL, "", "")), ErrInvalidSignature)
+	assert.ErrorIs(t, store.VerifyRequest("image/other.png", signedRequest("PUT", presigned.URL, "image/png", "png")), ErrInvalidSignature)
+	assert.ErrorIs(t, store.VerifyRequest("image/a b.png", signedRequest("PUT", presigned.URL, "text/html", "png")), ErrInvalidSignature)
+	assert.ErrorIs(t, store.VerifyRequest("image/a b.png", signedRequest("PUT", presigned.URL, "image/png", "larger")), ErrInvalidSignature)
+
+	tampered := strings.Replace(presigned.URL, "size=3", "size=6", 1)
+	assert.ErrorIs(t, store.VerifyRequest("image/a b.png", signedRequest("PUT", tampered, "image/png", "larger")), ErrInvalidSignature)
+}
+
+func TestURLSigner_ShouldRejectExpiredURL(t *testing.T) {
+	signed := signer.Sign("GET", "image/a.png", time.Now().Add(-time.Second), "", 0)
+
+	assert.ErrorIs(t, signer.Verify("image/a.png", signedRequest("GET", signed, "", "")), ErrInvalidSignature)
+}
+
+func TestURLSigner_PublicURLShouldOnlyAllowGet(t *testing.T) {
+	public := NewMemoryStore(signer).URL("image/a.png")
+
+	assert.Nil(t, signer.Verify("image/a.png", signedRequest("GET", public, "", "")))
+	assert.Nil(t, signer.Verify("image/a.png", signedRequest("HEAD", public, "", "")))
+	assert.ErrorIs(t, signer.Verify("image/a.png", signedRequest("PUT", public, "", "png")), ErrInvalidSignature)
+}
+
+// fakeS3 is a path style S3 endpoint keeping objects in memory.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key], _ = io.ReadAll(r.Body)
+		f.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+			}
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", "3")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newS3Store(t *testing.T) (*S3Store, *httptest.Server) {
+	server := httptest.NewServer(&fakeS3{objects: map[string][]byte{}, types: map[string]string{}})
+	t.Cleanup(server.Close)
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("ap-southeast-2"),
+		Credentials: credentials.NewStaticCredentials("key", "secret", ""),
+	}))
+	store, err := NewBlobStore(&config.Config{
+		Storage: config.Storage{Driver: "s3"},
+		S3:      config.S3{Bucket: "bucket", Endpoint: server.URL, PathStyle: true},
+	}, sess)
+	assert.Nil(t, err)
+
+	return store.(*S3Store), server
+}
+
+func TestS3Store_ShouldUseCustomEndpointWithPathStyle(t *testing.T) {
+	store, server := newS3Store(t)
+	ctx := context.Background()
+
+	info, err := store.Put(ctx, "image/a.png", strings.NewReader("png"), PutOptions{ContentType: "image/png"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), info.Size)
+	assert.Equal(t, `"etag"`, info.ETag)
+
+	body, info, err := store.Get(ctx, "image/a.png")
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "png", string(data))
+	assert.Equal(t, "image/png", info.ContentType)
+
+	assert.Equal(t, server.URL+"/bucket/image/a.png", store.URL("image/a.png"))
+
+	presigned, err := store.PresignGet(ctx, "image/a.png", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(presigned, server.URL+"/bucket/image/a.png?"))
+	assert.Contains(t, presigned, "X-Amz-Signature=")
+
+	upload, err := store.PresignPut(ctx, "image/b.png", PresignPutOptions{ContentType: "image/png", Size: 3, Public: true}, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Content-Type": "image/png", "Content-Length": "3", "X-Amz-Acl": "public-read"}, upload.Header)
+	assert.Contains(t, upload.URL, "X-Amz-SignedHeaders=content-length%3Bcontent-type%3Bhost%3Bx-amz-acl")
+
+	assert.Nil(t, store.Delete(ctx, "image/a.png"))
+	_, _, err = store.Get(ctx, "image/a.png")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	_, err = store.Stat(ctx, "image/a.png")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestNewBlobStore_ShouldRejectUnknownDriver(t *testing.T) {
+	_, err := NewBlobStore(&config.Config{Storage: config.Storage{Driver: "ftp"}}, session.Must(session.NewSession()))
+
+	assert.NotNil(t, err)
+}
+
+func TestCDNStore_ShouldServeThroughCDN(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryStore(signer)
+	_, _ = memory.Put(ctx, "private/image/a.png", strings.NewReader("png"), PutOptions{ContentType: "image/png"})
+
+	unsigned := NewCDNStore(memory, "https://cdn.test.com/", "", nil)
+	assert.Equal(t, "https://cdn.test.com/image/a%20b.png", unsigned.URL("image/a b.png"))
+	presigned, err := unsigned.PresignGet(ctx, "private/image/a.png", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(presigned, "https://api.test.com/api/v1/storage/private/image/a.png?"))
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	signed := NewCDNStore(memory, "https://cdn.test.com", "KEYPAIR", key)
+	presigned, err = signed.PresignGet(ctx, "private/image/a.png", time.Minute)
+	assert.Nil(t, err)
+	u, _ := url.Parse(presigned)
+	assert.Equal(t, "cdn.test.com", u.Host)
+	assert.Equal(t, "/private/image/a.png", u.Path)
+	assert.Equal(t, "KEYPAIR", u.Query().Get("Key-Pair-Id"))
+	assert.NotEmpty(t, u.Query().Get("Signature"))
+	assert.NotEmpty(t, u.Query().Get("Expires"))
+
+	body, _, err := signed.Get(ctx, "private/image/a.png")
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, "png", string(data))
+}
@@ -0,0 +1,352 @@
+// Package webp encodes images as lossless WebP without cgo.
+package webp
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// maxDimension is the largest width or height a WebP can hold.
+const maxDimension = 1 << 14
+
+// codeLengthOrder is the order code length code lengths are written in.
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// predictorBits sets the blocks of the predictor transform to 512 pixels
+// square, every block uses the same predictor.
+const predictorBits = 9
+
+// averagePredictor predicts a pixel as the average of its left and top
+// neighbours.
+const averagePredictor = 7
+
+// Encode writes img as a lossless WebP. Green is subtracted from red and
+// blue and pixels are predicted from their neighbours, the residuals are
+// written as literals with a single set of prefix codes. Files are larger than
+// those of libwebp, but the encoder stays small and needs no cgo.
+func Encode(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return errors.New("webp: invalid image size")
+	}
+
+	argb := make([]uint32, 0, width*height)
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			hasAlpha = hasAlpha || c.A != 0xFF
+			// subtract green
+			r, b := c.R-c.G, c.B-c.G
+			argb = append(argb, uint32(c.A)<<24|uint32(r)<<16|uint32(c.G)<<8|uint32(b))
+		}
+	}
+
+	residuals := make([]uint32, len(argb))
+	var green, red, blue, alpha [256]int
+	for i, pixel := range argb {
+		x, y := i%width, i/width
+		var prediction uint32
+		switch {
+		case x == 0 && y == 0:
+			prediction = 0xFF000000
+		case y == 0:
+			prediction = argb[i-1]
+		case x == 0:
+			prediction = argb[i-width]
+		default:
+			prediction = averagePixels(argb[i-1], argb[i-width])
+		}
+		residual := subtractPixels(pixel, prediction)
+		residuals[i] = residual
+		alpha[residual>>24]++
+		red[residual>>16&0xFF]++
+		green[residual>>8&0xFF]++
+		blue[residual&0xFF]++
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2F, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3)
+
+	// subtract green transform
+	bw.write(1, 1)
+	bw.write(2, 2)
+	// predictor transform, its image of predictors is coded with single
+	// symbol codes and takes no bits per block
+	bw.write(1, 1)
+	bw.write(0, 2)
+	bw.write(predictorBits-2, 3)
+	bw.write(0, 1)
+	writePrefixCode(bw, singleSymbol(280, averagePredictor))
+	for i := 0; i < 3; i++ {
+		writePrefixCode(bw, make([]int, 256))
+	}
+	writePrefixCode(bw, make([]int, 40))
+	bw.write(0, 1)
+
+	// no color cache and a single set of prefix codes
+	bw.write(0, 1)
+	bw.write(0, 1)
+
+	// The green alphabet also holds the 24 length prefixes, which are unused.
+	greenCode := writePrefixCode(bw, append(green[:], make([]int, 24)...))
+	redCode := writePrefixCode(bw, red[:])
+	blueCode := writePrefixCode(bw, blue[:])
+	alphaCode := writePrefixCode(bw, alpha[:])
+	writePrefixCode(bw, make([]int, 40))
+
+	for _, residual := range residuals {
+		greenCode.write(bw, int(residual>>8&0xFF))
+		redCode.write(bw, int(residual>>16&0xFF))
+		blueCode.write(bw, int(residual&0xFF))
+		alphaCode.write(bw, int(residual>>24))
+	}
+
+	data := bw.bytes()
+	padding := len(data) & 1
+	header := make([]byte, 20)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+len(data)+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padding == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+func singleSymbol(size int, symbol int) []int {
+	histogram := make([]int, size)
+	histogram[symbol] = 1
+	return histogram
+}
+
+// averagePixels averages every channel of a and b.
+func averagePixels(a uint32, b uint32) uint32 {
+	var pixel uint32
+	for shift := 0; shift < 32; shift += 8 {
+		pixel |= ((a>>shift&0xFF + b>>shift&0xFF) / 2) << shift
+	}
+	return pixel
+}
+
+// subtractPixels subtracts every channel of b from a, modulo 256.
+func subtractPixels(a uint32, b uint32) uint32 {
+	var pixel uint32
+	for shift := 0; shift < 32; shift += 8 {
+		pixel |= ((a>>shift - b>>shift) & 0xFF) << shift
+	}
+	return pixel
+}
+
+// bitWriter packs values least significant bit first.
+type bitWriter struct {
+	buf  []byte
+	bits uint64
+	n    uint
+}
+
+func (bw *bitWriter) write(value uint32, n uint) {
+	bw.bits |= uint64(value) << bw.n
+	bw.n += n
+	for bw.n >= 8 {
+		bw.buf = append(bw.buf, byte(bw.bits))
+		bw.bits >>= 8
+		bw.n -= 8
+	}
+}
+
+func (bw *bitWriter) bytes() []byte {
+	if bw.n > 0 {
+		bw.buf = append(bw.buf, byte(bw.bits))
+		bw.bits, bw.n = 0, 0
+	}
+	return bw.buf
+}
+
+// prefixCode holds the bit reversed canonical code of every symbol, ready to
+// be written least significant bit first.
+type prefixCode struct {
+	codes   []uint32
+	lengths []uint8
+}
+
+func (p prefixCode) write(bw *bitWriter, symbol int) {
+	bw.write(p.codes[symbol], uint(p.lengths[symbol]))
+}
+
+// writePrefixCode writes the code for symbols of histogram. A code of one
+// symbol is written as a simple code and takes no bits per symbol.
+func writePrefixCode(bw *bitWriter, histogram []int) prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) <= 1 {
+		symbol := 0
+		if len(used) == 1 {
+			symbol = used[0]
+		}
+		bw.write(1, 1)
+		bw.write(0, 1)
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8)
+		}
+		return prefixCode{codes: make([]uint32, len(histogram)), lengths: make([]uint8, len(histogram))}
+	}
+
+	lengths := huffmanLengths(histogram, 15)
+
+	// The code lengths are written with a code of their own, which needs two
+	// symbols to be complete.
+	var lengthHistogram [19]int
+	for _, length := range lengths {
+		lengthHistogram[length]++
+	}
+	distinct := 0
+	for _, count := range lengthHistogram {
+		if count > 0 {
+			distinct++
+		}
+	}
+	if distinct == 1 {
+		if lengthHistogram[0] == 0 {
+			lengthHistogram[0] = 1
+		} else {
+			lengthHistogram[1] = 1
+		}
+	}
+	lengthLengths := huffmanLengths(lengthHistogram[:], 7)
+	lengthCode := prefixCode{codes: canonicalCodes(lengthLengths), lengths: lengthLengths}
+
+	bw.write(0, 1)
+	bw.write(uint32(len(codeLengthOrder)-4), 4)
+	for _, symbol := range codeLengthOrder {
+		bw.write(uint32(lengthLengths[symbol]), 3)
+	}
+	// code lengths follow for the whole alphabet
+	bw.write(0, 1)
+	for _, length := range lengths {
+		lengthCode.write(bw, int(length))
+	}
+
+	return prefixCode{codes: canonicalCodes(lengths), lengths: lengths}
+}
+
+// huffmanLengths returns the code length of every symbol of histogram, at
+// most maxLength. Counts are halved until the code fits.
+func huffmanLengths(histogram []int, maxLength uint8) []uint8 {
+	counts := append([]int{}, histogram...)
+	for {
+		lengths, ok := buildHuffman(counts, maxLength)
+		if ok {
+			return lengths
+		}
+		for i, count := range counts {
+			if count > 0 {
+				counts[i] = (count + 1) / 2
+			}
+		}
+	}
+}
+
+func buildHuffman(counts []int, maxLength uint8) ([]uint8, bool) {
+	type node struct {
+		count       int
+		left, right int
+	}
+
+	var nodes []node
+	var active []int
+	leaves := make(map[int]int)
+	for symbol, count := range counts {
+		if count > 0 {
+			leaves[len(nodes)] = symbol
+			active = append(active, len(nodes))
+			nodes = append(nodes, node{count: count, left: -1, right: -1})
+		}
+	}
+
+	lengths := make([]uint8, len(counts))
+	if len(active) == 1 {
+		lengths[leaves[active[0]]] = 1
+		return lengths, true
+	}
+
+	for len(active) > 1 {
+		sort.SliceStable(active, func(i, j int) bool { return nodes[active[i]].count < nodes[active[j]].count })
+		nodes = append(nodes, node{count: nodes[active[0]].count + nodes[active[1]].count, left: active[0], right: active[1]})
+		active = append(active[2:], len(nodes)-1)
+	}
+
+	var walk func(i int, depth uint8) bool
+	walk = func(i int, depth uint8) bool {
+		if nodes[i].left < 0 {
+			lengths[leaves[i]] = depth
+			return depth <= maxLength
+		}
+		return walk(nodes[i].left, depth+1) && walk(nodes[i].right, depth+1)
+	}
+	return lengths, walk(active[0], 0)
+}
+
+// canonicalCodes assigns codes in order of length then symbol, as in DEFLATE,
+// and reverses their bits.
+func canonicalCodes(lengths []uint8) []uint32 {
+	var lengthCount [16]int
+	for _, length := range lengths {
+		if length > 0 {
+			lengthCount[length]++
+		}
+	}
+
+	var next [16]uint32
+	code := uint32(0)
+	for length := 1; length < 16; length++ {
+		code = (code + uint32(lengthCount[length-1])) << 1
+		next[length] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		code := next[length]
+		next[length]++
+		reversed := uint32(0)
+		for i := uint8(0); i < length; i++ {
+			reversed = reversed<<1 | code>>i&1
+		}
+		codes[symbol] = reversed
+	}
+	return codes
+}
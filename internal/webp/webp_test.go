@@ -0,0 +1,100 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+func gradient(width int, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 11), B: uint8(x * y), A: uint8(255 - x)})
+		}
+	}
+	return img
+}
+
+func assertRoundTrip(t *testing.T, src image.Image) {
+	var buf bytes.Buffer
+	if !assert.Nil(t, Encode(&buf, src)) {
+		return
+	}
+
+	decoded, err := webp.Decode(&buf)
+	if !assert.Nil(t, err) {
+		return
+	}
+	bounds := src.Bounds()
+	assert.Equal(t, bounds.Dx(), decoded.Bounds().Dx())
+	assert.Equal(t, bounds.Dy(), decoded.Bounds().Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			want := color.NRGBAModel.Convert(src.At(bounds.Min.X+x, bounds.Min.Y+y))
+			got := color.NRGBAModel.Convert(decoded.At(decoded.Bounds().Min.X+x, decoded.Bounds().Min.Y+y))
+			if !assert.Equal(t, want, got, "pixel %d,%d", x, y) {
+				return
+			}
+		}
+	}
+}
+
+func TestEncode_ShouldBeLossless(t *testing.T) {
+	assertRoundTrip(t, gradient(37, 21))
+}
+
+func TestEncode_ShouldRoundTripImages(t *testing.T) {
+	opaque := image.NewRGBA(image.Rect(0, 0, 64, 3))
+	for x := 0; x < 64; x++ {
+		opaque.Set(x, x%3, color.RGBA{R: 200, G: 10, B: uint8(x), A: 0xFF})
+	}
+	gray := image.NewGray(image.Rect(0, 0, 9, 9))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 3)
+	}
+
+	tests := map[string]image.Image{
+		"single pixel":  gradient(1, 1),
+		"single row":    gradient(300, 1),
+		"single column": gradient(1, 300),
+		"opaque":        opaque,
+		"gray":          gray,
+		"offset":        gradient(40, 40).SubImage(image.Rect(5, 7, 31, 22)),
+		"blank":         image.NewRGBA(image.Rect(0, 0, 12, 12)),
+	}
+	for name, img := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertRoundTrip(t, img)
+		})
+	}
+}
+
+func TestEncode_ShouldRejectInvalidSizes(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NotNil(t, Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 0, 10))))
+	assert.NotNil(t, Encode(&buf, image.NewNRGBA(image.Rect(0, 0, maxDimension+1, 1))))
+	assert.Zero(t, buf.Len())
+}
+
+func FuzzEncode(f *testing.F) {
+	f.Add(uint8(1), uint8(1), []byte{0, 0, 0, 0})
+	f.Add(uint8(3), uint8(2), []byte{255, 0, 0, 255, 0, 255, 0, 128, 0, 0, 255, 0})
+	f.Add(uint8(37), uint8(21), gradient(37, 21).Pix)
+	f.Fuzz(func(t *testing.T, width uint8, height uint8, pix []byte) {
+		if width == 0 || height == 0 {
+			return
+		}
+		img := image.NewNRGBA(image.Rect(0, 0, int(width), int(height)))
+		for i := range img.Pix {
+			if len(pix) > 0 {
+				img.Pix[i] = pix[i%len(pix)]
+			}
+		}
+		assertRoundTrip(t, img)
+	})
+}
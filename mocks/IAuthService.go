@@ -5,9 +5,10 @@ package mocks
 import (
 	request "github.com/felixlambertv/go-cleanplate/internal/controller/request"
 	response "github.com/felixlambertv/go-cleanplate/internal/controller/response"
-	mock "github.com/stretchr/testify/mock"
-
+	service "github.com/felixlambertv/go-cleanplate/internal/service"
 	utils "github.com/felixlambertv/go-cleanplate/pkg/utils"
+	mock "github.com/stretchr/testify/mock"
+	gorm "gorm.io/gorm"
 )
 
 // IAuthService is an autogenerated mock type for the IAuthService type
@@ -64,6 +65,20 @@ func (_m *IAuthService) Login(req request.LoginRequest) (*response.UserResponse,
 	return r0, r1, r2
 }
 
+// PurgeExpiredResetTokens provides a mock function with given fields:
+func (_m *IAuthService) PurgeExpiredResetTokens() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RefreshAuthToken provides a mock function with given fields: token
 func (_m *IAuthService) RefreshAuthToken(token string) (*response.UserResponse, *utils.TokenHeader, error) {
 	ret := _m.Called(token)
@@ -134,6 +149,20 @@ func (_m *IAuthService) Register(req request.RegisterRequest) (*response.UserRes
 	return r0, r1, r2
 }
 
+// RemindUnverifiedUsers provides a mock function with given fields:
+func (_m *IAuthService) RemindUnverifiedUsers() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResetPassword provides a mock function with given fields: req
 func (_m *IAuthService) ResetPassword(req request.ResetPasswordRequest) error {
 	ret := _m.Called(req)
@@ -148,13 +177,13 @@ func (_m *IAuthService) ResetPassword(req request.ResetPasswordRequest) error {
 	return r0
 }
 
-// SendResetPasswordEmail provides a mock function with given fields: id, token
-func (_m *IAuthService) SendResetPasswordEmail(id uint, token string) error {
-	ret := _m.Called(id, token)
+// SendResetPasswordEmail provides a mock function with given fields: id, token, locale
+func (_m *IAuthService) SendResetPasswordEmail(id uint, token string, locale string) error {
+	ret := _m.Called(id, token, locale)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uint, string) error); ok {
-		r0 = rf(id, token)
+	if rf, ok := ret.Get(0).(func(uint, string, string) error); ok {
+		r0 = rf(id, token, locale)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -162,13 +191,13 @@ func (_m *IAuthService) SendResetPasswordEmail(id uint, token string) error {
 	return r0
 }
 
-// SendVerificationEmail provides a mock function with given fields: id, token
-func (_m *IAuthService) SendVerificationEmail(id uint, token int) error {
-	ret := _m.Called(id, token)
+// SendVerificationEmail provides a mock function with given fields: id, token, locale
+func (_m *IAuthService) SendVerificationEmail(id uint, token int, locale string) error {
+	ret := _m.Called(id, token, locale)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uint, int) error); ok {
-		r0 = rf(id, token)
+	if rf, ok := ret.Get(0).(func(uint, int, string) error); ok {
+		r0 = rf(id, token, locale)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -190,6 +219,22 @@ func (_m *IAuthService) VerifyToken(req request.VerifyTokenRequest) error {
 	return r0
 }
 
+// WithTrx provides a mock function with given fields: trxHandle
+func (_m *IAuthService) WithTrx(trxHandle *gorm.DB) service.IAuthService {
+	ret := _m.Called(trxHandle)
+
+	var r0 service.IAuthService
+	if rf, ok := ret.Get(0).(func(*gorm.DB) service.IAuthService); ok {
+		r0 = rf(trxHandle)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(service.IAuthService)
+		}
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewIAuthService interface {
 	mock.TestingT
 	Cleanup(func())
@@ -4,6 +4,8 @@ package mocks
 
 import (
 	request "github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	response "github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	model "github.com/felixlambertv/go-cleanplate/internal/model"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,6 +14,144 @@ type IMailService struct {
 	mock.Mock
 }
 
+// DeleteSuppression provides a mock function with given fields: email
+func (_m *IMailService) DeleteSuppression(email string) error {
+	ret := _m.Called(email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetEmailLogs provides a mock function with given fields: filter, p
+func (_m *IMailService) GetEmailLogs(filter request.EmailLogFilter, p model.Pagination) (*model.Pagination, error) {
+	ret := _m.Called(filter, p)
+
+	var r0 *model.Pagination
+	var r1 error
+	if rf, ok := ret.Get(0).(func(request.EmailLogFilter, model.Pagination) (*model.Pagination, error)); ok {
+		return rf(filter, p)
+	}
+	if rf, ok := ret.Get(0).(func(request.EmailLogFilter, model.Pagination) *model.Pagination); ok {
+		r0 = rf(filter, p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(request.EmailLogFilter, model.Pagination) error); ok {
+		r1 = rf(filter, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLocales provides a mock function with given fields:
+func (_m *IMailService) GetLocales() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// GetSuppressions provides a mock function with given fields: p
+func (_m *IMailService) GetSuppressions(p model.Pagination) (*model.Pagination, error) {
+	ret := _m.Called(p)
+
+	var r0 *model.Pagination
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.Pagination) (*model.Pagination, error)); ok {
+		return rf(p)
+	}
+	if rf, ok := ret.Get(0).(func(model.Pagination) *model.Pagination); ok {
+		r0 = rf(p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(model.Pagination) error); ok {
+		r1 = rf(p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTemplates provides a mock function with given fields:
+func (_m *IMailService) GetTemplates() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// HandleSNSMessage provides a mock function with given fields: body
+func (_m *IMailService) HandleSNSMessage(body []byte) error {
+	ret := _m.Called(body)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PreviewTemplate provides a mock function with given fields: name, locale
+func (_m *IMailService) PreviewTemplate(name string, locale string) (*response.MailPreviewResponse, error) {
+	ret := _m.Called(name, locale)
+
+	var r0 *response.MailPreviewResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*response.MailPreviewResponse, error)); ok {
+		return rf(name, locale)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *response.MailPreviewResponse); ok {
+		r0 = rf(name, locale)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MailPreviewResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(name, locale)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SendEmail provides a mock function with given fields: emailData
 func (_m *IMailService) SendEmail(emailData request.SendEmailRequest) error {
 	ret := _m.Called(emailData)
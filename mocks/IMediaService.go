@@ -4,8 +4,11 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 
 	request "github.com/felixlambertv/go-cleanplate/internal/controller/request"
+	response "github.com/felixlambertv/go-cleanplate/internal/controller/response"
+	model "github.com/felixlambertv/go-cleanplate/internal/model"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,23 +17,336 @@ type IMediaService struct {
 	mock.Mock
 }
 
-// UploadMedia provides a mock function with given fields: req, ctx
-func (_m *IMediaService) UploadMedia(req request.MediaUploadRequest, ctx context.Context) (string, error) {
-	ret := _m.Called(req, ctx)
+// AppendUpload provides a mock function with given fields: userID, id, offset, chunk, ctx
+func (_m *IMediaService) AppendUpload(userID uint, id uint, offset int64, chunk io.Reader, ctx context.Context) (*response.ResumableUploadResponse, error) {
+	ret := _m.Called(userID, id, offset, chunk, ctx)
 
-	var r0 string
+	var r0 *response.ResumableUploadResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(request.MediaUploadRequest, context.Context) (string, error)); ok {
-		return rf(req, ctx)
+	if rf, ok := ret.Get(0).(func(uint, uint, int64, io.Reader, context.Context) (*response.ResumableUploadResponse, error)); ok {
+		return rf(userID, id, offset, chunk, ctx)
 	}
-	if rf, ok := ret.Get(0).(func(request.MediaUploadRequest, context.Context) string); ok {
-		r0 = rf(req, ctx)
+	if rf, ok := ret.Get(0).(func(uint, uint, int64, io.Reader, context.Context) *response.ResumableUploadResponse); ok {
+		r0 = rf(userID, id, offset, chunk, ctx)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ResumableUploadResponse)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(request.MediaUploadRequest, context.Context) error); ok {
-		r1 = rf(req, ctx)
+	if rf, ok := ret.Get(1).(func(uint, uint, int64, io.Reader, context.Context) error); ok {
+		r1 = rf(userID, id, offset, chunk, ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AttachMedia provides a mock function with given fields: userID, id
+func (_m *IMediaService) AttachMedia(userID uint, id uint) error {
+	ret := _m.Called(userID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CompleteUpload provides a mock function with given fields: userID, id, ctx
+func (_m *IMediaService) CompleteUpload(userID uint, id uint, ctx context.Context) (*response.MediaResponse, error) {
+	ret := _m.Called(userID, id, ctx)
+
+	var r0 *response.MediaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) (*response.MediaResponse, error)); ok {
+		return rf(userID, id, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) *response.MediaResponse); ok {
+		r0 = rf(userID, id, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MediaResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, context.Context) error); ok {
+		r1 = rf(userID, id, ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateResumableUpload provides a mock function with given fields: userID, req
+func (_m *IMediaService) CreateResumableUpload(userID uint, req request.CreateMediaUploadRequest) (*response.ResumableUploadResponse, error) {
+	ret := _m.Called(userID, req)
+
+	var r0 *response.ResumableUploadResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, request.CreateMediaUploadRequest) (*response.ResumableUploadResponse, error)); ok {
+		return rf(userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(uint, request.CreateMediaUploadRequest) *response.ResumableUploadResponse); ok {
+		r0 = rf(userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ResumableUploadResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, request.CreateMediaUploadRequest) error); ok {
+		r1 = rf(userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUpload provides a mock function with given fields: userID, req, ctx
+func (_m *IMediaService) CreateUpload(userID uint, req request.CreateMediaUploadRequest, ctx context.Context) (*response.MediaUploadResponse, error) {
+	ret := _m.Called(userID, req, ctx)
+
+	var r0 *response.MediaUploadResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, request.CreateMediaUploadRequest, context.Context) (*response.MediaUploadResponse, error)); ok {
+		return rf(userID, req, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(uint, request.CreateMediaUploadRequest, context.Context) *response.MediaUploadResponse); ok {
+		r0 = rf(userID, req, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MediaUploadResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, request.CreateMediaUploadRequest, context.Context) error); ok {
+		r1 = rf(userID, req, ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteMedia provides a mock function with given fields: userID, id, ctx
+func (_m *IMediaService) DeleteMedia(userID uint, id uint, ctx context.Context) error {
+	ret := _m.Called(userID, id, ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) error); ok {
+		r0 = rf(userID, id, ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DetachMedia provides a mock function with given fields: id
+func (_m *IMediaService) DetachMedia(id uint) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DownloadMedia provides a mock function with given fields: userID, id, ctx
+func (_m *IMediaService) DownloadMedia(userID uint, id uint, ctx context.Context) (io.ReadCloser, *response.MediaDownload, error) {
+	ret := _m.Called(userID, id, ctx)
+
+	var r0 io.ReadCloser
+	var r1 *response.MediaDownload
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) (io.ReadCloser, *response.MediaDownload, error)); ok {
+		return rf(userID, id, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) io.ReadCloser); ok {
+		r0 = rf(userID, id, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, context.Context) *response.MediaDownload); ok {
+		r1 = rf(userID, id, ctx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*response.MediaDownload)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uint, uint, context.Context) error); ok {
+		r2 = rf(userID, id, ctx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMedia provides a mock function with given fields: userID, id, ctx
+func (_m *IMediaService) GetMedia(userID uint, id uint, ctx context.Context) (*response.MediaResponse, error) {
+	ret := _m.Called(userID, id, ctx)
+
+	var r0 *response.MediaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) (*response.MediaResponse, error)); ok {
+		return rf(userID, id, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, context.Context) *response.MediaResponse); ok {
+		r0 = rf(userID, id, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MediaResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, context.Context) error); ok {
+		r1 = rf(userID, id, ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMediaList provides a mock function with given fields: userID, filter, p, ctx
+func (_m *IMediaService) GetMediaList(userID uint, filter request.MediaFilter, p model.Pagination, ctx context.Context) (*model.Pagination, error) {
+	ret := _m.Called(userID, filter, p, ctx)
+
+	var r0 *model.Pagination
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, request.MediaFilter, model.Pagination, context.Context) (*model.Pagination, error)); ok {
+		return rf(userID, filter, p, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(uint, request.MediaFilter, model.Pagination, context.Context) *model.Pagination); ok {
+		r0 = rf(userID, filter, p, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, request.MediaFilter, model.Pagination, context.Context) error); ok {
+		r1 = rf(userID, filter, p, ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrphanReport provides a mock function with given fields: p
+func (_m *IMediaService) GetOrphanReport(p model.Pagination) (*response.OrphanMediaReport, error) {
+	ret := _m.Called(p)
+
+	var r0 *response.OrphanMediaReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.Pagination) (*response.OrphanMediaReport, error)); ok {
+		return rf(p)
+	}
+	if rf, ok := ret.Get(0).(func(model.Pagination) *response.OrphanMediaReport); ok {
+		r0 = rf(p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.OrphanMediaReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(model.Pagination) error); ok {
+		r1 = rf(p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetResumableUpload provides a mock function with given fields: userID, id
+func (_m *IMediaService) GetResumableUpload(userID uint, id uint) (*response.ResumableUploadResponse, error) {
+	ret := _m.Called(userID, id)
+
+	var r0 *response.ResumableUploadResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint) (*response.ResumableUploadResponse, error)); ok {
+		return rf(userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint) *response.ResumableUploadResponse); ok {
+		r0 = rf(userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ResumableUploadResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint) error); ok {
+		r1 = rf(userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SweepOrphans provides a mock function with given fields: dryRun, ctx
+func (_m *IMediaService) SweepOrphans(dryRun bool, ctx context.Context) (*response.MediaSweepResponse, error) {
+	ret := _m.Called(dryRun, ctx)
+
+	var r0 *response.MediaSweepResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(bool, context.Context) (*response.MediaSweepResponse, error)); ok {
+		return rf(dryRun, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(bool, context.Context) *response.MediaSweepResponse); ok {
+		r0 = rf(dryRun, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MediaSweepResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bool, context.Context) error); ok {
+		r1 = rf(dryRun, ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UploadMedia provides a mock function with given fields: userID, req, ctx
+func (_m *IMediaService) UploadMedia(userID uint, req request.MediaUploadRequest, ctx context.Context) (*response.MediaResponse, error) {
+	ret := _m.Called(userID, req, ctx)
+
+	var r0 *response.MediaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, request.MediaUploadRequest, context.Context) (*response.MediaResponse, error)); ok {
+		return rf(userID, req, ctx)
+	}
+	if rf, ok := ret.Get(0).(func(uint, request.MediaUploadRequest, context.Context) *response.MediaResponse); ok {
+		r0 = rf(userID, req, ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MediaResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, request.MediaUploadRequest, context.Context) error); ok {
+		r1 = rf(userID, req, ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
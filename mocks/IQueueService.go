@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	time "time"
+
 	consttype "github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +14,20 @@ type IQueueService struct {
 	mock.Mock
 }
 
+// PurgeProcessedMessages provides a mock function with given fields:
+func (_m *IQueueService) PurgeProcessedMessages() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReceiveMessage provides a mock function with given fields:
 func (_m *IQueueService) ReceiveMessage() error {
 	ret := _m.Called()
@@ -26,6 +42,20 @@ func (_m *IQueueService) ReceiveMessage() error {
 	return r0
 }
 
+// SendDelayedMessage provides a mock function with given fields: messageBody, messageType, deduplicationId, deliverAt
+func (_m *IQueueService) SendDelayedMessage(messageBody string, messageType consttype.QueueType, deduplicationId string, deliverAt time.Time) error {
+	ret := _m.Called(messageBody, messageType, deduplicationId, deliverAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, consttype.QueueType, string, time.Time) error); ok {
+		r0 = rf(messageBody, messageType, deduplicationId, deliverAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendMessage provides a mock function with given fields: messageBody, messageType
 func (_m *IQueueService) SendMessage(messageBody string, messageType consttype.QueueType) error {
 	ret := _m.Called(messageBody, messageType)
@@ -40,6 +70,20 @@ func (_m *IQueueService) SendMessage(messageBody string, messageType consttype.Q
 	return r0
 }
 
+// SendMessageWithDeduplication provides a mock function with given fields: messageBody, messageType, deduplicationId
+func (_m *IQueueService) SendMessageWithDeduplication(messageBody string, messageType consttype.QueueType, deduplicationId string) error {
+	ret := _m.Called(messageBody, messageType, deduplicationId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, consttype.QueueType, string) error); ok {
+		r0 = rf(messageBody, messageType, deduplicationId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewIQueueService interface {
 	mock.TestingT
 	Cleanup(func())
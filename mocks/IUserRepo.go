@@ -3,13 +3,13 @@
 package mocks
 
 import (
+	time "time"
+
+	response "github.com/felixlambertv/go-cleanplate/internal/controller/response"
 	model "github.com/felixlambertv/go-cleanplate/internal/model"
+	repository "github.com/felixlambertv/go-cleanplate/internal/repository"
 	mock "github.com/stretchr/testify/mock"
 	gorm "gorm.io/gorm"
-
-	repository "github.com/felixlambertv/go-cleanplate/internal/repository"
-
-	response "github.com/felixlambertv/go-cleanplate/internal/controller/response"
 )
 
 // IUserRepo is an autogenerated mock type for the IUserRepo type
@@ -17,6 +17,56 @@ type IUserRepo struct {
 	mock.Mock
 }
 
+// ClearExpiredResetPasswordTokens provides a mock function with given fields: sentBefore
+func (_m *IUserRepo) ClearExpiredResetPasswordTokens(sentBefore time.Time) (int64, error) {
+	ret := _m.Called(sentBefore)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(sentBefore)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(sentBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(sentBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: user
+func (_m *IUserRepo) Create(user *model.User) (*model.User, error) {
+	ret := _m.Called(user)
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.User) (*model.User, error)); ok {
+		return rf(user)
+	}
+	if rf, ok := ret.Get(0).(func(*model.User) *model.User); ok {
+		r0 = rf(user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.User) error); ok {
+		r1 = rf(user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteUser provides a mock function with given fields: user
 func (_m *IUserRepo) DeleteUser(user model.User) error {
 	ret := _m.Called(user)
@@ -32,19 +82,19 @@ func (_m *IUserRepo) DeleteUser(user model.User) error {
 }
 
 // FindAll provides a mock function with given fields: p
-func (_m *IUserRepo) FindAll(p model.Pagination) (*model.Pagination, error) {
+func (_m *IUserRepo) FindAll(p model.Pagination) (*model.Page[response.UserResponse], error) {
 	ret := _m.Called(p)
 
-	var r0 *model.Pagination
+	var r0 *model.Page[response.UserResponse]
 	var r1 error
-	if rf, ok := ret.Get(0).(func(model.Pagination) (*model.Pagination, error)); ok {
+	if rf, ok := ret.Get(0).(func(model.Pagination) (*model.Page[response.UserResponse], error)); ok {
 		return rf(p)
 	}
-	if rf, ok := ret.Get(0).(func(model.Pagination) *model.Pagination); ok {
+	if rf, ok := ret.Get(0).(func(model.Pagination) *model.Page[response.UserResponse]); ok {
 		r0 = rf(p)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Pagination)
+			r0 = ret.Get(0).(*model.Page[response.UserResponse])
 		}
 	}
 
@@ -109,25 +159,25 @@ func (_m *IUserRepo) FindById(id uint) (*response.UserResponse, error) {
 	return r0, r1
 }
 
-// Store provides a mock function with given fields: user
-func (_m *IUserRepo) Store(user *model.User) (*model.User, error) {
-	ret := _m.Called(user)
+// FindUnconfirmed provides a mock function with given fields: createdFrom, createdTo
+func (_m *IUserRepo) FindUnconfirmed(createdFrom time.Time, createdTo time.Time) ([]response.UserResponse, error) {
+	ret := _m.Called(createdFrom, createdTo)
 
-	var r0 *model.User
+	var r0 []response.UserResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*model.User) (*model.User, error)); ok {
-		return rf(user)
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time) ([]response.UserResponse, error)); ok {
+		return rf(createdFrom, createdTo)
 	}
-	if rf, ok := ret.Get(0).(func(*model.User) *model.User); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time) []response.UserResponse); ok {
+		r0 = rf(createdFrom, createdTo)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.User)
+			r0 = ret.Get(0).([]response.UserResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*model.User) error); ok {
-		r1 = rf(user)
+	if rf, ok := ret.Get(1).(func(time.Time, time.Time) error); ok {
+		r1 = rf(createdFrom, createdTo)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -135,6 +185,20 @@ func (_m *IUserRepo) Store(user *model.User) (*model.User, error) {
 	return r0, r1
 }
 
+// SetAvatar provides a mock function with given fields: id, mediaID
+func (_m *IUserRepo) SetAvatar(id uint, mediaID *uint) error {
+	ret := _m.Called(id, mediaID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, *uint) error); ok {
+		r0 = rf(id, mediaID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: user, userID
 func (_m *IUserRepo) Update(user model.User, userID uint) (*model.User, error) {
 	ret := _m.Called(user, userID)
@@ -3,15 +3,12 @@
 package mocks
 
 import (
-	model "github.com/felixlambertv/go-cleanplate/internal/model"
-	mock "github.com/stretchr/testify/mock"
-	gorm "gorm.io/gorm"
-
 	request "github.com/felixlambertv/go-cleanplate/internal/controller/request"
-
 	response "github.com/felixlambertv/go-cleanplate/internal/controller/response"
-
+	model "github.com/felixlambertv/go-cleanplate/internal/model"
 	service "github.com/felixlambertv/go-cleanplate/internal/service"
+	mock "github.com/stretchr/testify/mock"
+	gorm "gorm.io/gorm"
 )
 
 // IUserService is an autogenerated mock type for the IUserService type
@@ -86,19 +83,19 @@ func (_m *IUserService) GetUser(id uint) (*response.UserResponse, error) {
 }
 
 // GetUsers provides a mock function with given fields: paginationReq
-func (_m *IUserService) GetUsers(paginationReq model.Pagination) (*model.Pagination, error) {
+func (_m *IUserService) GetUsers(paginationReq model.Pagination) (*model.Page[response.UserResponse], error) {
 	ret := _m.Called(paginationReq)
 
-	var r0 *model.Pagination
+	var r0 *model.Page[response.UserResponse]
 	var r1 error
-	if rf, ok := ret.Get(0).(func(model.Pagination) (*model.Pagination, error)); ok {
+	if rf, ok := ret.Get(0).(func(model.Pagination) (*model.Page[response.UserResponse], error)); ok {
 		return rf(paginationReq)
 	}
-	if rf, ok := ret.Get(0).(func(model.Pagination) *model.Pagination); ok {
+	if rf, ok := ret.Get(0).(func(model.Pagination) *model.Page[response.UserResponse]); ok {
 		r0 = rf(paginationReq)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Pagination)
+			r0 = ret.Get(0).(*model.Page[response.UserResponse])
 		}
 	}
 
@@ -111,6 +108,32 @@ func (_m *IUserService) GetUsers(paginationReq model.Pagination) (*model.Paginat
 	return r0, r1
 }
 
+// SetAvatar provides a mock function with given fields: userID, mediaID
+func (_m *IUserService) SetAvatar(userID uint, mediaID *uint) (*response.UserResponse, error) {
+	ret := _m.Called(userID, mediaID)
+
+	var r0 *response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, *uint) (*response.UserResponse, error)); ok {
+		return rf(userID, mediaID)
+	}
+	if rf, ok := ret.Get(0).(func(uint, *uint) *response.UserResponse); ok {
+		r0 = rf(userID, mediaID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, *uint) error); ok {
+		r1 = rf(userID, mediaID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateUserCountry provides a mock function with given fields: req, userID
 func (_m *IUserService) UpdateUserCountry(req request.UpdateUserCountryRequest, userID uint) (*response.UserResponse, error) {
 	ret := _m.Called(req, userID)
@@ -137,6 +160,32 @@ func (_m *IUserService) UpdateUserCountry(req request.UpdateUserCountryRequest,
 	return r0, r1
 }
 
+// UpdateUserPhone provides a mock function with given fields: req, userID
+func (_m *IUserService) UpdateUserPhone(req request.UpdateUserPhoneRequest, userID uint) (*response.UserResponse, error) {
+	ret := _m.Called(req, userID)
+
+	var r0 *response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(request.UpdateUserPhoneRequest, uint) (*response.UserResponse, error)); ok {
+		return rf(req, userID)
+	}
+	if rf, ok := ret.Get(0).(func(request.UpdateUserPhoneRequest, uint) *response.UserResponse); ok {
+		r0 = rf(req, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(request.UpdateUserPhoneRequest, uint) error); ok {
+		r1 = rf(req, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WithTrx provides a mock function with given fields: trxHandle
 func (_m *IUserService) WithTrx(trxHandle *gorm.DB) service.IUserService {
 	ret := _m.Called(trxHandle)
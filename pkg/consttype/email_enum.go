@@ -0,0 +1,27 @@
+package consttype
+
+type EmailStatus string
+
+const (
+	EMAIL_SENT       EmailStatus = "sent"
+	EMAIL_FAILED     EmailStatus = "failed"
+	EMAIL_SUPPRESSED EmailStatus = "suppressed"
+	EMAIL_DELIVERED  EmailStatus = "delivered"
+	EMAIL_BOUNCED    EmailStatus = "bounced"
+	EMAIL_COMPLAINED EmailStatus = "complained"
+)
+
+func (e EmailStatus) String() string {
+	return string(e)
+}
+
+type SuppressionReason string
+
+const (
+	SUPPRESSION_BOUNCE    SuppressionReason = "bounce"
+	SUPPRESSION_COMPLAINT SuppressionReason = "complaint"
+)
+
+func (s SuppressionReason) String() string {
+	return string(s)
+}
@@ -0,0 +1,24 @@
+package consttype
+
+type JobStatus string
+
+const (
+	JOB_RUNNING   JobStatus = "running"
+	JOB_SUCCEEDED JobStatus = "succeeded"
+	JOB_FAILED    JobStatus = "failed"
+)
+
+func (j JobStatus) String() string {
+	return string(j)
+}
+
+type JobTrigger string
+
+const (
+	JOB_TRIGGER_SCHEDULE JobTrigger = "schedule"
+	JOB_TRIGGER_MANUAL   JobTrigger = "manual"
+)
+
+func (j JobTrigger) String() string {
+	return string(j)
+}
@@ -0,0 +1,31 @@
+package consttype
+
+type MediaStatus string
+
+const (
+	// MEDIA_PENDING media waits for its upload to complete.
+	MEDIA_PENDING MediaStatus = "pending"
+	// MEDIA_PENDING_SCAN media is uploaded and kept in quarantine until it is
+	// scanned for malware.
+	MEDIA_PENDING_SCAN MediaStatus = "pending_scan"
+	MEDIA_READY        MediaStatus = "ready"
+	// MEDIA_INFECTED media was found to contain malware, its file is deleted.
+	MEDIA_INFECTED MediaStatus = "infected"
+)
+
+func (m MediaStatus) String() string {
+	return string(m)
+}
+
+// MediaVisibility decides who can download a media. Private media are only
+// served through URLs that expire or to their owner.
+type MediaVisibility string
+
+const (
+	MEDIA_PUBLIC  MediaVisibility = "public"
+	MEDIA_PRIVATE MediaVisibility = "private"
+)
+
+func (m MediaVisibility) String() string {
+	return string(m)
+}
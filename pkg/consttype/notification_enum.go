@@ -0,0 +1,38 @@
+package consttype
+
+type NotificationType string
+
+const (
+	NOTIFICATION_VERIFY_EMAIL   NotificationType = "verify_email"
+	NOTIFICATION_RESET_PASSWORD NotificationType = "reset_password"
+	NOTIFICATION_PRODUCT_UPDATE NotificationType = "product_update"
+	NOTIFICATION_PROMOTION      NotificationType = "promotion"
+)
+
+func (n NotificationType) String() string {
+	return string(n)
+}
+
+type NotificationChannel string
+
+const (
+	CHANNEL_EMAIL  NotificationChannel = "email"
+	CHANNEL_SMS    NotificationChannel = "sms"
+	CHANNEL_PUSH   NotificationChannel = "push"
+	CHANNEL_IN_APP NotificationChannel = "in_app"
+)
+
+func (n NotificationChannel) String() string {
+	return string(n)
+}
+
+type DevicePlatform string
+
+const (
+	PLATFORM_IOS     DevicePlatform = "ios"
+	PLATFORM_ANDROID DevicePlatform = "android"
+)
+
+func (d DevicePlatform) String() string {
+	return string(d)
+}
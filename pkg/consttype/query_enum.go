@@ -0,0 +1,34 @@
+package consttype
+
+// FilterOperator compares a field of a listed resource with the value of a
+// filter[field][operator] query parameter.
+type FilterOperator string
+
+const (
+	FILTER_EQ FilterOperator = "eq"
+	// FILTER_IN takes a comma separated list of values.
+	FILTER_IN  FilterOperator = "in"
+	FILTER_GTE FilterOperator = "gte"
+	FILTER_LTE FilterOperator = "lte"
+	// FILTER_LIKE matches the value anywhere in the field, ignoring case.
+	FILTER_LIKE FilterOperator = "like"
+)
+
+func (f FilterOperator) String() string {
+	return string(f)
+}
+
+// FieldType is how the value of a filter is parsed.
+type FieldType string
+
+const (
+	FIELD_STRING FieldType = "string"
+	FIELD_INT    FieldType = "int"
+	FIELD_BOOL   FieldType = "bool"
+	// FIELD_DATE compares the day of a timestamp with a DATEFORMAT value.
+	FIELD_DATE FieldType = "date"
+)
+
+func (f FieldType) String() string {
+	return string(f)
+}
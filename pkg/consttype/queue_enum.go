@@ -4,6 +4,12 @@ type QueueType string
 
 const (
 	SEND_EMAIL QueueType = "send_email"
+	SEND_PUSH  QueueType = "send_push"
+	SEND_SMS   QueueType = "send_sms"
+	// PROCESS_MEDIA generates the image variants of a ready media.
+	PROCESS_MEDIA QueueType = "process_media"
+	// SCAN_MEDIA scans an uploaded media for malware before it is served.
+	SCAN_MEDIA QueueType = "scan_media"
 )
 
 func (q QueueType) String() string {
@@ -1,22 +1,18 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
-	"math"
-	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gobeam/stringy"
-
 	"github.com/felixlambertv/go-cleanplate/internal/model"
 	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
 	"github.com/gin-gonic/gin"
-)
-
-var (
-	suffixes [5]string
+	"golang.org/x/text/language"
 )
 
 func CheckWhitelistUrl(url string) bool {
@@ -29,154 +25,294 @@ func CheckWhitelistUrl(url string) bool {
 	return whitelistedUrl[splittedUrl[1]]
 }
 
-func GeneratePaginationFromRequest(ctx *gin.Context, dbModel interface{}) model.Pagination {
-	// Initializing default
-	//	var mode string
-	limit := 10
-	page := 1
-	search := ""
-	level := ""
-	sort := "id"
-	direction := "asc"
-	var createdFrom time.Time
-	var createdTo time.Time
+// GetRequestLocale returns the preferred locale of the Accept-Language header as
+// a BCP 47 tag, or an empty string when the header is missing or invalid.
+func GetRequestLocale(ctx *gin.Context) string {
+	tags, _, err := language.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+
+	return tags[0].String()
+}
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+var filterKey = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+// QueryErrors are the invalid parameters of a list request.
+type QueryErrors []ValidationErrorMsg
+
+func (q QueryErrors) Error() string {
+	messages := make([]string, len(q))
+	for i, e := range q {
+		messages[i] = e.Field + ": " + e.Message
+	}
+	return "query not valid: " + strings.Join(messages, ", ")
+}
+
+// GeneratePaginationFromRequest reads the page, sorts, filters and search of a
+// list request allowed by spec, anything else is a QueryErrors. sort is a
+// comma separated list of fields, descending when prefixed with "-" and in
+// direction otherwise. Filters are filter[field]=value, the same as
+// filter[field][eq]=value, or filter[field][operator]=value.
+//
+// A cursor parameter switches to cursor mode instead of pages, empty for the
+// first page and then a cursor of the previous response. count=false skips
+// counting the total.
+func GeneratePaginationFromRequest(ctx *gin.Context, spec model.QuerySpec) (model.Pagination, error) {
+	var errs QueryErrors
+	invalid := func(field string, message string) {
+		errs = append(errs, ValidationErrorMsg{Field: field, Message: message})
+	}
 
 	query := ctx.Request.URL.Query()
-	for key, value := range query {
-		queryValue := value[len(value)-1]
-		switch key {
-		case "limit":
-			limit, _ = strconv.Atoi(queryValue)
-			break
-		case "page":
-			page, _ = strconv.Atoi(queryValue)
-			break
-		case "q":
-			search = queryValue
-			break
-		case "sort":
-			if queryValue != "" {
-				str := stringy.New(queryValue)
-				snakeStr := str.SnakeCase("?", "")
-				sort = snakeStr.ToLower()
-			}
-			break
-		case "direction":
-			queryValue = strings.ToLower(queryValue)
-			if queryValue == "asc" || queryValue == "desc" {
-				direction = queryValue
-			}
+	p := model.Pagination{
+		Limit:         defaultPageLimit,
+		Page:          1,
+		Search:        strings.TrimSpace(query.Get("q")),
+		SearchColumns: spec.Search,
+	}
 
-			break
-		case "level":
-			queryValue = strings.ToLower(queryValue)
-			if queryValue == "beginner" || queryValue == "intermediate" || queryValue == "advanced" {
-				level = queryValue
-			}
-			break
-		case "createdFrom":
-			queryValue = strings.ToLower(queryValue)
-			if queryValue != "" {
-				date, err := time.Parse(consttype.DATEFORMAT, queryValue)
-				if err == nil {
-					createdFrom = date
-				}
-			}
-			break
-		case "createdTo":
-			queryValue = strings.ToLower(queryValue)
-			if queryValue != "" {
-				date, err := time.Parse(consttype.DATEFORMAT, queryValue)
-				if err == nil {
-					createdTo = date
-				}
-			}
-			break
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			invalid("limit", fmt.Sprintf("Should be a number from 1 to %d", maxPageLimit))
+		} else {
+			p.Limit = limit
 		}
 	}
 
-	return model.Pagination{
-		Limit:     limit,
-		Page:      page,
-		Sort:      sort,
-		Direction: direction,
-		Search:    search,
-		Filter: model.Filter{
-			Level:       level,
-			CreatedFrom: createdFrom,
-			CreatedTo:   createdTo,
-		},
+	p.CursorMode = query.Has("cursor")
+	if p.CursorMode {
+		p.Page = 0
+	}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		switch {
+		case p.CursorMode:
+			invalid("page", "Cannot be used with cursor")
+		case err != nil || page < 1:
+			invalid("page", "Should be a number from 1")
+		default:
+			p.Page = page
+		}
+	}
+
+	if value := query.Get("count"); value != "" {
+		count, err := strconv.ParseBool(value)
+		if err != nil {
+			invalid("count", "Should be true or false")
+		}
+		p.SkipCount = err == nil && !count
+	}
+
+	p.Direction = strings.ToLower(query.Get("direction"))
+	if p.Direction != "" && p.Direction != "asc" && p.Direction != "desc" {
+		invalid("direction", "Should be asc or desc")
+	}
+
+	// The direction of a request without sort applies to the default sort.
+	p.Sort = query.Get("sort")
+	if p.Sort == "" {
+		p.Sort = spec.DefaultSort
+		if p.Direction != "" {
+			p.Sort = strings.ReplaceAll(p.Sort, "-", "")
+		}
+	}
+	for _, field := range strings.Split(p.Sort, ",") {
+		field = strings.TrimSpace(field)
+		desc := p.Direction == "desc"
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			desc = true
+		}
+		if field == "" {
+			continue
+		}
+
+		// Relevance sorts from the best match whatever the direction.
+		if field == model.RelevanceSort && spec.TextSearch != nil {
+			p.Sorts = append(p.Sorts, model.SortField{Field: field, Desc: true})
+			continue
+		}
+
+		column, ok := spec.Sorts[field]
+		if !ok {
+			invalid("sort", "Cannot sort by "+field)
+			continue
+		}
+		p.Sorts = append(p.Sorts, model.SortField{Field: field, Column: column, Desc: desc})
+	}
+
+	if p.CursorMode {
+		readCursor(&p, spec, query.Get("cursor"), invalid)
 	}
-}
 
-func GetExtensionType(ext string) string {
-	images := map[string]bool{".jpg": true, ".jpeg": true, ".png": true}
-	video := map[string]bool{".mp4": true}
-	sound := map[string]bool{".mp3": true}
+	p.TextSearch = spec.TextSearch
+	if p.Search != "" && len(spec.Search) == 0 && spec.TextSearch == nil {
+		invalid("q", "Search is not supported")
+	}
+	if hasSort(p.Sorts, model.RelevanceSort) {
+		if p.Search == "" {
+			invalid("sort", "Cannot sort by relevance without q")
+		}
+		if p.CursorMode {
+			invalid("sort", "Cannot sort by relevance with cursor")
+		}
+	}
 
-	filetype := "others"
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			invalid(key, "Should be filter[field] or filter[field][operator]")
+			continue
+		}
+
+		field, operator := match[1], consttype.FilterOperator(match[2])
+		if operator == "" {
+			operator = consttype.FILTER_EQ
+		}
 
-	if images[ext] {
-		filetype = "image"
-	} else if video[ext] {
-		filetype = "video"
-	} else if sound[ext] {
-		filetype = "sound"
+		filter, ok := spec.Filters[field]
+		if !ok {
+			invalid(key, "Cannot filter by "+field)
+			continue
+		}
+		if !hasOperator(filter.Operators, operator) {
+			invalid(key, fmt.Sprintf("Cannot filter %s with %s", field, operator))
+			continue
+		}
+
+		values := query[key]
+		value, err := parseFilterValue(filter.Type, operator, values[len(values)-1])
+		if err != nil {
+			invalid(key, err.Error())
+			continue
+		}
+		p.Filters = append(p.Filters, model.FilterCondition{
+			Column:   filter.Column,
+			Type:     filter.Type,
+			Operator: operator,
+			Value:    value,
+		})
 	}
 
-	return filetype
+	if len(errs) > 0 {
+		return p, errs
+	}
+	return p, nil
 }
 
-func ValidateExtension(ext string) error {
-	valid := map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".mp4": true, ".mp3": true}
+// readCursor ends the sorts with id, so every row has its own position, and
+// reads the position of the cursor, which must come from the same sort.
+func readCursor(p *model.Pagination, spec model.QuerySpec, cursor string, invalid func(field string, message string)) {
+	idColumn, ok := spec.Sorts["id"]
+	if !ok {
+		invalid("cursor", "Cursor is not supported")
+		return
+	}
+	if len(p.Sorts) == 0 {
+		p.Sorts = []model.SortField{{Field: "id", Column: idColumn, Desc: p.Direction == "desc"}}
+	}
+	if last := p.Sorts[len(p.Sorts)-1]; last.Field != "id" {
+		p.Sorts = append(p.Sorts, model.SortField{Field: "id", Column: idColumn, Desc: last.Desc})
+	}
+
+	if cursor == "" {
+		return
+	}
 
-	if valid[ext] {
-		return nil
-	} else {
-		keys := reflect.ValueOf(valid).MapKeys()
-		return fmt.Errorf("only allowed file extension : %s", keys)
+	keyset, err := model.DecodeCursor(cursor)
+	if err != nil {
+		invalid("cursor", "Should be a cursor of this list")
+		return
 	}
+	if keyset.Sort != p.Sort || len(keyset.Values) != len(p.Sorts) {
+		invalid("cursor", "Should be a cursor of the same sort")
+		return
+	}
+	p.Keyset = keyset
 }
 
-func GetReadableFileSize(size float64, ext string) error {
-	suffixes[0] = "B"
-	suffixes[1] = "KB"
-	suffixes[2] = "MB"
-	suffixes[3] = "GB"
-	suffixes[4] = "TB"
-	maxImageFile := 0.6
-	maxImageFileSuffix := "MB"
-	maxVideoSize := 30
-	maxVideoSizeSuffix := "MB"
-	maxSoundSize := 10
-	maxSoundSizeSuffix := "MB"
-
-	base := math.Log(size) / math.Log(1024)
-	getSize := Round(math.Pow(1024, base-math.Floor(base)), .5, 2)
-	getSuffix := suffixes[int(math.Floor(base))]
-	if getSuffix == "KB" {
-		getSize = math.Ceil((getSize/1000)*100) / 100
-		getSuffix = "MB"
-	}
-
-	if maxImageFileSuffix == getSuffix || maxVideoSizeSuffix == getSuffix {
-		switch ext {
-		case "image":
-			if float64(getSize) > maxImageFile || getSuffix == "GB" || getSuffix == "TB" {
-				return fmt.Errorf("image size is too big. Maximum size is %f %s", float64(maxImageFile), maxImageFileSuffix)
-			}
-			return nil
-		case "video":
-			if int(getSize) > maxVideoSize || getSuffix == "GB" || getSuffix == "TB" {
-				return fmt.Errorf("video size is too big. Maximum size is %d %s", maxVideoSize, maxVideoSizeSuffix)
-			}
-			return nil
-		case "sound":
-			if int(getSize) > maxSoundSize || getSuffix == "GB" || getSuffix == "TB" {
-				return fmt.Errorf("sound size is too big. Maximum size is %d %s", maxSoundSize, maxSoundSizeSuffix)
+func hasOperator(operators []consttype.FilterOperator, operator consttype.FilterOperator) bool {
+	for _, allowed := range operators {
+		if allowed == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSort(sorts []model.SortField, field string) bool {
+	for _, sort := range sorts {
+		if sort.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilterValue parses the value of a filter as the type of its field, a
+// FILTER_IN value as a list and a FILTER_LIKE value as a string.
+func parseFilterValue(fieldType consttype.FieldType, operator consttype.FilterOperator, raw string) (interface{}, error) {
+	switch operator {
+	case consttype.FILTER_LIKE:
+		if raw == "" {
+			return nil, errors.New("Should not be empty")
+		}
+		return raw, nil
+	case consttype.FILTER_IN:
+		parts := strings.Split(raw, ",")
+		values := make([]interface{}, len(parts))
+		for i, part := range parts {
+			value, err := parseFieldValue(fieldType, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
 			}
-			return nil
+			values[i] = value
+		}
+		return values, nil
+	}
+	return parseFieldValue(fieldType, raw)
+}
+
+func parseFieldValue(fieldType consttype.FieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case consttype.FIELD_INT:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("Should be a number")
+		}
+		return value, nil
+	case consttype.FIELD_BOOL:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("Should be true or false")
+		}
+		return value, nil
+	case consttype.FIELD_DATE:
+		_, err := time.Parse(consttype.DATEFORMAT, raw)
+		if err != nil {
+			return nil, errors.New("Should be a date such as " + consttype.DATEFORMAT)
 		}
+		return raw, nil
+	}
+
+	if raw == "" {
+		return nil, errors.New("Should not be empty")
 	}
-	return nil
+	return raw, nil
 }
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felixlambertv/go-cleanplate/internal/model"
+	"github.com/felixlambertv/go-cleanplate/pkg/consttype"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testQuery = model.QuerySpec{
+	Sorts: map[string]string{"id": "id", "createdAt": "created_at", "name": "full_name"},
+	Filters: map[string]model.FilterSpec{
+		"level":     {Column: "user_level", Type: consttype.FIELD_INT, Operators: []consttype.FilterOperator{consttype.FILTER_EQ, consttype.FILTER_IN}},
+		"name":      {Column: "full_name", Type: consttype.FIELD_STRING, Operators: []consttype.FilterOperator{consttype.FILTER_LIKE}},
+		"createdAt": {Column: "created_at", Type: consttype.FIELD_DATE, Operators: []consttype.FilterOperator{consttype.FILTER_GTE, consttype.FILTER_LTE}},
+	},
+	Search:      []string{"full_name", "email"},
+	DefaultSort: "-id",
+}
+
+func paginationFromQuery(query string) (model.Pagination, error) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/users?"+query, nil)
+	return GeneratePaginationFromRequest(ctx, testQuery)
+}
+
+func TestRequest_GeneratePaginationShouldUseDefaults(t *testing.T) {
+	p, err := paginationFromQuery("")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 10, p.Limit)
+	assert.Equal(t, 1, p.Page)
+	assert.Equal(t, []model.SortField{{Field: "id", Column: "id", Desc: true}}, p.Sorts)
+
+	p, err = paginationFromQuery("direction=asc")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.SortField{{Field: "id", Column: "id", Desc: false}}, p.Sorts)
+}
+
+func TestRequest_GeneratePaginationShouldReadSortsFiltersAndSearch(t *testing.T) {
+	p, err := paginationFromQuery("limit=20&page=3&sort=-createdAt,name&q=ann&filter[level][in]=0,1&filter[name][like]=an&filter[createdAt][gte]=2023-01-01")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 20, p.Limit)
+	assert.Equal(t, 3, p.Page)
+	assert.Equal(t, []model.SortField{{Field: "createdAt", Column: "created_at", Desc: true}, {Field: "name", Column: "full_name", Desc: false}}, p.Sorts)
+	assert.Equal(t, "ann", p.Search)
+	assert.Equal(t, []string{"full_name", "email"}, p.SearchColumns)
+	assert.Equal(t, []model.FilterCondition{
+		{Column: "created_at", Type: consttype.FIELD_DATE, Operator: consttype.FILTER_GTE, Value: "2023-01-01"},
+		{Column: "user_level", Type: consttype.FIELD_INT, Operator: consttype.FILTER_IN, Value: []interface{}{int64(0), int64(1)}},
+		{Column: "full_name", Type: consttype.FIELD_STRING, Operator: consttype.FILTER_LIKE, Value: "an"},
+	}, p.Filters)
+}
+
+func TestRequest_GeneratePaginationShouldRejectInvalidParameters(t *testing.T) {
+	_, err := paginationFromQuery("limit=1000&page=0&direction=up&sort=id%3Bdrop%20table%20users&filter[password]=x&filter[level][like]=1&filter[createdAt][gte]=yesterday&filter[level]=one&filter[a][b][c]=1")
+
+	var qe QueryErrors
+	assert.ErrorAs(t, err, &qe)
+	fields := make([]string, len(qe))
+	for i, e := range qe {
+		fields[i] = e.Field
+	}
+	assert.Equal(t, []string{
+		"limit", "page", "direction", "sort",
+		"filter[a][b][c]", "filter[createdAt][gte]", "filter[level]", "filter[level][like]", "filter[password]",
+	}, fields)
+	assert.Equal(t, []ValidationErrorMsg(qe), ValidationResponse(err))
+}
+
+func TestRequest_GeneratePaginationShouldReadCursor(t *testing.T) {
+	p, err := paginationFromQuery("cursor=&sort=-createdAt&count=false")
+
+	assert.Nil(t, err)
+	assert.True(t, p.CursorMode)
+	assert.True(t, p.SkipCount)
+	assert.Nil(t, p.Keyset)
+	assert.Equal(t, 0, p.Page)
+	assert.Equal(t, []model.SortField{{Field: "createdAt", Column: "created_at", Desc: true}, {Field: "id", Column: "id", Desc: true}}, p.Sorts)
+
+	cursor, _ := model.EncodeCursor(model.Keyset{Sort: "-createdAt", Values: []interface{}{"2023-01-01T00:00:00Z", 42}})
+	p, err = paginationFromQuery("sort=-createdAt&cursor=" + cursor)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"2023-01-01T00:00:00Z", int64(42)}, p.Keyset.Values)
+
+	_, err = paginationFromQuery("sort=name&page=2&cursor=" + cursor)
+
+	assert.Equal(t, QueryErrors{
+		{Field: "page", Message: "Cannot be used with cursor"},
+		{Field: "cursor", Message: "Should be a cursor of the same sort"},
+	}, err)
+
+	_, err = paginationFromQuery("cursor=bm90IGEgY3Vyc29y")
+
+	assert.Equal(t, QueryErrors{{Field: "cursor", Message: "Should be a cursor of this list"}}, err)
+}
+
+func TestRequest_GeneratePaginationShouldSortByRelevance(t *testing.T) {
+	spec := testQuery
+	spec.TextSearch = &model.TextSearch{Table: "users", Vector: "search_vector", Config: "simple"}
+	query := func(query string) (model.Pagination, error) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", "/users?"+query, nil)
+		return GeneratePaginationFromRequest(ctx, spec)
+	}
+
+	p, err := query("q=ann&sort=relevance,name")
+
+	assert.Nil(t, err)
+	assert.Equal(t, spec.TextSearch, p.TextSearch)
+	assert.Equal(t, []model.SortField{{Field: "relevance", Desc: true}, {Field: "name", Column: "full_name", Desc: false}}, p.Sorts)
+
+	_, err = query("sort=relevance&cursor=")
+
+	assert.Equal(t, QueryErrors{
+		{Field: "sort", Message: "Cannot sort by relevance without q"},
+		{Field: "sort", Message: "Cannot sort by relevance with cursor"},
+	}, err)
+
+	_, err = paginationFromQuery("q=ann&sort=relevance")
+
+	assert.Equal(t, QueryErrors{{Field: "sort", Message: "Cannot sort by relevance"}}, err)
+}
@@ -64,6 +64,11 @@ func ValidationResponse(err error) []ValidationErrorMsg {
 		return out
 	}
 
+	var qe QueryErrors
+	if errors.As(err, &qe) {
+		return qe
+	}
+
 	return nil
 }
 